@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// runCallGraph はコールグラフを出力する。
+// -from と -callers を指定した場合は、到達可能な関数や呼び出し元の一覧のみを出力する。
+func runCallGraph(args []string) error {
+	flags := flag.NewFlagSet("callgraph", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	mode := flags.String("mode", "cha", "call graph mode (static, cha or rta)")
+	format := flags.String("format", "text", "output format (text or dot)")
+	from := flags.String("from", "", "print functions reachable from this function")
+	callers := flags.String("callers", "", "print callers of this function")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := gocode.ParseCallGraphMode(*mode)
+	if err != nil {
+		return err
+	}
+	r, err := loadRelations(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	cg := r.CallGraph(m)
+
+	switch {
+	case *from != "":
+		for _, id := range cg.ReachableFrom(gocode.FunctionID(*from)) {
+			fmt.Println(id)
+		}
+	case *callers != "":
+		for _, e := range cg.Callers(gocode.FunctionID(*callers)) {
+			fmt.Printf("%s\t%s\t%s\n", e.Caller(), e.Kind(), e.Position())
+		}
+	case *format == "dot":
+		return cg.WriteDOT(os.Stdout)
+	case *format == "text":
+		for _, id := range cg.Functions() {
+			for _, e := range cg.Callees(id) {
+				fmt.Printf("%s -> %s\t%s\n", e.Caller(), e.Callee(), e.Kind())
+			}
+		}
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// findingJSON は、 -format json で出力する検出結果。
+type findingJSON struct {
+	Check    string `json:"check"`
+	Symbol   string `json:"symbol"`
+	Message  string `json:"message"`
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// runCheck は、設定ファイルで有効な検査を実行して検出結果を出力する。検出があれば終了コード1で終了する。
+// -checks を指定した場合は、設定ファイルの enabled の代わりにその検査のみを実行する。
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	format := flags.String("format", "text", "output format: text, json or sarif")
+	checks := flags.String("checks", "", "comma separated checks to run instead of the configured ones")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := loadConfig(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	if !isFlagSet(flags, "format") && c.Output.Check != "" {
+		*format = c.Output.Check
+	}
+	settings := c.Checks
+	if *checks != "" {
+		settings.Enabled = nil
+		for _, name := range strings.Split(*checks, ",") {
+			n, err := gocode.ParseCheckName(strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			settings.Enabled = append(settings.Enabled, n)
+		}
+	}
+
+	r, err := loadRelationsWithConfig(c)
+	if err != nil {
+		return err
+	}
+	findings, err := r.RunChecks(&settings)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "text":
+		for _, f := range findings {
+			fmt.Println(f)
+		}
+	case "json":
+		err = writeFindingsJSON(os.Stdout, findings)
+	case "sarif":
+		err = gocode.WriteSARIF(os.Stdout, findings, &gocode.SARIFOptions{BaseDirectory: c.Root()})
+	default:
+		err = fmt.Errorf("unknown format: %s", *format)
+	}
+	if err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+func writeFindingsJSON(w io.Writer, findings []*gocode.Finding) error {
+	res := make([]findingJSON, 0, len(findings))
+	for _, f := range findings {
+		res = append(res, findingJSON{
+			Check:    f.Check().String(),
+			Symbol:   f.Symbol(),
+			Message:  f.Message(),
+			Filename: f.Position().Filename,
+			Line:     f.Position().Line,
+			Column:   f.Position().Column,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// runDeadCode は使われていない要素を出力する。 -fail を指定した場合、検出があれば終了コード1で終了する。
+func runDeadCode(args []string) error {
+	flags := flag.NewFlagSet("deadcode", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	exported := flags.Bool("exported", false, "also report exported symbols not referenced outside their package")
+	allow := flags.String("allow", "", "comma separated regular expressions of symbols to ignore")
+	fail := flags.Bool("fail", false, "exit with status 1 if anything is reported")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := loadConfig(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	options := c.Checks.DeadCode
+	if isFlagSet(flags, "exported") {
+		options.Exported = *exported
+	}
+	if *allow != "" {
+		options.Allowlist = strings.Split(*allow, ",")
+	}
+	r, err := loadRelationsWithConfig(c)
+	if err != nil {
+		return err
+	}
+	findings, err := r.DeadCode(&options)
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+	if *fail && len(findings) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/keisuke-m123/goanalyzer/docgen"
+)
+
+// runDocs は、パッケージごとのドキュメントをMarkdownまたはHTMLとして -out のディレクトリに書き出す。
+func runDocs(args []string) error {
+	flags := flag.NewFlagSet("docs", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	out := flags.String("out", "docs", "output directory")
+	format := flags.String("format", docgen.FormatMarkdown.String(), "output format: markdown or html")
+	title := flags.String("title", "", "title of the index page")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := loadConfig(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	if !isFlagSet(flags, "format") && c.Output.Docs != "" {
+		*format = c.Output.Docs
+	}
+	docFormat, err := docgen.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	r, err := loadRelationsWithConfig(c)
+	if err != nil {
+		return err
+	}
+
+	written, err := docgen.Generate(r, &docgen.Options{
+		Format:    docFormat,
+		OutputDir: *out,
+		Title:     *title,
+	})
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	return nil
+}
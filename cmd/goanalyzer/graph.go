@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// runGraph はパッケージまたは型の依存グラフを出力する。
+// -cycles を指定した場合は、型の循環依存の一覧のみを出力する。
+func runGraph(args []string) error {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	level := flags.String("level", "package", "graph granularity (package or type)")
+	format := flags.String("format", "text", "output format (text, dot or mermaid)")
+	cycles := flags.Bool("cycles", false, "print cycles between types")
+	fail := flags.Bool("fail", false, "exit with status 1 if cycles between types are found")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := loadConfig(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	if !isFlagSet(flags, "format") && c.Output.Graph != "" {
+		*format = c.Output.Graph
+	}
+	r, err := loadRelationsWithConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if *cycles || *fail {
+		found := r.TypeGraph().Cycles()
+		for _, c := range found {
+			ids := make([]string, 0, len(c))
+			for _, id := range c {
+				ids = append(ids, id.String())
+			}
+			fmt.Println(strings.Join(ids, " "))
+		}
+		if *fail && len(found) > 0 {
+			return &exitError{code: 1}
+		}
+		return nil
+	}
+
+	switch *level {
+	case "package":
+		return writePackageGraph(r.PackageGraph(), *format)
+	case "type":
+		return writeTypeGraph(r.TypeGraph(), *format)
+	default:
+		return fmt.Errorf("unknown level: %s", *level)
+	}
+}
+
+func writePackageGraph(g *gocode.PackageGraph, format string) error {
+	switch format {
+	case "dot":
+		return g.WriteDOT(os.Stdout)
+	case "mermaid":
+		return g.WriteMermaid(os.Stdout)
+	case "text":
+		for _, path := range g.SortedPackagePaths() {
+			for _, ps := range g.SortedImportPackagePaths(path) {
+				fmt.Printf("%s -> %s\n", path, ps.Path())
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeTypeGraph(g *gocode.TypeGraph, format string) error {
+	switch format {
+	case "dot":
+		return g.WriteDOT(os.Stdout)
+	case "mermaid":
+		return g.WriteMermaid(os.Stdout)
+	case "text":
+		for _, e := range g.Edges() {
+			fmt.Printf("%s -> %s\t%s\n", e.From(), e.To(), e.Kind())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/keisuke-m123/goanalyzer/lsp"
+	"github.com/spf13/afero"
+)
+
+// runLSP は標準入出力でLanguage Server Protocolのサーバを起動する。
+// 設定ファイルは -dir 、指定しなければカレントディレクトリから探す。
+// -dir と設定ファイルの load.directories のいずれも指定しなければ、クライアントから受け取ったワークスペースのルートを解析する。
+func runLSP(args []string) error {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	dir := flags.String("dir", "", "directory to analyze (defaults to the workspace root)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	configDir := *dir
+	if configDir == "" {
+		configDir = "."
+	}
+	c, err := loadConfig(configDir, *dir != "")
+	if err != nil {
+		return err
+	}
+	options := c.LoadOptions(afero.NewOsFs())
+	if *dir == "" && len(c.Load.Directories) == 0 {
+		options.Directories = nil
+	}
+	return lsp.NewServer(options).Serve(os.Stdin, os.Stdout)
+}
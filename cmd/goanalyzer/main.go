@@ -0,0 +1,134 @@
+// goanalyzer は gocode パッケージの解析結果を扱うコマンドラインツール。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+type (
+	// command はサブコマンドを表す。
+	command struct {
+		usage string
+		run   func(args []string) error
+	}
+
+	// exitError は、エラーメッセージを出力せずに指定の終了コードで終了するためのエラー。
+	exitError struct {
+		code int
+	}
+)
+
+var commands = map[string]*command{
+	"callgraph": {usage: "print the call graph or query callers and reachable functions", run: runCallGraph},
+	"check":     {usage: "run the checks enabled in .goanalyzer.yaml and report findings as text, JSON or SARIF", run: runCheck},
+	"deadcode":  {usage: "report unused structs, fields, methods, defined types and aliases", run: runDeadCode},
+	"docs":      {usage: "generate Markdown or HTML documentation pages with Mermaid diagrams for each package", run: runDocs},
+	"graph":     {usage: "print the package or type dependency graph as text, DOT or Mermaid, or type cycles", run: runGraph},
+	"lsp":       {usage: "run a language server over stdio answering implementation and type hierarchy requests", run: runLSP},
+	"metrics":   {usage: "print package coupling, instability, abstractness and distance metrics", run: runMetrics},
+	"mockgen":   {usage: "generate a gomock compatible mock or a function-field fake for interfaces", run: runMockGen},
+	"nearmiss":  {usage: "report structs that almost implement an interface with a per-method diff", run: runNearMiss},
+	"query":     {usage: "run a query such as 'structs where implements \"io.Reader\"'", run: runQuery},
+	"refs":      {usage: "list references to a type, field or method", run: runRefs},
+	"serve":     {usage: "start an HTTP server with a JSON API and an interactive architecture browser", run: runServe},
+	"semver":    {usage: "suggest the minimum semver bump between two versions of a module", run: runSemver},
+	"untyped":   {usage: "report fields, parameters and return values typed interface{} or any", run: runUntyped},
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		var ee *exitError
+		if errors.As(err, &ee) {
+			os.Exit(ee.code)
+		}
+		fmt.Fprintln(os.Stderr, "goanalyzer:", err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		printUsage()
+		return &exitError{code: 2}
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+func printUsage() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: goanalyzer <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].usage)
+	}
+}
+
+// loadConfig は、 dir からモジュールのルートまで辿って見つけた設定ファイルを読み込む。
+// explicit が真であれば、 -dir で指定された dir を設定ファイルの Load.Directories の代わりに解析する。
+func loadConfig(dir string, explicit bool) (*gocode.Config, error) {
+	c, err := gocode.LoadConfig(afero.NewOsFs(), dir)
+	if err != nil {
+		return nil, err
+	}
+	if explicit {
+		c.Load.Directories = nil
+	}
+	return c, nil
+}
+
+// loadRelations は、 dir に適用される設定ファイルに従ってディレクトリ以下のパッケージを解析する。
+// explicit は loadConfig と同じく、 dir を Load.Directories より優先するかを表す。
+func loadRelations(dir string, explicit bool) (*gocode.Relations, error) {
+	c, err := loadConfig(dir, explicit)
+	if err != nil {
+		return nil, err
+	}
+	return loadRelationsWithConfig(c)
+}
+
+// loadRelationsWithConfig は、設定の LoadOptions でパッケージを解析する。
+// FailFast を指定しない限り解析できなかったパッケージがあっても中断せず、エラーは警告として標準エラー出力に出力する。
+func loadRelationsWithConfig(c *gocode.Config) (*gocode.Relations, error) {
+	r, err := gocode.LoadRelations(c.LoadOptions(afero.NewOsFs()))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range r.Errors() {
+		fmt.Fprintf(os.Stderr, "goanalyzer: warning: %s error: %v\n", e.Kind(), e)
+	}
+	return r, nil
+}
+
+// isFlagSet は、 name のフラグがコマンドラインで指定されていれば真を返す。
+// 指定されていないフラグには設定ファイルの値を用いる。
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// runMetrics はパッケージメトリクスを出力する。閾値を超えたパッケージがあれば終了コード1で終了する。
+// -types を指定した場合は、structとdefined typeのメトリクスを -sort の項目の降順で出力する。
+func runMetrics(args []string) error {
+	flags := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	format := flags.String("format", "table", "output format: table or csv")
+	maxDistance := flags.Float64("max-distance", 0, "maximum distance from the main sequence (0 disables the check)")
+	maxInstability := flags.Float64("max-instability", 0, "maximum instability (0 disables the check)")
+	maxEfferent := flags.Int("max-ce", 0, "maximum efferent coupling (0 disables the check)")
+	maxAfferent := flags.Int("max-ca", 0, "maximum afferent coupling (0 disables the check)")
+	typeMetrics := flags.Bool("types", false, "print metrics of structs and defined types instead of packages")
+	sortBy := flags.String("sort", gocode.TypeMetricMethods.String(), "type metric to rank by with -types")
+	top := flags.Int("top", 0, "number of types to print with -types (0 prints all)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := loadConfig(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	if !isFlagSet(flags, "format") && c.Output.Metrics != "" {
+		*format = c.Output.Metrics
+	}
+	thresholds := c.Checks.Metrics
+	if isFlagSet(flags, "max-distance") {
+		thresholds.MaxDistance = *maxDistance
+	}
+	if isFlagSet(flags, "max-instability") {
+		thresholds.MaxInstability = *maxInstability
+	}
+	if isFlagSet(flags, "max-ce") {
+		thresholds.MaxEfferent = *maxEfferent
+	}
+	if isFlagSet(flags, "max-ca") {
+		thresholds.MaxAfferent = *maxAfferent
+	}
+	r, err := loadRelationsWithConfig(c)
+	if err != nil {
+		return err
+	}
+	if *typeMetrics {
+		return writeTypeMetrics(r, *format, *sortBy, *top)
+	}
+	metrics := r.PackageMetrics()
+	switch *format {
+	case "table":
+		err = gocode.WritePackageMetricsTable(os.Stdout, metrics)
+	case "csv":
+		err = gocode.WritePackageMetricsCSV(os.Stdout, metrics)
+	default:
+		err = fmt.Errorf("unknown format: %s", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	violations := gocode.CheckPackageMetrics(metrics, &thresholds)
+	for _, v := range violations {
+		fmt.Fprintln(os.Stderr, v)
+	}
+	if len(violations) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+func writeTypeMetrics(r *gocode.Relations, format, sortBy string, top int) error {
+	metric, err := gocode.ParseTypeMetric(sortBy)
+	if err != nil {
+		return err
+	}
+	metrics := gocode.RankTypeMetrics(r.TypeMetrics(), metric, top)
+	switch format {
+	case "table":
+		return gocode.WriteTypeMetricsTable(os.Stdout, metrics)
+	case "csv":
+		return gocode.WriteTypeMetricsCSV(os.Stdout, metrics)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
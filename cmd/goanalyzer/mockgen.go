@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// runMockGen は指定したinterfaceのモックを生成し、標準出力または -out のファイルに書き出す。
+func runMockGen(args []string) error {
+	flags := flag.NewFlagSet("mockgen", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	style := flags.String("style", gocode.MockStyleGomock.String(), "mock style: gomock or fake")
+	pkgName := flags.String("package", "", "package name of the generated file (default mock_<package>)")
+	pkgPath := flags.String("self_package", "", "import path of the generated file's package")
+	gomockPkg := flags.String("gomock", "", "import path of the gomock package")
+	out := flags.String("out", "", "output file (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: goanalyzer mockgen [flags] <package>.<interface>...")
+	}
+
+	mockStyle, err := gocode.ParseMockStyle(*style)
+	if err != nil {
+		return err
+	}
+	r, err := loadRelations(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+
+	var interfaces []*gocode.Interface
+	for _, name := range flags.Args() {
+		i, err := lookupInterface(r, name)
+		if err != nil {
+			return err
+		}
+		interfaces = append(interfaces, i)
+	}
+
+	src, err := r.GenerateMocks(interfaces, &gocode.MockOptions{
+		Style:         mockStyle,
+		PackageName:   *pkgName,
+		PackagePath:   *pkgPath,
+		GomockPackage: *gomockPkg,
+	})
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
+
+// lookupInterface は "パッケージ名.interface名" または "パッケージパス.interface名" 形式の名前からinterfaceを探す。
+func lookupInterface(r *gocode.Relations, name string) (*gocode.Interface, error) {
+	for _, i := range r.Interfaces().InterfaceAll() {
+		path := fmt.Sprintf("%s.%s", i.PackageSummary().Path(), i.Name())
+		if i.PackageInterfaceName().String() == name || path == name {
+			return i, nil
+		}
+	}
+	if !strings.Contains(name, ".") {
+		return nil, fmt.Errorf("interface name must be qualified with its package: %s", name)
+	}
+	return nil, fmt.Errorf("interface not found: %s", name)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runNearMiss はinterfaceをほぼ実装しているstructを、メソッドごとの差分と共に出力する。
+// -fail を指定した場合、検出があれば終了コード1で終了する。
+func runNearMiss(args []string) error {
+	flags := flag.NewFlagSet("nearmiss", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	maxDiff := flags.Int("max", 1, "maximum number of missing or mismatched methods to report")
+	fail := flags.Bool("fail", false, "exit with status 1 if anything is reported")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := loadConfig(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	options := c.Checks.NearMiss
+	if isFlagSet(flags, "max") || options.MaxDifferences == 0 {
+		options.MaxDifferences = *maxDiff
+	}
+	r, err := loadRelationsWithConfig(c)
+	if err != nil {
+		return err
+	}
+	nearMisses := r.NearMisses(&options)
+	for _, nm := range nearMisses {
+		fmt.Println(nm)
+	}
+	if *fail && len(nearMisses) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
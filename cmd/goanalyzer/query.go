@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// runQuery はクエリを実行し、該当した要素をパッケージパス付きの名前で1行ずつ出力する。
+func runQuery(args []string) error {
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: goanalyzer query [-dir dir] <query>")
+	}
+
+	r, err := loadRelations(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	res, err := r.Query(strings.Join(flags.Args(), " "))
+	if err != nil {
+		return err
+	}
+	for _, s := range res.Strings() {
+		fmt.Println(s)
+	}
+	return nil
+}
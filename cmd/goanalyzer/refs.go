@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// runRefs は指定した要素への参照を、位置と参照の種類と共に出力する。
+func runRefs(args []string) error {
+	flags := flag.NewFlagSet("refs", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: goanalyzer refs [-dir dir] <package path>.<type>[.<field or method>]")
+	}
+
+	r, err := loadRelations(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	for _, ref := range r.References().Lookup(gocode.ReferenceTarget(flags.Arg(0))) {
+		fmt.Printf("%s: %s\n", ref.Position(), ref.Kind())
+	}
+	return nil
+}
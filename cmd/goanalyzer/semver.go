@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// runSemver は2つのディレクトリの公開APIを比較し、必要なバージョンの上げ幅を出力する。
+// -max を超える上げ幅が必要な場合は終了コード1で終了するため、CIのゲートとして使える。
+// -format sarif を指定した場合は、変更を new のソースコードの位置と共にSARIFとして出力する。
+func runSemver(args []string) error {
+	flags := flag.NewFlagSet("semver", flag.ContinueOnError)
+	oldDir := flags.String("old", "", "directory of the previous version")
+	newDir := flags.String("new", ".", "directory of the new version")
+	maxBump := flags.String("max", "", "fail if the required bump exceeds this level (patch, minor or major)")
+	verbose := flags.Bool("v", false, "print all changes instead of only the justifying ones")
+	format := flags.String("format", "text", "output format: text or sarif")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *oldDir == "" {
+		return fmt.Errorf("-old is required")
+	}
+
+	oldRelations, err := loadRelations(*oldDir, false)
+	if err != nil {
+		return err
+	}
+	newRelations, err := loadRelations(*newDir, false)
+	if err != nil {
+		return err
+	}
+
+	diff := gocode.CompareAPI(oldRelations, newRelations)
+	bump := diff.RequiredBump()
+	changes := diff.JustifyingChanges()
+	if *verbose {
+		changes = diff.Changes()
+	}
+	switch *format {
+	case "text":
+		fmt.Println(bump)
+		for _, c := range changes {
+			fmt.Printf("  [%s] %s\n", c.Bump(), c.Message())
+		}
+	case "sarif":
+		if err := writeAPIChangesSARIF(*newDir, changes); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+
+	if *maxBump != "" {
+		limit, err := gocode.ParseSemverBump(*maxBump)
+		if err != nil {
+			return err
+		}
+		if bump > limit {
+			fmt.Fprintf(os.Stderr, "required bump %s exceeds allowed %s\n", bump, limit)
+			return &exitError{code: 1}
+		}
+	}
+	return nil
+}
+
+// writeAPIChangesSARIF は、 changes を newDir の設定のルートからの相対パスの位置と共にSARIFとして出力する。
+func writeAPIChangesSARIF(newDir string, changes []*gocode.APIChange) error {
+	c, err := loadConfig(newDir, false)
+	if err != nil {
+		return err
+	}
+	findings := make([]*gocode.Finding, 0, len(changes))
+	for _, change := range changes {
+		findings = append(findings, change.Finding())
+	}
+	return gocode.WriteSARIF(os.Stdout, findings, &gocode.SARIFOptions{BaseDirectory: c.Root()})
+}
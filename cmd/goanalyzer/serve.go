@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/keisuke-m123/goanalyzer/server"
+	"github.com/spf13/afero"
+)
+
+// runServe はJSONのAPIとWeb UIを提供するHTTPサーバを起動する。
+// -watch を指定した場合は、その間隔でファイルの変更を調べて解析結果を更新する。
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	watch := flags.Duration("watch", 0, "poll interval for reloading changed packages (0 disables watching)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var source server.Source
+	if *watch > 0 {
+		c, err := loadConfig(*dir, isFlagSet(flags, "dir"))
+		if err != nil {
+			return err
+		}
+		w, err := gocode.WatchRelations(c.LoadOptions(afero.NewOsFs()), *watch)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		go logEvents(w.Events())
+		source = w
+	} else {
+		r, err := loadRelations(*dir, isFlagSet(flags, "dir"))
+		if err != nil {
+			return err
+		}
+		source = server.StaticSource(r)
+	}
+
+	fmt.Fprintf(os.Stderr, "goanalyzer: serving on http://%s/\n", *addr)
+	return (&http.Server{
+		Addr:              *addr,
+		Handler:           server.NewServer(source),
+		ReadHeaderTimeout: 10 * time.Second,
+	}).ListenAndServe()
+}
+
+func logEvents(events <-chan *gocode.RelationsChangeEvent) {
+	for e := range events {
+		if e.Err() != nil {
+			fmt.Fprintln(os.Stderr, "goanalyzer: reload failed:", e.Err())
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "goanalyzer: reloaded %v, removed %v\n", e.Reloaded(), e.Removed())
+	}
+}
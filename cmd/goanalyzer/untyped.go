@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runUntyped は interface{} または any を型に含むフィールド、パラメータ、戻り値を出力する。
+// -fail を指定した場合、検出があれば終了コード1で終了する。
+func runUntyped(args []string) error {
+	flags := flag.NewFlagSet("untyped", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "directory to analyze")
+	fail := flags.Bool("fail", false, "exit with status 1 if anything is reported")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	r, err := loadRelations(*dir, isFlagSet(flags, "dir"))
+	if err != nil {
+		return err
+	}
+	usages := r.EmptyInterfaceUsages()
+	for _, u := range usages {
+		fmt.Println(u)
+	}
+	if *fail && len(usages) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
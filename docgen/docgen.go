@@ -0,0 +1,180 @@
+// Package docgen は、 gocode の解析結果からパッケージごとのドキュメントをMarkdownまたは静的なHTMLとして生成する。
+package docgen
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+type (
+	// Format は、生成するドキュメントの形式を表す。
+	Format string
+
+	// Options は、ドキュメントの生成のオプション。
+	Options struct {
+		// Format は生成するドキュメントの形式。空の場合は FormatMarkdown となる。
+		Format Format
+		// OutputDir はドキュメントを書き出すディレクトリ。空の場合はカレントディレクトリとなる。
+		OutputDir string
+		// FileSystem はドキュメントを書き出すファイルシステム。nilの場合はOSのファイルシステムとなる。
+		FileSystem afero.Fs
+		// Title は索引ページの見出し。空の場合は "API documentation" となる。
+		Title string
+	}
+
+	// template は、 text/template と html/template のテンプレートを同じように扱う。
+	template interface {
+		ExecuteTemplate(w io.Writer, name string, data interface{}) error
+	}
+)
+
+const (
+	// FormatMarkdown は、Mermaidの図をコードブロックとして埋め込んだMarkdownを表す。
+	FormatMarkdown Format = "markdown"
+	// FormatHTML は、Mermaidの図をブラウザで描画する静的なHTMLを表す。
+	FormatHTML Format = "html"
+)
+
+const (
+	defaultTitle = "API documentation"
+	indexName    = "index"
+)
+
+//go:embed templates
+var templates embed.FS
+
+func (f Format) String() string {
+	return string(f)
+}
+
+// ParseFormat は、"markdown" または "html" の文字列を Format に変換する。
+func ParseFormat(s string) (Format, error) {
+	for _, f := range []Format{FormatMarkdown, FormatHTML} {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown documentation format: %q", s)
+}
+
+func (f Format) extension() string {
+	if f == FormatHTML {
+		return ".html"
+	}
+	return ".md"
+}
+
+func parseTemplates(format Format) (template, error) {
+	pattern := "templates/" + format.String() + "/*.tmpl"
+	if format == FormatHTML {
+		t, err := htmltemplate.New("").Funcs(htmltemplate.FuncMap{
+			"list": list,
+		}).ParseFS(templates, pattern)
+		return t, err
+	}
+	t, err := texttemplate.New("").Funcs(texttemplate.FuncMap{
+		"cell": markdownCell,
+		"list": list,
+	}).ParseFS(templates, pattern)
+	return t, err
+}
+
+// list は、テンプレートから複数の値を1つの引数として渡すために使う。
+func list(values ...interface{}) []interface{} {
+	return values
+}
+
+// markdownCell は、Markdownの表のセルに書けるように "|" をエスケープする。
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// Generate は、 r が解析したパッケージごとに1ページと、全パッケージの索引ページを書き出し、書き出したファイルのパスを返す。
+//
+// 各ページには、パッケージの依存関係とクラス図をMermaidの図として埋め込み、
+// structのフィールドとタグ、interfaceのメソッドと実装している型、defined typeのメソッド、type aliasを記載する。
+func Generate(r *gocode.Relations, options *Options) ([]string, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	opts := *options
+	if opts.Format == "" {
+		opts.Format = FormatMarkdown
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "."
+	}
+	if opts.FileSystem == nil {
+		opts.FileSystem = afero.NewOsFs()
+	}
+	if opts.Title == "" {
+		opts.Title = defaultTitle
+	}
+
+	tmpl, err := parseTemplates(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.FileSystem.MkdirAll(opts.OutputDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	b := newPageBuilder(r, opts.Format)
+	var written []string
+	write := func(name, templateName string, data interface{}) error {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, templateName, data); err != nil {
+			return err
+		}
+		path := filepath.Join(opts.OutputDir, name+opts.Format.extension())
+		if err := afero.WriteFile(opts.FileSystem, path, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+		written = append(written, path)
+		return nil
+	}
+
+	if err := write(indexName, "index.tmpl", b.indexPage(opts.Title)); err != nil {
+		return nil, err
+	}
+	for _, path := range b.graph.SortedPackagePaths() {
+		pkg, ok := r.Packages().Get(path)
+		if !ok {
+			continue
+		}
+		if err := write(pageName(path), "package.tmpl", b.packagePage(pkg, opts.Title)); err != nil {
+			return nil, err
+		}
+	}
+	return written, nil
+}
+
+// pageNameReplacer は、パッケージパスをページのファイル名に変換する。
+// 異なるパッケージパスが同じ名前にならないように、 "/" に置き換える "_" と、エスケープに使う "%" をパーセントエンコードする。
+var pageNameReplacer = strings.NewReplacer("%", "%25", "_", "%5F", "/", "_")
+
+// pageName は、パッケージのページの拡張子を除いたファイル名を返す。
+// 全てのページを同じディレクトリに置くため、パッケージパスの "/" を "_" に置き換える。
+// 索引ページの名前は予約し、パッケージパスが index であれば末尾に "_" を付ける。
+func pageName(path gocode.PackagePath) string {
+	name := pageNameReplacer.Replace(path.String())
+	if name == indexName {
+		return name + "_"
+	}
+	return name
+}
+
+// pageHref は、パッケージのページへのリンクの拡張子を除いたURLを返す。
+func pageHref(path gocode.PackagePath) string {
+	return url.PathEscape(pageName(path))
+}
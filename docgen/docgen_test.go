@@ -0,0 +1,241 @@
+package docgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/docgen"
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+func loadTestRelations(t *testing.T) *gocode.Relations {
+	t.Helper()
+	files := map[string]string{
+		"go.mod": "module example.com/testmodule\n\ngo 1.17\n",
+		"store/store.go": `package store
+
+type Reader interface {
+	Read(key string) ([]byte, error)
+}
+
+type Memory struct {
+	data map[string][]byte
+	Name string ` + "`json:\"name\"`" + `
+}
+
+func (m *Memory) Read(key string) ([]byte, error) { return m.data[key], nil }
+
+type Level int
+
+type Key = string
+`,
+		"app/app.go": `package app
+
+import "example.com/testmodule/store"
+
+type App struct {
+	Reader store.Reader
+}
+`,
+	}
+	return loadRelations(t, files)
+}
+
+func loadRelations(t *testing.T, files map[string]string) *gocode.Relations {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:  afero.NewOsFs(),
+		Directories: []string{dir},
+		Recursive:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestGenerate(t *testing.T) {
+	r := loadTestRelations(t)
+
+	tests := []struct {
+		format docgen.Format
+		page   string
+		want   []string
+	}{
+		{
+			format: docgen.FormatMarkdown,
+			page:   "docs/example.com_testmodule_store.md",
+			want: []string{
+				"# Package store",
+				"```mermaid\ngraph LR\n",
+				"\tp1 --> p0\n",
+				"```mermaid\nclassDiagram\n",
+				"<<interface>>",
+				"t2 <|.. t1\n",
+				"| Name | `string` | `json:\"name\"` |",
+				"- [example.com/testmodule/app](example.com_testmodule_app.md)",
+				"- [store.Memory](example.com_testmodule_store.md#memory)",
+				"Underlying type: `int`",
+				"### Key",
+				"Alias of `string`",
+			},
+		},
+		{
+			format: docgen.FormatHTML,
+			page:   "docs/example.com_testmodule_store.html",
+			want: []string{
+				"<h1>Package store</h1>",
+				`<pre class="mermaid">classDiagram`,
+				"&lt;|..",
+				`<td><code>json:&#34;name&#34;</code></td>`,
+				`<a href="example.com_testmodule_store.html#memory">store.Memory</a>`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			written, err := docgen.Generate(r, &docgen.Options{Format: tt.format, OutputDir: "docs", FileSystem: fs})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(written) != 3 {
+				t.Errorf("written = %v, want the index and 2 package pages", written)
+			}
+			page, err := afero.ReadFile(fs, tt.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(page), want) {
+					t.Errorf("%s does not contain %q:\n%s", tt.page, want, page)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_PageNames(t *testing.T) {
+	r := loadRelations(t, map[string]string{
+		"go.mod":      "module index\n\ngo 1.17\n",
+		"index.go":    "package index\n",
+		"a/b_c/b.go":  "package b_c\n",
+		"a_b/c/c.go":  "package c\n",
+		"a_b/c_/c.go": "package c_\n",
+		"a_b_/c/c.go": "package c\n",
+	})
+
+	fs := afero.NewMemMapFs()
+	written, err := docgen.Generate(r, &docgen.Options{OutputDir: "docs", FileSystem: fs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// パッケージパスの "_" と "/" を区別し、索引ページの名前はパッケージのページに使わない。
+	want := []string{
+		"docs/index.md",
+		"docs/index_.md",
+		"docs/index_a%5Fb%5F_c.md",
+		"docs/index_a%5Fb_c%5F.md",
+		"docs/index_a%5Fb_c.md",
+		"docs/index_a_b%5Fc.md",
+	}
+	var got []string
+	for _, path := range written {
+		got = append(got, filepath.ToSlash(path))
+	}
+	sort.Strings(got)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("written = %v, want %v", got, want)
+	}
+
+	index, err := afero.ReadFile(fs, "docs/index.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range []string{"(index_.md)", "(index_a_b%255Fc.md)"} {
+		if !strings.Contains(string(index), link) {
+			t.Errorf("index.md does not contain %q:\n%s", link, index)
+		}
+	}
+}
+
+func TestGenerate_Anchors(t *testing.T) {
+	r := loadRelations(t, map[string]string{
+		"go.mod": "module example.com/testmodule\n\ngo 1.17\n",
+		"store/store.go": `package store
+
+type Reader interface {
+	Read() string
+}
+
+type Foo struct{}
+
+func (Foo) Read() string { return "" }
+
+type foo struct{}
+
+func (foo) Read() string { return "" }
+
+type structs struct{}
+`,
+	})
+
+	tests := []struct {
+		format docgen.Format
+		page   string
+		want   []string
+	}{
+		{
+			// 小文字にすると同じになる型と "## Structs" の見出しには、Markdownと同じく連番を付ける。
+			format: docgen.FormatMarkdown,
+			page:   "docs/example.com_testmodule_store.md",
+			want: []string{
+				"- [store.Foo](example.com_testmodule_store.md#foo)\n",
+				"- [store.foo](example.com_testmodule_store.md#foo-1)\n",
+			},
+		},
+		{
+			format: docgen.FormatHTML,
+			page:   "docs/example.com_testmodule_store.html",
+			want: []string{
+				`<section id="foo">`,
+				`<section id="foo-1">`,
+				`<section id="structs-1">`,
+				`<section id="reader">`,
+				`<a href="example.com_testmodule_store.html#foo">store.Foo</a>`,
+				`<a href="example.com_testmodule_store.html#foo-1">store.foo</a>`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if _, err := docgen.Generate(r, &docgen.Options{Format: tt.format, OutputDir: "docs", FileSystem: fs}); err != nil {
+				t.Fatal(err)
+			}
+			page, err := afero.ReadFile(fs, tt.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(page), want) {
+					t.Errorf("%s does not contain %q:\n%s", tt.page, want, page)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package docgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+type (
+	// mermaidIDs は、Mermaidの図で使うノードのIDを出現順に割り当てる。
+	mermaidIDs struct {
+		prefix string
+		ids    map[string]string
+	}
+)
+
+func newMermaidIDs(prefix string) *mermaidIDs {
+	return &mermaidIDs{prefix: prefix, ids: make(map[string]string)}
+}
+
+// get は key のIDを返す。初めて出現した場合は true を返す。
+func (m *mermaidIDs) get(key string) (id string, added bool) {
+	if id, ok := m.ids[key]; ok {
+		return id, false
+	}
+	id = fmt.Sprintf("%s%d", m.prefix, len(m.ids))
+	m.ids[key] = id
+	return id, true
+}
+
+// dependencyDiagram は、 path のパッケージと、それがimportしているパッケージ、それをimportしているパッケージのflowchartを返す。
+func (b *pageBuilder) dependencyDiagram(path gocode.PackagePath) string {
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	ids := newMermaidIDs("p")
+	node := func(p gocode.PackagePath) string {
+		id, added := ids.get(p.String())
+		if added {
+			fmt.Fprintf(&sb, "\t%s[%s]\n", id, mermaidLabel(p.String()))
+		}
+		return id
+	}
+
+	self := node(path)
+	fmt.Fprintf(&sb, "\tstyle %s stroke-width:3px\n", self)
+	for _, importer := range b.importers(path) {
+		fmt.Fprintf(&sb, "\t%s --> %s\n", node(importer), self)
+	}
+	for _, ps := range b.graph.SortedImportPackagePaths(path) {
+		fmt.Fprintf(&sb, "\t%s --> %s\n", self, node(ps.Path()))
+	}
+	return sb.String()
+}
+
+// classDiagram は、 pkg のstruct、interface、defined typeと、
+// それらとフィールド、埋め込み、実装の関係にある型のクラス図を返す。
+// メンバーを書くのは pkg の型のみで、他のパッケージの型は名前のみを書く。
+func (b *pageBuilder) classDiagram(pkg *gocode.Package) string {
+	path := pkg.Summary().Path()
+	var own []*gocode.TypeNode
+	for _, n := range b.typeGraph.Nodes() {
+		if n.PackageSummary().Path() == path && n.Kind() != gocode.TypeNodeTypeAlias {
+			own = append(own, n)
+		}
+	}
+	if len(own) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("classDiagram\n")
+	ids := newMermaidIDs("t")
+	node := func(n *gocode.TypeNode) string {
+		id, added := ids.get(n.ID().String())
+		if !added {
+			return id
+		}
+		label := n.Name()
+		if n.PackageSummary().Path() != path {
+			label = n.PackageSummary().Name().String() + "." + n.Name()
+		}
+		fmt.Fprintf(&sb, "\tclass %s[%s]\n", id, mermaidLabel(label))
+		if n.Kind() == gocode.TypeNodeInterface {
+			fmt.Fprintf(&sb, "\t<<interface>> %s\n", id)
+		}
+		return id
+	}
+
+	ownMembers := members(pkg)
+	var relations []string
+	for _, n := range own {
+		id := node(n)
+		for _, member := range ownMembers[n.ID()] {
+			fmt.Fprintf(&sb, "\t%s : %s\n", id, member)
+		}
+		for _, e := range b.typeGraph.OutEdges(n.ID()) {
+			to, ok := b.typeGraph.Node(e.To())
+			if !ok || to.Kind() == gocode.TypeNodeTypeAlias {
+				continue
+			}
+			switch e.Kind() {
+			case gocode.TypeEdgeField:
+				relations = append(relations, fmt.Sprintf("%s --> %s", id, node(to)))
+			case gocode.TypeEdgeEmbed:
+				if n.Kind() == gocode.TypeNodeInterface {
+					relations = append(relations, fmt.Sprintf("%s <|-- %s", node(to), id))
+				} else {
+					relations = append(relations, fmt.Sprintf("%s *-- %s", id, node(to)))
+				}
+			case gocode.TypeEdgeImplements:
+				relations = append(relations, fmt.Sprintf("%s <|.. %s", node(to), id))
+			}
+		}
+		// 他のパッケージの型が実装している場合も、interfaceのページで実装している型がわかるように書く。
+		for _, e := range b.typeGraph.InEdges(n.ID()) {
+			from, ok := b.typeGraph.Node(e.From())
+			if !ok || e.Kind() != gocode.TypeEdgeImplements || from.PackageSummary().Path() == path {
+				continue
+			}
+			relations = append(relations, fmt.Sprintf("%s <|.. %s", id, node(from)))
+		}
+	}
+	for _, r := range relations {
+		fmt.Fprintf(&sb, "\t%s\n", r)
+	}
+	return sb.String()
+}
+
+// members は、クラス図に書く pkg の型のフィールドとメソッドを返す。公開されているものは "+" 、非公開のものは "-" を先頭に付ける。
+func members(pkg *gocode.Package) map[gocode.TypeNodeID][]string {
+	path := pkg.Summary().Path()
+	res := make(map[gocode.TypeNodeID][]string)
+	for _, s := range pkg.Detail().Structs() {
+		var list []string
+		for _, f := range s.Fields() {
+			if !f.Embedded() {
+				list = append(list, visibility(f.Exported())+mermaidMember(f.Name().String()+" "+gocode.DisplayType(f.Type().GoType())))
+			}
+		}
+		res[typeNodeID(path, s.Name().String())] = append(list, methodMembers(s.Methods())...)
+	}
+	for _, i := range pkg.Detail().Interfaces() {
+		res[typeNodeID(path, i.Name().String())] = methodMembers(i.ExplicitMethods())
+	}
+	for _, dt := range pkg.Detail().DefinedTypes() {
+		res[typeNodeID(path, dt.Name().String())] = methodMembers(dt.Methods())
+	}
+	return res
+}
+
+func methodMembers(methods []*gocode.Function) []string {
+	sorted := append([]*gocode.Function{}, methods...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name().String() < sorted[j].Name().String()
+	})
+	list := make([]string, 0, len(sorted))
+	for _, m := range sorted {
+		list = append(list, visibility(m.Exported())+mermaidMember(m.Signature()))
+	}
+	return list
+}
+
+func visibility(exported bool) string {
+	if exported {
+		return "+"
+	}
+	return "-"
+}
+
+// mermaidLabel は、Mermaidのノードのラベルとして書けるように文字列を引用符で囲む。
+func mermaidLabel(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// mermaidMember は、クラス図のメンバーとして解釈される記号を置き換える。
+// "{" と "}" はクラスの定義の区切り、 "~" はジェネリクスの記法として解釈されるため全角の記号にする。
+func mermaidMember(s string) string {
+	return strings.NewReplacer("{", "｛", "}", "｝", "~", "～").Replace(s)
+}
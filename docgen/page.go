@@ -0,0 +1,311 @@
+package docgen
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// テンプレートに渡すページの内容を定義する。
+
+type (
+	indexPage struct {
+		Title    string
+		Packages []*packageEntry
+	}
+
+	packageEntry struct {
+		Link         *link
+		Structs      int
+		Interfaces   int
+		DefinedTypes int
+		TypeAliases  int
+	}
+
+	packagePage struct {
+		Title      string
+		Index      *link
+		Path       string
+		Name       string
+		Imports    []*link
+		ImportedBy []*link
+		// Dependencies はパッケージの依存関係を表すMermaidのflowchart。
+		Dependencies string
+		// ClassDiagram はパッケージの型と関連する型を表すMermaidのクラス図。型がなければ空となる。
+		ClassDiagram string
+		Structs      []*typeSection
+		Interfaces   []*typeSection
+		DefinedTypes []*typeSection
+		TypeAliases  []*typeSection
+	}
+
+	typeSection struct {
+		id       gocode.TypeNodeID
+		Name     string
+		Anchor   string
+		Position string
+		// Underlying はdefined typeの基底型、またはtype aliasが参照する型。
+		Underlying   string
+		Fields       []*fieldRow
+		Methods      []string
+		Implements   []*link
+		Implementors []*link
+	}
+
+	fieldRow struct {
+		Name     string
+		Type     string
+		Tag      string
+		Embedded bool
+	}
+
+	link struct {
+		Label string
+		Href  string
+	}
+
+	// pageBuilder は、パッケージごとのページの内容を組み立てる。
+	pageBuilder struct {
+		relations *gocode.Relations
+		format    Format
+		graph     *gocode.PackageGraph
+		typeGraph *gocode.TypeGraph
+		// anchors はパッケージごとの型名とアンカーの対応。 anchor で必要になったパッケージの分だけ作る。
+		anchors map[gocode.PackagePath]map[string]string
+	}
+)
+
+func newPageBuilder(r *gocode.Relations, format Format) *pageBuilder {
+	return &pageBuilder{
+		relations: r,
+		format:    format,
+		graph:     r.PackageGraph(),
+		typeGraph: r.TypeGraph(),
+		anchors:   make(map[gocode.PackagePath]map[string]string),
+	}
+}
+
+func (b *pageBuilder) indexPage(title string) *indexPage {
+	page := &indexPage{Title: title}
+	for _, path := range b.graph.SortedPackagePaths() {
+		pkg, ok := b.relations.Packages().Get(path)
+		if !ok {
+			continue
+		}
+		detail := pkg.Detail()
+		page.Packages = append(page.Packages, &packageEntry{
+			Link:         b.packageLink(path),
+			Structs:      len(detail.Structs()),
+			Interfaces:   len(detail.Interfaces()),
+			DefinedTypes: len(detail.DefinedTypes()),
+			TypeAliases:  len(detail.TypeAliases()),
+		})
+	}
+	return page
+}
+
+func (b *pageBuilder) packagePage(pkg *gocode.Package, title string) *packagePage {
+	path := pkg.Summary().Path()
+	detail := pkg.Detail()
+	page := &packagePage{
+		Title: title,
+		Index: &link{Label: title, Href: indexName + b.format.extension()},
+		Path:  path.String(),
+		Name:  pkg.Summary().Name().String(),
+	}
+	for _, ps := range b.graph.SortedImportPackagePaths(path) {
+		page.Imports = append(page.Imports, b.packageLink(ps.Path()))
+	}
+	for _, importer := range b.importers(path) {
+		page.ImportedBy = append(page.ImportedBy, b.packageLink(importer))
+	}
+	page.Dependencies = b.dependencyDiagram(path)
+	page.ClassDiagram = b.classDiagram(pkg)
+
+	for _, s := range detail.Structs() {
+		section := b.newTypeSection(s.PackageSummary(), s.Name().String(), s.DefinedPos())
+		for _, f := range s.Fields() {
+			section.Fields = append(section.Fields, &fieldRow{
+				Name:     f.Name().String(),
+				Type:     gocode.DisplayType(f.Type().GoType()),
+				Tag:      string(f.Tag()),
+				Embedded: f.Embedded(),
+			})
+		}
+		section.Methods = signatures(s.Methods())
+		section.Implements = b.typeLinks(b.typeGraph.OutEdges(section.id), gocode.TypeEdgeImplements, (*gocode.TypeEdge).To)
+		page.Structs = append(page.Structs, section)
+	}
+	for _, i := range detail.Interfaces() {
+		section := b.newTypeSection(i.PackageSummary(), i.Name().String(), i.DefinedPos())
+		section.Methods = signatures(i.Methods())
+		section.Implementors = b.typeLinks(b.typeGraph.InEdges(section.id), gocode.TypeEdgeImplements, (*gocode.TypeEdge).From)
+		page.Interfaces = append(page.Interfaces, section)
+	}
+	for _, dt := range detail.DefinedTypes() {
+		section := b.newTypeSection(dt.PackageSummary(), dt.Name().String(), dt.DefinedPos())
+		section.Underlying = gocode.DisplayType(dt.UnderlyingType().GoType())
+		section.Methods = signatures(dt.Methods())
+		section.Implements = b.typeLinks(b.typeGraph.OutEdges(section.id), gocode.TypeEdgeImplements, (*gocode.TypeEdge).To)
+		page.DefinedTypes = append(page.DefinedTypes, section)
+	}
+	for _, a := range detail.TypeAliases() {
+		section := b.newTypeSection(a.PackageSummary(), a.Name().String(), a.DefinedPos())
+		section.Underlying = gocode.DisplayType(a.Target().GoType())
+		page.TypeAliases = append(page.TypeAliases, section)
+	}
+	for _, sections := range [][]*typeSection{page.Structs, page.Interfaces, page.DefinedTypes, page.TypeAliases} {
+		sort.Slice(sections, func(i, j int) bool {
+			return sections[i].Name < sections[j].Name
+		})
+	}
+	return page
+}
+
+// importers は、解析したパッケージのうち path をimportしているパッケージを返す。
+func (b *pageBuilder) importers(path gocode.PackagePath) []gocode.PackagePath {
+	var importers []gocode.PackagePath
+	for _, from := range b.graph.SortedPackagePaths() {
+		for _, ps := range b.graph.SortedImportPackagePaths(from) {
+			if ps.Path() == path {
+				importers = append(importers, from)
+				break
+			}
+		}
+	}
+	return importers
+}
+
+func (b *pageBuilder) newTypeSection(ps *gocode.PackageSummary, name string, pos token.Pos) *typeSection {
+	section := &typeSection{
+		id:     typeNodeID(ps.Path(), name),
+		Name:   name,
+		Anchor: b.anchor(ps.Path(), name),
+	}
+	if pos.IsValid() {
+		position := b.relations.Position(pos)
+		section.Position = fmt.Sprintf("%s:%d", filepath.Base(position.Filename), position.Line)
+	}
+	return section
+}
+
+// typeLinks は、 edges のうち kind の辺の端の型へのリンクを返す。
+func (b *pageBuilder) typeLinks(edges []*gocode.TypeEdge, kind gocode.TypeEdgeKind, end func(*gocode.TypeEdge) gocode.TypeNodeID) []*link {
+	var links []*link
+	for _, e := range edges {
+		if e.Kind() != kind {
+			continue
+		}
+		if n, ok := b.typeGraph.Node(end(e)); ok {
+			links = append(links, b.typeLink(n))
+		}
+	}
+	return links
+}
+
+func (b *pageBuilder) typeLink(n *gocode.TypeNode) *link {
+	return &link{
+		Label: n.PackageSummary().Name().String() + "." + n.Name(),
+		Href:  pageHref(n.PackageSummary().Path()) + b.format.extension() + "#" + b.anchor(n.PackageSummary().Path(), n.Name()),
+	}
+}
+
+func (b *pageBuilder) packageLink(path gocode.PackagePath) *link {
+	return &link{Label: path.String(), Href: pageHref(path) + b.format.extension()}
+}
+
+func typeNodeID(path gocode.PackagePath, name string) gocode.TypeNodeID {
+	return gocode.TypeNodeID(path.String() + "." + name)
+}
+
+// anchor は、 path のページにある型 name の見出しへのリンクのアンカーを返す。
+func (b *pageBuilder) anchor(path gocode.PackagePath, name string) string {
+	anchors, ok := b.anchors[path]
+	if !ok {
+		anchors = make(map[string]string)
+		if pkg, ok := b.relations.Packages().Get(path); ok {
+			anchors = typeAnchors(pkg)
+		}
+		b.anchors[path] = anchors
+	}
+	if a, ok := anchors[name]; ok {
+		return a
+	}
+	return headingAnchor(name)
+}
+
+// typeAnchors は、パッケージのページにある型の見出しのアンカーを型名ごとに返す。
+//
+// Markdownの見出しから生成されるアンカーに合わせ、ページの見出しを上から順に小文字にして、
+// Foo と foo のように同じになった2つ目以降には "-1" からの連番を付ける。
+// HTMLのページでも同じアンカーを使うため、ページ内で一意になる。
+func typeAnchors(pkg *gocode.Package) map[string]string {
+	detail := pkg.Detail()
+	var structs, interfaces, definedTypes, typeAliases []string
+	for _, s := range detail.Structs() {
+		structs = append(structs, s.Name().String())
+	}
+	for _, i := range detail.Interfaces() {
+		interfaces = append(interfaces, i.Name().String())
+	}
+	for _, dt := range detail.DefinedTypes() {
+		definedTypes = append(definedTypes, dt.Name().String())
+	}
+	for _, a := range detail.TypeAliases() {
+		typeAliases = append(typeAliases, a.Name().String())
+	}
+
+	// 型の見出しより前にある見出しも数え、 "## Structs" と型 structs のような衝突も避ける。
+	counts := map[string]int{
+		headingAnchor("Package " + pkg.Summary().Name().String()): 1,
+		headingAnchor("Dependencies"):                             1,
+		headingAnchor("Class diagram"):                            1,
+	}
+	next := func(heading string) string {
+		a := headingAnchor(heading)
+		n := counts[a]
+		counts[a]++
+		if n == 0 {
+			return a
+		}
+		return fmt.Sprintf("%s-%d", a, n)
+	}
+	anchors := make(map[string]string)
+	for _, group := range []struct {
+		heading string
+		names   []string
+	}{
+		{"Structs", structs},
+		{"Interfaces", interfaces},
+		{"Defined types", definedTypes},
+		{"Type aliases", typeAliases},
+	} {
+		if len(group.names) == 0 {
+			continue
+		}
+		next(group.heading)
+		sort.Strings(group.names)
+		for _, name := range group.names {
+			anchors[name] = next(name)
+		}
+	}
+	return anchors
+}
+
+// headingAnchor は、Markdownの見出しから生成されるアンカーと同じく、見出しを小文字にして空白を "-" に置き換える。
+func headingAnchor(heading string) string {
+	return strings.ReplaceAll(strings.ToLower(heading), " ", "-")
+}
+
+func signatures(methods []*gocode.Function) []string {
+	res := make([]string, 0, len(methods))
+	for _, m := range methods {
+		res = append(res, m.Signature())
+	}
+	sort.Strings(res)
+	return res
+}
@@ -5,6 +5,7 @@ go 1.17
 require (
 	github.com/spf13/afero v1.8.0
 	golang.org/x/tools v0.1.8
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,353 @@
+package gocode
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+type (
+	// SemverBump は、APIの変更に必要なセマンティックバージョンの上げ幅を表す。
+	SemverBump int
+
+	// APIChangeKind は、APIの変更の種類を表す。
+	APIChangeKind string
+
+	// APIChange は、公開APIの1つの変更を表す。
+	APIChange struct {
+		// kind は変更の種類。
+		kind APIChangeKind
+		// symbol は変更された要素のパッケージパス付きの名前。
+		symbol string
+		// message は変更内容の説明。
+		message string
+		// bump は変更に必要なバージョンの上げ幅。
+		bump SemverBump
+		// position は new での要素の位置。削除された場合は要素を保持していた要素の位置となる。
+		position token.Position
+	}
+
+	// APIDiff は、2つの Relations の公開APIを比較した結果を表す。
+	APIDiff struct {
+		changes []*APIChange
+	}
+
+	// apiElement は、公開APIを構成する1つの要素を表す。
+	apiElement struct {
+		// label は要素の種類を表す表示用の文字列。
+		label string
+		// signature は要素の互換性判定に使う文字列。異なれば破壊的変更とみなす。
+		signature string
+		// additionBump は要素が追加された場合に必要なバージョンの上げ幅。
+		additionBump SemverBump
+		// parent は要素を保持する要素のシンボル名。パッケージの場合は空となる。
+		parent string
+		// position は要素が宣言された位置。
+		position token.Position
+	}
+
+	// apiSnapshot は、 Relations の公開APIをシンボル名をキーとして保持する。
+	apiSnapshot map[string]*apiElement
+)
+
+const (
+	SemverBumpPatch SemverBump = iota
+	SemverBumpMinor
+	SemverBumpMajor
+)
+
+const (
+	APIChangeAdded   APIChangeKind = "added"
+	APIChangeRemoved APIChangeKind = "removed"
+	APIChangeChanged APIChangeKind = "changed"
+)
+
+func (b SemverBump) String() string {
+	switch b {
+	case SemverBumpPatch:
+		return "patch"
+	case SemverBumpMinor:
+		return "minor"
+	case SemverBumpMajor:
+		return "major"
+	default:
+		return fmt.Sprintf("SemverBump(%d)", int(b))
+	}
+}
+
+// ParseSemverBump は、"patch", "minor", "major" のいずれかの文字列を SemverBump に変換する。
+func ParseSemverBump(s string) (SemverBump, error) {
+	for _, b := range []SemverBump{SemverBumpPatch, SemverBumpMinor, SemverBumpMajor} {
+		if b.String() == s {
+			return b, nil
+		}
+	}
+	return SemverBumpPatch, fmt.Errorf("unknown semver bump: %q", s)
+}
+
+func (k APIChangeKind) String() string {
+	return string(k)
+}
+
+func (c *APIChange) Kind() APIChangeKind {
+	return c.kind
+}
+
+func (c *APIChange) Symbol() string {
+	return c.symbol
+}
+
+func (c *APIChange) Message() string {
+	return c.message
+}
+
+func (c *APIChange) Bump() SemverBump {
+	return c.bump
+}
+
+// Position は、変更された要素の new での位置を返す。
+// 削除された要素の場合は、それを保持していた要素の位置となる。パッケージの削除のように特定できなければ Filename が空となる。
+func (c *APIChange) Position() token.Position {
+	return c.position
+}
+
+// Finding は、変更を "semver/major" のように必要な上げ幅をルールとする Finding に変換する。
+func (c *APIChange) Finding() *Finding {
+	return &Finding{
+		check:    CheckSemver,
+		rule:     ruleID(CheckSemver, c.bump.String()),
+		symbol:   c.symbol,
+		message:  c.message,
+		position: c.position,
+	}
+}
+
+// CompareAPI は、 old と new の公開APIを比較し、Goの互換性ルールに基づいて差分を返す。
+//
+// 両者は同じモジュールを別々のディレクトリからロードしたものであることを想定しており、
+// パッケージパスと型名によって要素を対応付ける。
+func CompareAPI(old, new *Relations) *APIDiff {
+	oldAPI := newAPISnapshot(old)
+	newAPI := newAPISnapshot(new)
+
+	var changes []*APIChange
+	for symbol, oe := range oldAPI {
+		ne, ok := newAPI[symbol]
+		switch {
+		case !ok && !oldAPI.containedIn(symbol, newAPI):
+			// 要素を保持する要素ごと削除された場合は、保持する要素の削除として扱う。
+			continue
+		case !ok:
+			c := &APIChange{
+				kind:    APIChangeRemoved,
+				symbol:  symbol,
+				message: fmt.Sprintf("%s %s was removed", oe.label, symbol),
+				bump:    SemverBumpMajor,
+			}
+			if parent, ok := newAPI[oe.parent]; ok {
+				c.position = parent.position
+			}
+			changes = append(changes, c)
+		case oe.signature != ne.signature:
+			changes = append(changes, &APIChange{
+				kind:     APIChangeChanged,
+				symbol:   symbol,
+				message:  fmt.Sprintf("%s %s changed from %q to %q", oe.label, symbol, oe.signature, ne.signature),
+				bump:     SemverBumpMajor,
+				position: ne.position,
+			})
+		}
+	}
+	for symbol, ne := range newAPI {
+		// 要素を保持する要素ごと追加された場合は、保持する要素の追加として扱う。
+		if _, ok := oldAPI[symbol]; ok || !newAPI.containedIn(symbol, oldAPI) {
+			continue
+		}
+		message := fmt.Sprintf("%s %s was added", ne.label, symbol)
+		if ne.additionBump == SemverBumpMajor {
+			message += " (breaks existing implementations)"
+		}
+		changes = append(changes, &APIChange{
+			kind:     APIChangeAdded,
+			symbol:   symbol,
+			message:  message,
+			bump:     ne.additionBump,
+			position: ne.position,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return strings.Compare(changes[i].symbol, changes[j].symbol) < 0
+	})
+
+	return &APIDiff{changes: changes}
+}
+
+// Changes は、検出した全ての変更をシンボル名順で返す。
+func (d *APIDiff) Changes() []*APIChange {
+	return append([]*APIChange{}, d.changes...)
+}
+
+// RequiredBump は、検出した変更を公開するために最低限必要なバージョンの上げ幅を返す。
+// 公開APIに変更がなければ SemverBumpPatch となる。
+func (d *APIDiff) RequiredBump() SemverBump {
+	bump := SemverBumpPatch
+	for _, c := range d.changes {
+		if c.bump > bump {
+			bump = c.bump
+		}
+	}
+	return bump
+}
+
+// JustifyingChanges は、 RequiredBump の根拠となる変更の一覧を返す。
+func (d *APIDiff) JustifyingChanges() []*APIChange {
+	bump := d.RequiredBump()
+	var changes []*APIChange
+	for _, c := range d.changes {
+		if c.bump == bump {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+func newAPISnapshot(r *Relations) apiSnapshot {
+	api := make(apiSnapshot)
+	for _, pkg := range r.Packages().AsSlice() {
+		if !isPublicPackage(pkg.Summary()) {
+			continue
+		}
+		pkgPath := pkg.Summary().Path().String()
+		api.put(pkgPath, "", "package", "package "+pkg.Summary().Name().String(), SemverBumpMinor, r.packagePosition(pkg.Summary().Path()))
+
+		detail := pkg.Detail()
+		for _, s := range detail.Structs() {
+			if !token.IsExported(s.Name().String()) {
+				continue
+			}
+			symbol := pkgPath + "." + s.Name().String()
+			api.put(symbol, pkgPath, "struct", "struct", SemverBumpMinor, r.Position(s.DefinedPos()))
+			for _, f := range s.Fields() {
+				if f.Exported() {
+					api.put(symbol+"."+f.Name().String(), symbol, "field", types.TypeString(f.Type().GoType(), nil), SemverBumpMinor, r.Position(f.DefinedPos()))
+				}
+			}
+			api.putMethods(r, symbol, s.Methods())
+		}
+		for _, i := range detail.Interfaces() {
+			if !token.IsExported(i.Name().String()) {
+				continue
+			}
+			symbol := pkgPath + "." + i.Name().String()
+
+			// 非公開メソッドを持つinterfaceはパッケージ外で実装できないため、メソッドの追加は互換性を壊さない。
+			// 非公開メソッドの追加で実装できなくなる変更を検出するため、シグネチャに含めて比較する。
+			methodAdditionBump := SemverBumpMajor
+			signature := "interface"
+			for _, m := range i.Methods() {
+				if !m.Exported() {
+					methodAdditionBump = SemverBumpMinor
+					signature = "sealed interface"
+				}
+			}
+			api.put(symbol, pkgPath, "interface", signature, SemverBumpMinor, r.Position(i.DefinedPos()))
+			for _, m := range i.Methods() {
+				if m.Exported() {
+					api.put(symbol+"."+m.Name().String(), symbol, "interface method", signatureKey(m.signature()), methodAdditionBump, r.Position(m.DefinedPos()))
+				}
+			}
+		}
+		for _, dt := range detail.DefinedTypes() {
+			if !token.IsExported(dt.Name().String()) {
+				continue
+			}
+			symbol := pkgPath + "." + dt.Name().String()
+			api.put(symbol, pkgPath, "defined type", "type "+types.TypeString(dt.UnderlyingType().GoType(), nil), SemverBumpMinor, r.Position(dt.DefinedPos()))
+			api.putMethods(r, symbol, dt.Methods())
+		}
+		for _, a := range detail.TypeAliases() {
+			if !token.IsExported(a.Name().String()) {
+				continue
+			}
+			symbol := pkgPath + "." + a.Name().String()
+			api.put(symbol, pkgPath, "type alias", "= "+types.TypeString(a.Target().GoType(), nil), SemverBumpMinor, r.Position(a.DefinedPos()))
+		}
+		for _, f := range detail.Functions() {
+			if f.Exported() {
+				api.put(pkgPath+"."+f.Name().String(), pkgPath, "function", signatureKey(f.signature()), SemverBumpMinor, r.Position(f.DefinedPos()))
+			}
+		}
+		api.putValues(r, pkgPath, pkg.GoPackage())
+	}
+	return api
+}
+
+func (api apiSnapshot) put(symbol, parent, label, signature string, additionBump SemverBump, position token.Position) {
+	api[symbol] = &apiElement{
+		label:        label,
+		signature:    signature,
+		additionBump: additionBump,
+		parent:       parent,
+		position:     position,
+	}
+}
+
+// containedIn は、 symbol を保持する要素が other にも存在するかを返す。
+func (api apiSnapshot) containedIn(symbol string, other apiSnapshot) bool {
+	parent := api[symbol].parent
+	if parent == "" {
+		return true
+	}
+	_, ok := other[parent]
+	return ok
+}
+
+// putValues は、パッケージレベルの公開された変数と定数を型と共に追加する。
+func (api apiSnapshot) putValues(r *Relations, pkgPath string, pkg *types.Package) {
+	if pkg == nil {
+		return
+	}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.Var:
+			if obj.Exported() {
+				api.put(pkgPath+"."+name, pkgPath, "variable", "var "+types.TypeString(obj.Type(), nil), SemverBumpMinor, r.Position(obj.Pos()))
+			}
+		case *types.Const:
+			if obj.Exported() {
+				api.put(pkgPath+"."+name, pkgPath, "constant", "const "+types.TypeString(obj.Type(), nil), SemverBumpMinor, r.Position(obj.Pos()))
+			}
+		}
+	}
+}
+
+func (api apiSnapshot) putMethods(r *Relations, ownerSymbol string, methods []*Function) {
+	for _, m := range methods {
+		if !m.Exported() {
+			continue
+		}
+		// レシーバの種類が変わるとメソッドセットが変わるため、シグネチャに含めて比較する。
+		receiver := "value receiver "
+		if m.PointerReceiver() {
+			receiver = "pointer receiver "
+		}
+		api.put(ownerSymbol+"."+m.Name().String(), ownerSymbol, "method", receiver+signatureKey(m.signature()), SemverBumpMinor, r.Position(m.DefinedPos()))
+	}
+}
+
+// isPublicPackage は、パッケージがモジュール外に公開されるパッケージかを判定する。
+func isPublicPackage(ps *PackageSummary) bool {
+	if ps.Name() == "main" {
+		return false
+	}
+	for _, elem := range strings.Split(ps.Path().String(), "/") {
+		if elem == "internal" {
+			return false
+		}
+	}
+	return true
+}
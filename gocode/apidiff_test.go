@@ -0,0 +1,253 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestCompareAPI(t *testing.T) {
+	const base = `package api
+
+type Client struct {
+	Name string
+}
+
+func (c *Client) Do(name string) error { return nil }
+
+type Doer interface {
+	Do(name string) error
+}
+
+type sealed interface {
+	Do(name string) error
+	seal()
+}
+
+type Sealed interface {
+	sealed
+}
+`
+	tests := []struct {
+		name    string
+		new     string
+		bump    gocode.SemverBump
+		symbols []string
+	}{
+		{
+			name:    "no change",
+			new:     base,
+			bump:    gocode.SemverBumpPatch,
+			symbols: nil,
+		},
+		{
+			name:    "struct added",
+			new:     base + "\ntype Extra struct{}\n",
+			bump:    gocode.SemverBumpMinor,
+			symbols: []string{"example.com/testmodule/api.Extra"},
+		},
+		{
+			name:    "interface added",
+			new:     base + "\ntype Closer interface{ Close() error }\n\ntype ReadDoer interface {\n\tDoer\n\tRead() error\n}\n",
+			bump:    gocode.SemverBumpMinor,
+			symbols: []string{"example.com/testmodule/api.Closer", "example.com/testmodule/api.ReadDoer"},
+		},
+		{
+			name: "method signature changed",
+			new: `package api
+
+type Client struct {
+	Name string
+}
+
+func (c *Client) Do(name string, retry int) error { return nil }
+
+type Doer interface {
+	Do(name string) error
+}
+
+type sealed interface {
+	Do(name string) error
+	seal()
+}
+
+type Sealed interface {
+	sealed
+}
+`,
+			bump:    gocode.SemverBumpMajor,
+			symbols: []string{"example.com/testmodule/api.Client.Do"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldRelations := loadTestModule(t, map[string]string{"api/api.go": base})
+			newRelations := loadTestModule(t, map[string]string{"api/api.go": test.new})
+
+			diff := gocode.CompareAPI(oldRelations, newRelations)
+			if diff.RequiredBump() != test.bump {
+				t.Errorf("RequiredBump() = %s, want %s", diff.RequiredBump(), test.bump)
+			}
+
+			var symbols []string
+			for _, c := range diff.JustifyingChanges() {
+				symbols = append(symbols, c.Symbol())
+			}
+			if len(symbols) != len(test.symbols) {
+				t.Fatalf("JustifyingChanges() = %v, want %v", symbols, test.symbols)
+			}
+			for i := range symbols {
+				if symbols[i] != test.symbols[i] {
+					t.Errorf("JustifyingChanges()[%d] = %s, want %s", i, symbols[i], test.symbols[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompareAPI_InterfaceMethodAddition(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		bump gocode.SemverBump
+	}{
+		{
+			name: "open interface",
+			old:  "package api\n\ntype Doer interface{ Do() }\n",
+			new:  "package api\n\ntype Doer interface {\n\tDo()\n\tUndo()\n}\n",
+			bump: gocode.SemverBumpMajor,
+		},
+		{
+			name: "sealed interface",
+			old:  "package api\n\ntype Doer interface {\n\tDo()\n\tseal()\n}\n",
+			new:  "package api\n\ntype Doer interface {\n\tDo()\n\tUndo()\n\tseal()\n}\n",
+			bump: gocode.SemverBumpMinor,
+		},
+		{
+			name: "open interface sealed",
+			old:  "package api\n\ntype Doer interface{ Do() }\n",
+			new:  "package api\n\ntype Doer interface {\n\tDo()\n\tseal()\n}\n",
+			bump: gocode.SemverBumpMajor,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldRelations := loadTestModule(t, map[string]string{"api/api.go": test.old})
+			newRelations := loadTestModule(t, map[string]string{"api/api.go": test.new})
+
+			if bump := gocode.CompareAPI(oldRelations, newRelations).RequiredBump(); bump != test.bump {
+				t.Errorf("RequiredBump() = %s, want %s", bump, test.bump)
+			}
+		})
+	}
+}
+
+func TestCompareAPI_Removal(t *testing.T) {
+	oldRelations := loadTestModule(t, map[string]string{"api/api.go": "package api\n\ntype Doer interface{ Do() }\n\ntype Client struct{ Name string }\n"})
+	newRelations := loadTestModule(t, map[string]string{"api/api.go": "package api\n\ntype Client struct{}\n"})
+
+	diff := gocode.CompareAPI(oldRelations, newRelations)
+	if diff.RequiredBump() != gocode.SemverBumpMajor {
+		t.Errorf("RequiredBump() = %s, want %s", diff.RequiredBump(), gocode.SemverBumpMajor)
+	}
+	want := []string{"example.com/testmodule/api.Client.Name", "example.com/testmodule/api.Doer"}
+	changes := diff.Changes()
+	if len(changes) != len(want) {
+		t.Fatalf("len(Changes()) = %d, want %d", len(changes), len(want))
+	}
+	for i := range want {
+		if changes[i].Symbol() != want[i] || changes[i].Kind() != gocode.APIChangeRemoved {
+			t.Errorf("Changes()[%d] = %s %s, want removed %s", i, changes[i].Kind(), changes[i].Symbol(), want[i])
+		}
+	}
+}
+
+func TestCompareAPI_PackageLevel(t *testing.T) {
+	const base = `package api
+
+import "example.com/testmodule/v1"
+
+var Default = &Client{}
+
+const Version = "1.0"
+
+type Config = v1.Config
+
+type Client struct{}
+
+func New(name string) *Client { return nil }
+`
+	files := func(api string) map[string]string {
+		return map[string]string{
+			"api/api.go": api,
+			"v1/v1.go":   "package v1\n\ntype Config struct{ Name string }\n",
+			"v2/v2.go":   "package v2\n\ntype Config struct{ Name string }\n",
+		}
+	}
+	tests := []struct {
+		name    string
+		new     string
+		bump    gocode.SemverBump
+		symbols []string
+	}{
+		{
+			name:    "function removed",
+			new:     strings.Replace(base, "func New(name string) *Client { return nil }\n", "", 1),
+			bump:    gocode.SemverBumpMajor,
+			symbols: []string{"example.com/testmodule/api.New"},
+		},
+		{
+			name:    "function changed",
+			new:     strings.Replace(base, "New(name string)", "New(name string, retry int)", 1),
+			bump:    gocode.SemverBumpMajor,
+			symbols: []string{"example.com/testmodule/api.New"},
+		},
+		{
+			name:    "function added",
+			new:     base + "\nfunc Must(c *Client) *Client { return c }\n",
+			bump:    gocode.SemverBumpMinor,
+			symbols: []string{"example.com/testmodule/api.Must"},
+		},
+		{
+			name:    "variable type changed",
+			new:     strings.Replace(base, "var Default = &Client{}", "var Default = Client{}", 1),
+			bump:    gocode.SemverBumpMajor,
+			symbols: []string{"example.com/testmodule/api.Default"},
+		},
+		{
+			name:    "constant removed",
+			new:     strings.Replace(base, "const Version = \"1.0\"\n", "", 1),
+			bump:    gocode.SemverBumpMajor,
+			symbols: []string{"example.com/testmodule/api.Version"},
+		},
+		{
+			name:    "alias target changed",
+			new:     strings.NewReplacer("testmodule/v1", "testmodule/v2", "v1.Config", "v2.Config").Replace(base),
+			bump:    gocode.SemverBumpMajor,
+			symbols: []string{"example.com/testmodule/api.Config"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			oldRelations := loadTestModule(t, files(base))
+			newRelations := loadTestModule(t, files(test.new))
+
+			diff := gocode.CompareAPI(oldRelations, newRelations)
+			if diff.RequiredBump() != test.bump {
+				t.Errorf("RequiredBump() = %s, want %s", diff.RequiredBump(), test.bump)
+			}
+			var symbols []string
+			for _, c := range diff.JustifyingChanges() {
+				symbols = append(symbols, c.Symbol())
+			}
+			if strings.Join(symbols, " ") != strings.Join(test.symbols, " ") {
+				t.Errorf("JustifyingChanges() = %v, want %v", symbols, test.symbols)
+			}
+		})
+	}
+}
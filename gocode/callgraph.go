@@ -0,0 +1,567 @@
+package gocode
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+)
+
+type (
+	// CallGraphMode は、コールグラフの構築方法を表す。
+	CallGraphMode int
+
+	// FunctionID は、関数やメソッドを types.Func.FullName の形式で識別する。
+	// 例: "example.com/pkg.Func", "(*example.com/pkg.Type).Method"
+	FunctionID string
+
+	// CallEdgeKind は、呼び出しの種類を表す。
+	CallEdgeKind string
+
+	// CallEdge は、関数から関数への呼び出しを表す。
+	CallEdge struct {
+		caller   FunctionID
+		callee   FunctionID
+		kind     CallEdgeKind
+		pos      token.Pos
+		position token.Position
+	}
+
+	// CallGraph は、解析したパッケージの関数の呼び出し関係を表す。
+	CallGraph struct {
+		mode      CallGraphMode
+		functions map[FunctionID]struct{}
+		callees   map[FunctionID][]*CallEdge
+		callers   map[FunctionID][]*CallEdge
+	}
+
+	// functionFacts は、関数本体から収集した呼び出しと型の生成の情報。
+	functionFacts struct {
+		id             FunctionID
+		staticCalls    []*CallEdge
+		dynamicCalls   []*dynamicCall
+		instantiations []string
+		// main は main パッケージの main 関数であるかを表す。
+		main bool
+		// init は init 関数かパッケージレベルの変数の初期化であるかを表す。
+		init bool
+		// exported は公開された関数かメソッドであるかを表す。
+		exported bool
+	}
+
+	// dynamicCall は、interfaceのメソッドの呼び出しを表す。
+	dynamicCall struct {
+		pos    token.Pos
+		method *types.Func
+		iface  *types.Interface
+		// ifaceKey はメソッドが宣言されたinterfaceの "パッケージパス.名前" 。名前がなければ空となる。
+		ifaceKey string
+	}
+
+	// callGraphBuilder は、 Relations から CallGraph を構築する。
+	callGraphBuilder struct {
+		relations *Relations
+		graph     *CallGraph
+		facts     map[FunctionID]*functionFacts
+		// implementers は、 Relations 内の名前付きの型を "パッケージパス.名前" をキーとして保持する。
+		implementers map[string]types.Type
+		// edges は、重複した辺を追加しないために追加済みの辺を保持する。
+		edges map[string]struct{}
+	}
+)
+
+const (
+	// CallGraphStatic は、静的に解決できる関数とメソッドの呼び出しのみを辺とする。
+	CallGraphStatic CallGraphMode = iota
+	// CallGraphCHA は、interfaceのメソッドの呼び出しを、実装している全ての型のメソッドへの辺とする(Class Hierarchy Analysis)。
+	CallGraphCHA
+	// CallGraphRTA は、interfaceのメソッドの呼び出しを、到達可能な関数内で生成される型のメソッドへの辺に限定する(Rapid Type Analysis)。
+	// init 関数と main パッケージの main 関数を起点とし、 main パッケージがなければ main 関数の代わりに公開された関数とメソッドを起点とする。
+	CallGraphRTA
+)
+
+const (
+	CallEdgeStatic  CallEdgeKind = "static"
+	CallEdgeDynamic CallEdgeKind = "dynamic"
+)
+
+func (m CallGraphMode) String() string {
+	switch m {
+	case CallGraphStatic:
+		return "static"
+	case CallGraphCHA:
+		return "cha"
+	case CallGraphRTA:
+		return "rta"
+	default:
+		return fmt.Sprintf("CallGraphMode(%d)", int(m))
+	}
+}
+
+// ParseCallGraphMode は、"static", "cha", "rta" のいずれかの文字列を CallGraphMode に変換する。
+func ParseCallGraphMode(s string) (CallGraphMode, error) {
+	for _, m := range []CallGraphMode{CallGraphStatic, CallGraphCHA, CallGraphRTA} {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return CallGraphStatic, fmt.Errorf("unknown call graph mode: %q", s)
+}
+
+func (id FunctionID) String() string {
+	return string(id)
+}
+
+func (e *CallEdge) Caller() FunctionID {
+	return e.caller
+}
+
+func (e *CallEdge) Callee() FunctionID {
+	return e.callee
+}
+
+func (e *CallEdge) Kind() CallEdgeKind {
+	return e.kind
+}
+
+func (e *CallEdge) Pos() token.Pos {
+	return e.pos
+}
+
+func (e *CallEdge) Position() token.Position {
+	return e.position
+}
+
+// CallGraph は、解析したパッケージの構文と型情報からコールグラフを構築する。
+func (r *Relations) CallGraph(mode CallGraphMode) *CallGraph {
+	b := &callGraphBuilder{
+		relations: r,
+		graph: &CallGraph{
+			mode:      mode,
+			functions: make(map[FunctionID]struct{}),
+			callees:   make(map[FunctionID][]*CallEdge),
+			callers:   make(map[FunctionID][]*CallEdge),
+		},
+		facts:        make(map[FunctionID]*functionFacts),
+		implementers: make(map[string]types.Type),
+		edges:        make(map[string]struct{}),
+	}
+	b.build()
+	return b.graph
+}
+
+func (cg *CallGraph) Mode() CallGraphMode {
+	return cg.mode
+}
+
+// Functions は、コールグラフに含まれる関数の一覧を返す。
+func (cg *CallGraph) Functions() []FunctionID {
+	var ids []FunctionID
+	for id := range cg.functions {
+		ids = append(ids, id)
+	}
+	sortFunctionIDs(ids)
+	return ids
+}
+
+// Callees は、 id から呼び出している辺の一覧を返す。
+func (cg *CallGraph) Callees(id FunctionID) []*CallEdge {
+	return append([]*CallEdge{}, cg.callees[id]...)
+}
+
+// Callers は、 id を呼び出している辺の一覧を返す。
+func (cg *CallGraph) Callers(id FunctionID) []*CallEdge {
+	return append([]*CallEdge{}, cg.callers[id]...)
+}
+
+// ReachableFrom は、 id から呼び出しを辿って到達可能な関数の一覧を返す。 id 自身は含まない。
+func (cg *CallGraph) ReachableFrom(id FunctionID) []FunctionID {
+	visited := map[FunctionID]struct{}{id: {}}
+	queue := []FunctionID{id}
+	var reachable []FunctionID
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, e := range cg.callees[current] {
+			if _, ok := visited[e.callee]; ok {
+				continue
+			}
+			visited[e.callee] = struct{}{}
+			reachable = append(reachable, e.callee)
+			queue = append(queue, e.callee)
+		}
+	}
+	sortFunctionIDs(reachable)
+	return reachable
+}
+
+// WriteDOT は、コールグラフをGraphvizのDOT形式で書き出す。
+func (cg *CallGraph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph callgraph {")
+	for _, id := range cg.Functions() {
+		fmt.Fprintf(bw, "\t%q;\n", id)
+	}
+	for _, id := range cg.Functions() {
+		for _, e := range cg.callees[id] {
+			if e.kind == CallEdgeDynamic {
+				fmt.Fprintf(bw, "\t%q -> %q [style=dashed];\n", e.caller, e.callee)
+			} else {
+				fmt.Fprintf(bw, "\t%q -> %q;\n", e.caller, e.callee)
+			}
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func (cg *CallGraph) addEdge(e *CallEdge) {
+	cg.functions[e.caller] = struct{}{}
+	cg.functions[e.callee] = struct{}{}
+	cg.callees[e.caller] = append(cg.callees[e.caller], e)
+	cg.callers[e.callee] = append(cg.callers[e.callee], e)
+}
+
+func sortFunctionIDs(ids []FunctionID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+}
+
+func (b *callGraphBuilder) build() {
+	for _, s := range b.relations.Structs().StructAll() {
+		b.implementers[typeKey(s.Type().GoType())] = s.Type().GoType()
+	}
+	for _, dt := range b.relations.DefinedTypes().DefinedTypeAll() {
+		b.implementers[typeKey(dt.Type().GoType())] = dt.Type().GoType()
+	}
+
+	packages := b.relations.Packages().AsSlice()
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Summary().Path() < packages[j].Summary().Path()
+	})
+	for _, pkg := range packages {
+		if pkg.typesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.files {
+			b.collectFile(pkg, file)
+		}
+	}
+
+	switch b.graph.mode {
+	case CallGraphStatic:
+		for _, facts := range b.sortedFacts() {
+			b.addStaticCalls(facts)
+		}
+	case CallGraphCHA:
+		for _, facts := range b.sortedFacts() {
+			b.addStaticCalls(facts)
+			for _, call := range facts.dynamicCalls {
+				for _, typ := range b.resolveImplementers(call) {
+					b.addDynamicCall(facts.id, call, typ)
+				}
+			}
+		}
+	case CallGraphRTA:
+		b.buildRTA()
+	}
+
+	for id := range b.graph.callees {
+		sortCallEdges(b.graph.callees[id])
+	}
+	for id := range b.graph.callers {
+		sortCallEdges(b.graph.callers[id])
+	}
+}
+
+// buildRTA は、起点の関数から到達可能な関数のみを辿り、生成された型のメソッドのみを動的な呼び出し先とする。
+func (b *callGraphBuilder) buildRTA() {
+	hasMain := false
+	for _, facts := range b.facts {
+		hasMain = hasMain || facts.main
+	}
+	var roots []FunctionID
+	for _, facts := range b.sortedFacts() {
+		if facts.init || (hasMain && facts.main) || (!hasMain && facts.exported) {
+			roots = append(roots, facts.id)
+		}
+	}
+
+	reachable := make(map[FunctionID]struct{})
+	live := make(map[string]struct{})
+	var processed []*functionFacts
+	queue := append([]FunctionID{}, roots...)
+	for {
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			if _, ok := reachable[id]; ok {
+				continue
+			}
+			reachable[id] = struct{}{}
+			b.graph.functions[id] = struct{}{}
+
+			facts, ok := b.facts[id]
+			if !ok {
+				continue
+			}
+			processed = append(processed, facts)
+			b.addStaticCalls(facts)
+			for _, e := range facts.staticCalls {
+				queue = append(queue, e.callee)
+			}
+			for _, key := range facts.instantiations {
+				live[key] = struct{}{}
+			}
+		}
+
+		// 新たに生成された型があれば、到達済みの動的な呼び出しを解決し直す。
+		for _, facts := range processed {
+			for _, call := range facts.dynamicCalls {
+				for _, typ := range b.resolveImplementers(call) {
+					if _, ok := live[typeKey(typ)]; !ok {
+						continue
+					}
+					if e, added := b.addDynamicCall(facts.id, call, typ); added {
+						queue = append(queue, e.callee)
+					}
+				}
+			}
+		}
+		if len(queue) == 0 {
+			return
+		}
+	}
+}
+
+func (b *callGraphBuilder) sortedFacts() []*functionFacts {
+	var facts []*functionFacts
+	for _, f := range b.facts {
+		facts = append(facts, f)
+	}
+	sort.Slice(facts, func(i, j int) bool {
+		return facts[i].id < facts[j].id
+	})
+	return facts
+}
+
+func (b *callGraphBuilder) addStaticCalls(facts *functionFacts) {
+	b.graph.functions[facts.id] = struct{}{}
+	for _, e := range facts.staticCalls {
+		b.addEdgeOnce(e)
+	}
+}
+
+func (b *callGraphBuilder) addDynamicCall(caller FunctionID, call *dynamicCall, typ types.Type) (*CallEdge, bool) {
+	method, ok := lookupMethod(typ, call.method.Name())
+	if !ok {
+		return nil, false
+	}
+	e := &CallEdge{
+		caller:   caller,
+		callee:   FunctionID(method.FullName()),
+		kind:     CallEdgeDynamic,
+		pos:      call.pos,
+		position: b.relations.Position(call.pos),
+	}
+	return e, b.addEdgeOnce(e)
+}
+
+func (b *callGraphBuilder) addEdgeOnce(e *CallEdge) bool {
+	key := fmt.Sprintf("%s %s %d", e.caller, e.callee, e.pos)
+	if _, ok := b.edges[key]; ok {
+		return false
+	}
+	b.edges[key] = struct{}{}
+	b.graph.addEdge(e)
+	return true
+}
+
+// resolveImplementers は、動的な呼び出しの呼び出し先となりうる型の一覧を返す。
+// interfaceが Relations に含まれていれば実装関係を、含まれていなければシグネチャの一致を用いる。
+func (b *callGraphBuilder) resolveImplementers(call *dynamicCall) []types.Type {
+	var res []types.Type
+	if iface, ok := b.lookupInterface(call.ifaceKey); ok {
+		for _, s := range b.relations.Structs().StructAll() {
+			impl, ok := s.ImplementInterfaces().Get(iface.PackageSummary().Name(), iface.Name())
+			if ok && impl.PackageSummary().Equal(iface.PackageSummary()) {
+				res = append(res, s.Type().GoType())
+			}
+		}
+		for _, dt := range b.relations.DefinedTypes().DefinedTypeAll() {
+			if dt.Implements(iface) {
+				res = append(res, dt.Type().GoType())
+			}
+		}
+	} else {
+		for _, typ := range b.implementers {
+			if implementsBySignature(typ, call.iface) {
+				res = append(res, typ)
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return typeKey(res[i]) < typeKey(res[j])
+	})
+	return res
+}
+
+func (b *callGraphBuilder) lookupInterface(key string) (*Interface, bool) {
+	if key == "" {
+		return nil, false
+	}
+	for _, i := range b.relations.Interfaces().InterfaceAll() {
+		if fmt.Sprintf("%s.%s", i.PackageSummary().Path(), i.Name()) == key {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+// collectFile は、ファイル内の関数の本体から呼び出しと型の生成を収集する。
+// パッケージレベルの変数の初期化式は、パッケージの init 関数の一部として扱う。
+func (b *callGraphBuilder) collectFile(pkg *Package, file *ast.File) {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			fn, ok := pkg.typesInfo.Defs[d.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			facts := b.factsOf(FunctionID(fn.FullName()))
+			facts.main = d.Recv == nil && d.Name.Name == "main" && pkg.Summary().Name() == "main"
+			facts.init = d.Recv == nil && d.Name.Name == "init"
+			facts.exported = fn.Exported()
+			if d.Body != nil {
+				b.collectNode(pkg, facts, d.Body)
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.VAR {
+				continue
+			}
+			facts := b.factsOf(FunctionID(pkg.Summary().Path().String() + ".init"))
+			facts.init = true
+			b.collectNode(pkg, facts, d)
+		}
+	}
+}
+
+func (b *callGraphBuilder) factsOf(id FunctionID) *functionFacts {
+	facts, ok := b.facts[id]
+	if !ok {
+		facts = &functionFacts{id: id}
+		b.facts[id] = facts
+	}
+	return facts
+}
+
+func (b *callGraphBuilder) collectNode(pkg *Package, facts *functionFacts, node ast.Node) {
+	info := pkg.typesInfo
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.CompositeLit:
+			facts.addInstantiation(info.TypeOf(e))
+		case *ast.ValueSpec:
+			if e.Type != nil {
+				facts.addInstantiation(info.TypeOf(e.Type))
+			}
+		case *ast.CallExpr:
+			b.collectCall(info, facts, e)
+		}
+		return true
+	})
+}
+
+func (b *callGraphBuilder) collectCall(info *types.Info, facts *functionFacts, call *ast.CallExpr) {
+	fun := call.Fun
+	for {
+		p, ok := fun.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = p.X
+	}
+
+	if tv, ok := info.Types[fun]; ok && tv.IsType() {
+		facts.addInstantiation(tv.Type)
+		return
+	}
+
+	var callee *types.Func
+	switch f := fun.(type) {
+	case *ast.Ident:
+		if builtin, ok := info.Uses[f].(*types.Builtin); ok {
+			if builtin.Name() == "new" && len(call.Args) == 1 {
+				facts.addInstantiation(info.TypeOf(call.Args[0]))
+			}
+			return
+		}
+		callee, _ = info.Uses[f].(*types.Func)
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[f]; ok {
+			method, ok := sel.Obj().(*types.Func)
+			if !ok {
+				return
+			}
+			if sel.Kind() == types.MethodVal && types.IsInterface(sel.Recv()) {
+				facts.dynamicCalls = append(facts.dynamicCalls, newDynamicCall(call.Pos(), method, sel.Recv()))
+				return
+			}
+			callee = method
+		} else {
+			callee, _ = info.Uses[f.Sel].(*types.Func)
+		}
+	}
+	if callee == nil {
+		return
+	}
+
+	facts.staticCalls = append(facts.staticCalls, &CallEdge{
+		caller:   facts.id,
+		callee:   FunctionID(callee.FullName()),
+		kind:     CallEdgeStatic,
+		pos:      call.Pos(),
+		position: b.relations.Position(call.Pos()),
+	})
+}
+
+func newDynamicCall(pos token.Pos, method *types.Func, recv types.Type) *dynamicCall {
+	call := &dynamicCall{
+		pos:    pos,
+		method: method,
+		iface:  recv.Underlying().(*types.Interface),
+	}
+	// 埋め込まれたinterfaceのメソッドは、宣言されたinterfaceの実装関係から解決する。
+	if named, ok := namedOf(method.Type().(*types.Signature).Recv().Type()); ok {
+		call.ifaceKey = typeKey(named)
+	}
+	return call
+}
+
+func (f *functionFacts) addInstantiation(typ types.Type) {
+	if named, ok := namedOf(typ); ok {
+		f.instantiations = append(f.instantiations, typeKey(named))
+	}
+}
+
+// typeKey は、名前付きの型を "パッケージパス.名前" の形式の文字列に変換する。
+func typeKey(typ types.Type) string {
+	named, ok := namedOf(typ)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s", named.Obj().Pkg().Path(), named.Obj().Name())
+}
+
+func sortCallEdges(edges []*CallEdge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].callee != edges[j].callee {
+			return edges[i].callee < edges[j].callee
+		}
+		return edges[i].pos < edges[j].pos
+	})
+}
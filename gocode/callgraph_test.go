@@ -0,0 +1,110 @@
+package gocode_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestRelations_CallGraph(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"shape/shape.go": `package shape
+
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct{ R float64 }
+
+func (c Circle) Area() float64 { return 3 * c.R * c.R }
+
+type Square struct{ W float64 }
+
+func (s *Square) Area() float64 { return s.W * s.W }
+
+func Total(shapes ...Shape) float64 {
+	var total float64
+	for _, s := range shapes {
+		total += s.Area()
+	}
+	return total
+}
+`,
+		"cmd/app/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/testmodule/shape"
+)
+
+func main() {
+	fmt.Println(shape.Total(shape.Circle{R: 1}))
+}
+`,
+	})
+
+	const (
+		mainID   = gocode.FunctionID("example.com/testmodule/cmd/app.main")
+		totalID  = gocode.FunctionID("example.com/testmodule/shape.Total")
+		circleID = gocode.FunctionID("(example.com/testmodule/shape.Circle).Area")
+		squareID = gocode.FunctionID("(*example.com/testmodule/shape.Square).Area")
+	)
+
+	tests := []struct {
+		mode    gocode.CallGraphMode
+		callees []gocode.FunctionID
+	}{
+		{mode: gocode.CallGraphStatic, callees: nil},
+		{mode: gocode.CallGraphCHA, callees: []gocode.FunctionID{squareID, circleID}},
+		{mode: gocode.CallGraphRTA, callees: []gocode.FunctionID{circleID}},
+	}
+	for _, test := range tests {
+		t.Run(test.mode.String(), func(t *testing.T) {
+			cg := r.CallGraph(test.mode)
+
+			var callees []gocode.FunctionID
+			for _, e := range cg.Callees(totalID) {
+				if e.Kind() != gocode.CallEdgeDynamic {
+					t.Errorf("Callees(Total) kind = %s, want %s", e.Kind(), gocode.CallEdgeDynamic)
+				}
+				callees = append(callees, e.Callee())
+			}
+			if len(callees) != len(test.callees) {
+				t.Fatalf("Callees(Total) = %v, want %v", callees, test.callees)
+			}
+			for i := range callees {
+				if callees[i] != test.callees[i] {
+					t.Errorf("Callees(Total)[%d] = %s, want %s", i, callees[i], test.callees[i])
+				}
+			}
+
+			callers := cg.Callers(totalID)
+			if len(callers) != 1 || callers[0].Caller() != mainID || callers[0].Kind() != gocode.CallEdgeStatic {
+				t.Errorf("Callers(Total) = %v, want static call from %s", callers, mainID)
+			}
+		})
+	}
+
+	cg := r.CallGraph(gocode.CallGraphRTA)
+	reachable := cg.ReachableFrom(mainID)
+	want := []gocode.FunctionID{circleID, totalID, "fmt.Println"}
+	if len(reachable) != len(want) {
+		t.Fatalf("ReachableFrom(main) = %v, want %v", reachable, want)
+	}
+	for i := range want {
+		if reachable[i] != want[i] {
+			t.Errorf("ReachableFrom(main)[%d] = %s, want %s", i, reachable[i], want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := cg.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"example.com/testmodule/shape.Total" -> "(example.com/testmodule/shape.Circle).Area" [style=dashed];`) {
+		t.Errorf("WriteDOT() does not contain dynamic edge:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,149 @@
+package gocode
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Finding は、 RunChecks の検査で検出された問題を表す。
+type Finding struct {
+	check CheckName
+	// rule は検出の種類を表すルールのID。検査の名前、または検査の名前と種類を "/" で繋いだもの。
+	rule string
+	// symbol は問題のある要素のパッケージパス付きの名前、またはパッケージパス。
+	symbol   string
+	message  string
+	position token.Position
+}
+
+func (f *Finding) Check() CheckName {
+	return f.check
+}
+
+// Rule は、"deadcode/struct" や "nearmiss" のような検出の種類を表すルールのIDを返す。
+func (f *Finding) Rule() string {
+	return f.rule
+}
+
+func (f *Finding) Symbol() string {
+	return f.symbol
+}
+
+// Message は、位置を含まない問題の説明を返す。複数行となる場合がある。
+func (f *Finding) Message() string {
+	return f.message
+}
+
+// Position は、問題のある要素の位置を返す。パッケージメトリクスの場合はパッケージの最初のファイルのpackage句の位置となる。
+// 位置が特定できない場合は Filename が空となる。
+func (f *Finding) Position() token.Position {
+	return f.position
+}
+
+func (f *Finding) String() string {
+	if f.position.Filename == "" {
+		return fmt.Sprintf("%s: %s [%s]", f.symbol, f.message, f.check)
+	}
+	return fmt.Sprintf("%s: %s [%s]", f.position, f.message, f.check)
+}
+
+// RunChecks は、 settings で有効な検査を CheckNames の順に実行し、検出された問題の一覧を返す。
+// settings が nil の場合は、全ての検査を既定の設定で実行する。
+func (r *Relations) RunChecks(settings *CheckSettings) ([]*Finding, error) {
+	if settings == nil {
+		settings = &CheckSettings{}
+	}
+	var findings []*Finding
+	if settings.CheckEnabled(CheckDeadCode) {
+		deadCode, err := r.DeadCode(&settings.DeadCode)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range deadCode {
+			findings = append(findings, &Finding{
+				check:    CheckDeadCode,
+				rule:     ruleID(CheckDeadCode, f.Kind().String()),
+				symbol:   f.Symbol().String(),
+				message:  fmt.Sprintf("unused %s %s", f.Kind(), f.Symbol()),
+				position: f.Position(),
+			})
+		}
+	}
+	if settings.CheckEnabled(CheckNearMiss) {
+		for _, nm := range r.NearMisses(&settings.NearMiss) {
+			findings = append(findings, &Finding{
+				check:    CheckNearMiss,
+				rule:     CheckNearMiss.String(),
+				symbol:   fmt.Sprintf("%s.%s", nm.Struct().PackageSummary().Path(), nm.Struct().Name()),
+				message:  nm.String(),
+				position: r.Position(nm.Struct().DefinedPos()),
+			})
+		}
+	}
+	if settings.CheckEnabled(CheckMetrics) {
+		for _, v := range CheckPackageMetrics(r.PackageMetrics(), &settings.Metrics) {
+			pkgPath := v.Metrics().PackageSummary().Path()
+			findings = append(findings, &Finding{
+				check:    CheckMetrics,
+				rule:     ruleID(CheckMetrics, v.Metric()),
+				symbol:   pkgPath.String(),
+				message:  fmt.Sprintf("%s of %s %s exceeds %s", v.Metric(), pkgPath, formatMetric(v.Value()), formatMetric(v.Threshold())),
+				position: r.packagePosition(pkgPath),
+			})
+		}
+	}
+	if settings.CheckEnabled(CheckCycles) {
+		for _, cycle := range r.TypeGraph().Cycles() {
+			// 連結リストのような自身のみを参照する型は、設計上の問題ではないため報告しない。
+			if len(cycle) < 2 {
+				continue
+			}
+			ids := make([]string, 0, len(cycle))
+			for _, id := range cycle {
+				ids = append(ids, id.String())
+			}
+			f := &Finding{
+				check:   CheckCycles,
+				rule:    CheckCycles.String(),
+				symbol:  ids[0],
+				message: "cycle between types " + strings.Join(ids, " "),
+			}
+			if tn, ok := r.lookupTypeName(ids[0]); ok {
+				f.position = r.Position(tn.Pos())
+			}
+			findings = append(findings, f)
+		}
+	}
+	if settings.CheckEnabled(CheckUntyped) {
+		for _, u := range r.EmptyInterfaceUsages() {
+			name := u.Name()
+			if name == "" {
+				name = fmt.Sprintf("#%d", u.Index())
+			}
+			findings = append(findings, &Finding{
+				check:    CheckUntyped,
+				rule:     ruleID(CheckUntyped, u.Kind().String()),
+				symbol:   u.Owner(),
+				message:  fmt.Sprintf("%s %s of %s has type %s", u.Kind(), name, u.Owner(), types.TypeString(u.Type().GoType(), nil)),
+				position: u.Position(),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// ruleID は、検査の名前と検出の種類からルールのIDを作る。種類に含まれる空白は "-" に置き換える。
+func ruleID(check CheckName, kind string) string {
+	return check.String() + "/" + strings.ReplaceAll(kind, " ", "-")
+}
+
+// packagePosition は、パッケージの最初のファイルのpackage句の位置を返す。
+func (r *Relations) packagePosition(pkgPath PackagePath) token.Position {
+	pkg, ok := r.packages.Get(pkgPath)
+	if !ok || len(pkg.files) == 0 {
+		return token.Position{}
+	}
+	return r.Position(pkg.files[0].Package)
+}
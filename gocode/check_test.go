@@ -0,0 +1,47 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestRelations_RunChecks_Cycles(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"model/model.go": `package model
+
+type List struct {
+	Next *List
+}
+
+type Builder struct{}
+
+func (b *Builder) With(name string) *Builder { return b }
+
+type Node struct {
+	Tree *Tree
+}
+
+type Tree struct {
+	Root *Node
+}
+`,
+	})
+
+	findings, err := r.RunChecks(&gocode.CheckSettings{Enabled: []gocode.CheckName{gocode.CheckCycles}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Message())
+	}
+	// 自身のみを参照する List と Builder は報告しない。
+	want := []string{
+		"cycle between types example.com/testmodule/model.Node example.com/testmodule/model.Tree",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("RunChecks(cycles) =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
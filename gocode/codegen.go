@@ -0,0 +1,104 @@
+package gocode
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+)
+
+type (
+	// codegenImports は、コード生成時に出力するファイルのimportを管理する。
+	codegenImports struct {
+		// outputPath は出力するファイルのパッケージパス。このパッケージの型は修飾しない。
+		outputPath string
+		// aliases はパッケージパスごとの優先して使うパッケージ名。解析したパッケージのimportのエイリアスを設定する。
+		aliases map[string]string
+		// names はimportしたパッケージのパスごとのパッケージ名。
+		names map[string]string
+		// paths はimportしたパッケージ名ごとのパッケージパス。
+		paths map[string]string
+	}
+)
+
+func newCodegenImports(outputPath string) *codegenImports {
+	return &codegenImports{
+		outputPath: outputPath,
+		aliases:    make(map[string]string),
+		names:      make(map[string]string),
+		paths:      make(map[string]string),
+	}
+}
+
+// useAliases は、 imports のエイリアスをパッケージ名として優先して使うように設定する。
+func (ci *codegenImports) useAliases(imports []*Import) {
+	for _, i := range imports {
+		if i.HasAliasName() && i.AliasName() != "_" && i.AliasName() != "." {
+			ci.aliases[i.PackageSummary().Path().String()] = i.AliasName().String()
+		}
+	}
+}
+
+// add は、パッケージをimportに加えて、コード中で使うパッケージ名を返す。
+// パッケージ名が他のパッケージと衝突する場合は、末尾に数字を付与する。
+func (ci *codegenImports) add(path, name string) string {
+	if path == ci.outputPath {
+		return ""
+	}
+	if n, ok := ci.names[path]; ok {
+		return n
+	}
+	if alias, ok := ci.aliases[path]; ok {
+		name = alias
+	}
+	candidate := name
+	for i := 2; ; i++ {
+		if _, ok := ci.paths[candidate]; !ok {
+			break
+		}
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	ci.names[path] = candidate
+	ci.paths[candidate] = path
+	return candidate
+}
+
+// qualifier は、 types.TypeString で型をパッケージ名で修飾するための types.Qualifier を返す。
+func (ci *codegenImports) qualifier() types.Qualifier {
+	return func(pkg *types.Package) string {
+		return ci.add(pkg.Path(), pkg.Name())
+	}
+}
+
+// typeString は、 typ を出力するファイルから見た型の文字列に変換する。
+func (ci *codegenImports) typeString(typ types.Type) string {
+	return types.TypeString(typ, ci.qualifier())
+}
+
+// writeTo は、import宣言を buf に書き出す。
+func (ci *codegenImports) writeTo(buf *bytes.Buffer) {
+	if len(ci.names) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(ci.names))
+	for path := range ci.names {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	buf.WriteString("import (\n")
+	for _, path := range paths {
+		fmt.Fprintf(buf, "\t%s %q\n", ci.names[path], path)
+	}
+	buf.WriteString(")\n\n")
+}
+
+// formatSource は、生成したソースコードを gofmt で整形する。
+func formatSource(src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w\n%s", err, src)
+	}
+	return formatted, nil
+}
@@ -22,10 +22,19 @@ func (p *PackageMap) AsSlice() []*Package {
 	return packages
 }
 
+func (p *PackageMap) Get(pkgPath PackagePath) (pkg *Package, ok bool) {
+	pkg, ok = p.m[pkgPath]
+	return pkg, ok
+}
+
 func (p *PackageMap) add(pkg *Package) {
 	p.m[pkg.Summary().Path()] = pkg
 }
 
+func (p *PackageMap) remove(pkgPath PackagePath) {
+	delete(p.m, pkgPath)
+}
+
 func (p PackageMap) Contains(pkgPath PackagePath) bool {
 	_, ok := p.m[pkgPath]
 	return ok
@@ -33,6 +42,8 @@ func (p PackageMap) Contains(pkgPath PackagePath) bool {
 
 type PackageStructureMap struct {
 	m map[PackageName]map[StructName]*Struct
+	// aliases が設定されていれば、structを参照する型別名の名前でも Get で取得できる。
+	aliases *PackageTypeAliasMap
 }
 
 func newPackageStructureMap() *PackageStructureMap {
@@ -40,6 +51,28 @@ func newPackageStructureMap() *PackageStructureMap {
 }
 
 func (p *PackageStructureMap) Get(pkgName PackageName, structName StructName) (s *Struct, ok bool) {
+	if s, ok := p.get(pkgName, structName); ok {
+		return s, true
+	}
+	if p.aliases == nil {
+		return nil, false
+	}
+	alias, ok := p.aliases.Get(pkgName, TypeAliasName(structName))
+	if !ok {
+		return nil, false
+	}
+	tn, ok := alias.targetTypeName()
+	if !ok {
+		return nil, false
+	}
+	s, ok = p.get(PackageName(tn.Pkg().Name()), StructName(tn.Name()))
+	if !ok || s.PackageSummary().Path() != PackagePath(tn.Pkg().Path()) {
+		return nil, false
+	}
+	return s, true
+}
+
+func (p *PackageStructureMap) get(pkgName PackageName, structName StructName) (s *Struct, ok bool) {
 	structMap, ok := p.m[pkgName]
 	if !ok {
 		return nil, false
@@ -101,8 +134,23 @@ func (p *PackageStructureMap) put(s *Struct) {
 	p.m[pkgName][s.Name()] = s
 }
 
+func (p *PackageStructureMap) remove(pkgPath PackagePath) {
+	for pkgName, m := range p.m {
+		for name, v := range m {
+			if v.PackageSummary().Path() == pkgPath {
+				delete(m, name)
+			}
+		}
+		if len(m) == 0 {
+			delete(p.m, pkgName)
+		}
+	}
+}
+
 type PackageInterfaceMap struct {
 	m map[PackageName]map[InterfaceName]*Interface
+	// aliases が設定されていれば、interfaceを参照する型別名の名前でも Get で取得できる。
+	aliases *PackageTypeAliasMap
 }
 
 func newPackageInterfaceMap() *PackageInterfaceMap {
@@ -110,6 +158,28 @@ func newPackageInterfaceMap() *PackageInterfaceMap {
 }
 
 func (p *PackageInterfaceMap) Get(pkgName PackageName, interfaceName InterfaceName) (iface *Interface, ok bool) {
+	if iface, ok := p.get(pkgName, interfaceName); ok {
+		return iface, true
+	}
+	if p.aliases == nil {
+		return nil, false
+	}
+	alias, ok := p.aliases.Get(pkgName, TypeAliasName(interfaceName))
+	if !ok {
+		return nil, false
+	}
+	tn, ok := alias.targetTypeName()
+	if !ok {
+		return nil, false
+	}
+	iface, ok = p.get(PackageName(tn.Pkg().Name()), InterfaceName(tn.Name()))
+	if !ok || iface.PackageSummary().Path() != PackagePath(tn.Pkg().Path()) {
+		return nil, false
+	}
+	return iface, true
+}
+
+func (p *PackageInterfaceMap) get(pkgName PackageName, interfaceName InterfaceName) (iface *Interface, ok bool) {
 	interfaceMap, ok := p.m[pkgName]
 	if !ok {
 		return nil, false
@@ -172,6 +242,19 @@ func (p *PackageInterfaceMap) put(iface *Interface) {
 	p.m[pkgName][iface.Name()] = iface
 }
 
+func (p *PackageInterfaceMap) remove(pkgPath PackagePath) {
+	for pkgName, m := range p.m {
+		for name, v := range m {
+			if v.PackageSummary().Path() == pkgPath {
+				delete(m, name)
+			}
+		}
+		if len(m) == 0 {
+			delete(p.m, pkgName)
+		}
+	}
+}
+
 type PackageTypeAliasMap struct {
 	m map[PackageName]map[TypeAliasName]*TypeAlias
 }
@@ -243,6 +326,19 @@ func (p *PackageTypeAliasMap) put(al *TypeAlias) {
 	p.m[pkgName][al.Name()] = al
 }
 
+func (p *PackageTypeAliasMap) remove(pkgPath PackagePath) {
+	for pkgName, m := range p.m {
+		for name, v := range m {
+			if v.PackageSummary().Path() == pkgPath {
+				delete(m, name)
+			}
+		}
+		if len(m) == 0 {
+			delete(p.m, pkgName)
+		}
+	}
+}
+
 type PackageDefinedTypeMap struct {
 	m map[PackageName]map[DefinedTypeName]*DefinedType
 }
@@ -290,3 +386,16 @@ func (p *PackageDefinedTypeMap) put(definedType *DefinedType) {
 	}
 	p.m[pkgName][definedType.Name()] = definedType
 }
+
+func (p *PackageDefinedTypeMap) remove(pkgPath PackagePath) {
+	for pkgName, m := range p.m {
+		for name, v := range m {
+			if v.PackageSummary().Path() == pkgPath {
+				delete(m, name)
+			}
+		}
+		if len(m) == 0 {
+			delete(p.m, pkgName)
+		}
+	}
+}
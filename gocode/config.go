@@ -0,0 +1,254 @@
+package gocode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Config は、 .goanalyzer.yaml または .goanalyzer.json に記述する解析の設定を表す。
+	Config struct {
+		Load   LoadSettings   `yaml:"load" json:"load"`
+		Output OutputSettings `yaml:"output" json:"output"`
+		Checks CheckSettings  `yaml:"checks" json:"checks"`
+		// path は読み込んだ設定ファイルのパス。設定ファイルがなければ空となる。
+		path string
+		// root は相対パスの基準となるディレクトリ。設定ファイルのディレクトリ、またはモジュールのルート。
+		root string
+		// dir は LoadConfig に指定したディレクトリ。 Load.Directories が空の場合に解析する。
+		dir string
+	}
+
+	// LoadSettings は、 LoadOptions のうち設定ファイルで指定できる項目を表す。
+	// ディレクトリのパスは設定ファイルのディレクトリからの相対パスで指定する。
+	LoadSettings struct {
+		Directories        []string `yaml:"directories" json:"directories"`
+		IgnoredDirectories []string `yaml:"ignoredDirectories" json:"ignoredDirectories"`
+		// Recursive は省略した場合は真となる。
+		Recursive                *bool      `yaml:"recursive" json:"recursive"`
+		DirectoryFilter          PathFilter `yaml:"directoryFilter" json:"directoryFilter"`
+		PackageFilter            PathFilter `yaml:"packageFilter" json:"packageFilter"`
+		FileFilter               PathFilter `yaml:"fileFilter" json:"fileFilter"`
+		ExcludeGenerated         bool       `yaml:"excludeGenerated" json:"excludeGenerated"`
+		IncludeHiddenDirectories bool       `yaml:"includeHiddenDirectories" json:"includeHiddenDirectories"`
+		IncludeVendor            bool       `yaml:"includeVendor" json:"includeVendor"`
+		IncludeEmptyInterfaces   bool       `yaml:"includeEmptyInterfaces" json:"includeEmptyInterfaces"`
+		FailFast                 bool       `yaml:"failFast" json:"failFast"`
+	}
+
+	// OutputSettings は、コマンドごとの出力形式を表す。空であればコマンドの既定の形式となる。
+	OutputSettings struct {
+		// Check は、 check コマンドの検出結果の形式。 text 、 json 、 sarif のいずれか。
+		Check string `yaml:"check" json:"check"`
+		Graph string `yaml:"graph" json:"graph"`
+		// Metrics は、パッケージメトリクスの表の形式。
+		Metrics string `yaml:"metrics" json:"metrics"`
+		Docs    string `yaml:"docs" json:"docs"`
+	}
+
+	// CheckName は、 RunChecks で実行する検査の名前。
+	CheckName string
+
+	// CheckSettings は、実行する検査と検査ごとの設定を表す。
+	CheckSettings struct {
+		// Enabled は実行する検査の一覧。空であれば全ての検査を実行する。
+		Enabled  []CheckName              `yaml:"enabled" json:"enabled"`
+		DeadCode DeadCodeOptions          `yaml:"deadcode" json:"deadcode"`
+		NearMiss NearMissOptions          `yaml:"nearmiss" json:"nearmiss"`
+		Metrics  PackageMetricsThresholds `yaml:"metrics" json:"metrics"`
+	}
+)
+
+const (
+	// CheckDeadCode は、 Relations.DeadCode による使われていない要素の検出。
+	CheckDeadCode CheckName = "deadcode"
+	// CheckNearMiss は、 Relations.NearMisses によるinterfaceをほぼ実装しているstructの検出。
+	CheckNearMiss CheckName = "nearmiss"
+	// CheckMetrics は、パッケージメトリクスの閾値の検査。
+	CheckMetrics CheckName = "metrics"
+	// CheckCycles は、異なる型の間の循環依存の検出。自身のみを参照する型は含まない。
+	CheckCycles CheckName = "cycles"
+	// CheckUntyped は、 interface{} または any の使用箇所の検出。
+	CheckUntyped CheckName = "untyped"
+	// CheckSemver は、 CompareAPI による公開APIの変更。2つの Relations を比較するため RunChecks では実行しない。
+	CheckSemver CheckName = "semver"
+)
+
+// ConfigFileNames は、 LoadConfig が探す設定ファイルの名前を優先順に並べたもの。
+var ConfigFileNames = []string{".goanalyzer.yaml", ".goanalyzer.yml", ".goanalyzer.json"}
+
+// CheckNames は、全ての検査の名前を返す。
+func CheckNames() []CheckName {
+	return []CheckName{CheckDeadCode, CheckNearMiss, CheckMetrics, CheckCycles, CheckUntyped}
+}
+
+func (n CheckName) String() string {
+	return string(n)
+}
+
+// ParseCheckName は、検査の名前を CheckName に変換する。
+func ParseCheckName(s string) (CheckName, error) {
+	for _, n := range CheckNames() {
+		if n.String() == s {
+			return n, nil
+		}
+	}
+	return "", fmt.Errorf("unknown check: %q", s)
+}
+
+// LoadConfig は、 dir からモジュールのルート(go.mod のあるディレクトリ)まで親を辿って設定ファイルを探し、読み込む。
+// 設定ファイルが見つからなければ、既定の設定を返す。
+func LoadConfig(fs afero.Fs, dir string) (*Config, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root := ""
+	for current := abs; ; current = filepath.Dir(current) {
+		for _, name := range ConfigFileNames {
+			path := filepath.Join(current, name)
+			ok, err := afero.Exists(fs, path)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				c, err := ReadConfigFile(fs, path)
+				if err != nil {
+					return nil, err
+				}
+				c.dir = dir
+				return c, nil
+			}
+		}
+		if ok, _ := afero.Exists(fs, filepath.Join(current, "go.mod")); ok {
+			root = current
+			break
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	if root == "" {
+		root = abs
+	}
+	return &Config{root: root, dir: dir}, nil
+}
+
+// ReadConfigFile は、 path の設定ファイルを読み込む。拡張子が .json であればJSON、それ以外はYAMLとして解釈する。
+// 未知の項目や未知の検査の名前があればエラーを返す。
+func ReadConfigFile(fs afero.Fs, path string) (*Config, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if filepath.Ext(path) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(c)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		// 空のファイルは既定の設定として扱う。
+		if err = dec.Decode(c); errors.Is(err, io.EOF) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	c.path = abs
+	c.root = filepath.Dir(abs)
+	c.dir = c.root
+	return c, nil
+}
+
+func (c *Config) validate() error {
+	for _, n := range c.Checks.Enabled {
+		if _, err := ParseCheckName(n.String()); err != nil {
+			return err
+		}
+	}
+	if _, err := newLoadFilter(c.LoadOptions(afero.NewMemMapFs())); err != nil {
+		return err
+	}
+	if _, err := compileAllowlist(c.Checks.DeadCode.Allowlist); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Path は、読み込んだ設定ファイルの絶対パスを返す。設定ファイルがなければ空となる。
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Root は、設定の相対パスの基準となるディレクトリを返す。
+func (c *Config) Root() string {
+	return c.root
+}
+
+// LoadOptions は、設定から fs を解析する LoadOptions を作る。
+// Load.Directories が空であれば、 LoadConfig に指定したディレクトリを解析する。
+func (c *Config) LoadOptions(fs afero.Fs) *LoadOptions {
+	options := &LoadOptions{
+		FileSystem:               fs,
+		Recursive:                c.Load.Recursive == nil || *c.Load.Recursive,
+		DirectoryFilter:          c.Load.DirectoryFilter,
+		PackageFilter:            c.Load.PackageFilter,
+		FileFilter:               c.Load.FileFilter,
+		ExcludeGenerated:         c.Load.ExcludeGenerated,
+		IncludeHiddenDirectories: c.Load.IncludeHiddenDirectories,
+		IncludeVendor:            c.Load.IncludeVendor,
+		IncludeEmptyInterfaces:   c.Load.IncludeEmptyInterfaces,
+		FailFast:                 c.Load.FailFast,
+	}
+	for _, dir := range c.Load.Directories {
+		options.Directories = append(options.Directories, c.resolve(dir))
+	}
+	if len(options.Directories) == 0 && c.dir != "" {
+		options.Directories = []string{c.dir}
+	}
+	for _, dir := range c.Load.IgnoredDirectories {
+		// パターンとして照合し、相対パスは設定ファイルのディレクトリからのパスとしても除外する。
+		options.IgnoredDirectories = append(options.IgnoredDirectories, dir)
+		if !filepath.IsAbs(dir) && c.root != "" {
+			options.IgnoredDirectories = append(options.IgnoredDirectories, filepath.Join(c.root, dir))
+		}
+	}
+	return options
+}
+
+func (c *Config) resolve(path string) string {
+	if filepath.IsAbs(path) || c.root == "" {
+		return path
+	}
+	return filepath.Join(c.root, path)
+}
+
+// CheckEnabled は、 name の検査を実行する設定であれば真を返す。
+func (s *CheckSettings) CheckEnabled(name CheckName) bool {
+	if len(s.Enabled) == 0 {
+		return true
+	}
+	for _, n := range s.Enabled {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package gocode_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		".goanalyzer.yaml": `load:
+  ignoredDirectories: [generated]
+checks:
+  enabled: [deadcode, untyped]
+  deadcode:
+    allowlist: ["\\.keep$"]
+output:
+  check: json
+`,
+		"app/app.go": `package app
+
+type unused struct{}
+
+type keep struct{}
+
+type Box struct {
+	Value interface{}
+}
+`,
+		"generated/gen.go": `package generated
+
+type unusedGenerated struct{}
+`,
+	})
+
+	c, err := gocode.LoadConfig(afero.NewOsFs(), filepath.Join(dir, "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, ".goanalyzer.yaml"); c.Path() != want {
+		t.Errorf("Path() = %s, want %s", c.Path(), want)
+	}
+	if c.Output.Check != "json" {
+		t.Errorf("Output.Check = %q, want json", c.Output.Check)
+	}
+	options := c.LoadOptions(afero.NewOsFs())
+	if !options.Recursive {
+		t.Errorf("LoadOptions().Recursive = false, want true by default")
+	}
+
+	// Load.Directories を省略した場合は LoadConfig に指定したディレクトリを解析する。
+	c, err = gocode.LoadConfig(afero.NewOsFs(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := gocode.LoadRelations(c.LoadOptions(afero.NewOsFs()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := r.RunChecks(&c.Checks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Check().String()+" "+f.Symbol())
+	}
+	want := []string{
+		"deadcode example.com/testmodule/app.unused",
+		"untyped example.com/testmodule/app.Box",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("RunChecks() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, f := range findings {
+		if f.Position().Filename != filepath.Join(dir, "app", "app.go") {
+			t.Errorf("%s: Position().Filename = %s, want app/app.go", f.Symbol(), f.Position().Filename)
+		}
+	}
+}
+
+func TestLoadConfig_Default(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"a/a.go": "package a\n",
+	})
+	c, err := gocode.LoadConfig(afero.NewOsFs(), filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Path() != "" {
+		t.Errorf("Path() = %s, want empty without a configuration file", c.Path())
+	}
+	if c.Root() != dir {
+		t.Errorf("Root() = %s, want the module root %s", c.Root(), dir)
+	}
+	for _, name := range gocode.CheckNames() {
+		if !c.Checks.CheckEnabled(name) {
+			t.Errorf("CheckEnabled(%s) = false, want true by default", name)
+		}
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "json",
+			file:    ".goanalyzer.json",
+			content: `{"load": {"directories": ["cmd"], "recursive": false}, "checks": {"nearmiss": {"maxDifferences": 2}}}`,
+		},
+		{name: "empty yaml", file: ".goanalyzer.yaml"},
+		{name: "unknown field", file: ".goanalyzer.yaml", content: "load:\n  directory: [cmd]\n", wantErr: "field directory not found"},
+		{name: "unknown json field", file: ".goanalyzer.json", content: `{"output": {"format": "json"}}`, wantErr: `unknown field "format"`},
+		{name: "unknown check", file: ".goanalyzer.yaml", content: "checks:\n  enabled: [layering]\n", wantErr: `unknown check: "layering"`},
+		{name: "invalid pattern", file: ".goanalyzer.yaml", content: "load:\n  fileFilter:\n    exclude: ['re:(']\n", wantErr: "invalid pattern"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			path := filepath.Join("/repo", test.file)
+			if err := afero.WriteFile(fs, path, []byte(test.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			c, err := gocode.ReadConfigFile(fs, path)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("ReadConfigFile() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.name != "json" {
+				return
+			}
+			options := c.LoadOptions(fs)
+			if options.Recursive {
+				t.Errorf("LoadOptions().Recursive = true, want false")
+			}
+			if len(options.Directories) != 1 || options.Directories[0] != filepath.Join("/repo", "cmd") {
+				t.Errorf("LoadOptions().Directories = %v, want [/repo/cmd]", options.Directories)
+			}
+			if c.Checks.NearMiss.MaxDifferences != 2 {
+				t.Errorf("Checks.NearMiss.MaxDifferences = %d, want 2", c.Checks.NearMiss.MaxDifferences)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package gocode
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+)
+
+type (
+	// DeadCodeKind は、使われていない要素の種類を表す。
+	DeadCodeKind string
+
+	// DeadCodeOptions は、使われていない要素の検出のオプション。
+	DeadCodeOptions struct {
+		// Exported が真であれば、公開された要素のうち定義されたパッケージの外から参照されていないものも検出する。
+		// 解析したパッケージがモジュール全体であることを前提とする。
+		Exported bool `yaml:"exported" json:"exported"`
+		// Allowlist は、検出の対象から除外する要素の正規表現の一覧。
+		// 正規表現は "パッケージパス.型名" または "パッケージパス.型名.メンバ名" の形式の名前と照合する。
+		// リフレクションを通じてのみ使われる要素の除外に用いる。
+		Allowlist []string `yaml:"allowlist" json:"allowlist"`
+	}
+
+	// DeadCodeFinding は、使われていない要素を表す。
+	DeadCodeFinding struct {
+		kind       DeadCodeKind
+		symbol     ReferenceTarget
+		pos        token.Pos
+		position   token.Position
+		pkgSummary *PackageSummary
+	}
+
+	// deadCodeDetector は、 Relations から使われていない要素を検出する。
+	deadCodeDetector struct {
+		relations  *Relations
+		options    *DeadCodeOptions
+		refs       *ReferenceIndex
+		allowlist  []*regexp.Regexp
+		findings   []*DeadCodeFinding
+		interfaces []*types.Interface
+	}
+)
+
+const (
+	DeadCodeStruct      DeadCodeKind = "struct"
+	DeadCodeField       DeadCodeKind = "field"
+	DeadCodeMethod      DeadCodeKind = "method"
+	DeadCodeDefinedType DeadCodeKind = "defined type"
+	DeadCodeTypeAlias   DeadCodeKind = "type alias"
+)
+
+func (k DeadCodeKind) String() string {
+	return string(k)
+}
+
+func (f *DeadCodeFinding) Kind() DeadCodeKind {
+	return f.kind
+}
+
+// Symbol は、使われていない要素の名前を返す。
+func (f *DeadCodeFinding) Symbol() ReferenceTarget {
+	return f.symbol
+}
+
+func (f *DeadCodeFinding) DefinedPos() token.Pos {
+	return f.pos
+}
+
+func (f *DeadCodeFinding) Position() token.Position {
+	return f.position
+}
+
+func (f *DeadCodeFinding) PackageSummary() *PackageSummary {
+	return f.pkgSummary
+}
+
+func (f *DeadCodeFinding) String() string {
+	return fmt.Sprintf("%s: unused %s %s", f.position, f.kind, f.symbol)
+}
+
+// DeadCode は、使われていない非公開のstruct、フィールド、メソッド、defined type、type aliasを検出する。
+//
+// レシーバとしての参照は使用とみなさない。
+// メソッドは参照されていなくても、使われているinterfaceのメソッドを実装していれば使われているとみなす。
+// 埋め込まれたフィールドは、昇格したフィールドやメソッドを通じて使われるため検出の対象としない。
+func (r *Relations) DeadCode(options *DeadCodeOptions) ([]*DeadCodeFinding, error) {
+	if options == nil {
+		options = &DeadCodeOptions{}
+	}
+	d := &deadCodeDetector{
+		relations: r,
+		options:   options,
+		refs:      r.References(),
+	}
+	allowlist, err := compileAllowlist(options.Allowlist)
+	if err != nil {
+		return nil, err
+	}
+	d.allowlist = allowlist
+	d.interfaces = d.externalInterfaces()
+
+	d.detect()
+
+	sort.Slice(d.findings, func(i, j int) bool {
+		pi, pj := d.findings[i].position, d.findings[j].position
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Offset != pj.Offset {
+			return pi.Offset < pj.Offset
+		}
+		return d.findings[i].symbol < d.findings[j].symbol
+	})
+	return d.findings, nil
+}
+
+func compileAllowlist(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist pattern %q: %w", pattern, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func (d *deadCodeDetector) detect() {
+	for _, s := range d.relations.Structs().StructAll() {
+		typeName := s.Name().String()
+		if d.isTarget(s.PackageSummary(), typeName) && !d.used(s.PackageSummary(), d.refs.StructReferences(s), token.IsExported(typeName)) {
+			d.add(DeadCodeStruct, newTypeReferenceTarget(s.PackageSummary().Path(), typeName), s.DefinedPos(), s.PackageSummary())
+		}
+		for _, f := range s.Fields() {
+			if f.Embedded() || f.Name() == "_" || !d.isTarget(s.PackageSummary(), f.Name().String()) {
+				continue
+			}
+			if !d.used(s.PackageSummary(), d.refs.FieldReferences(s, f), token.IsExported(typeName) && token.IsExported(f.Name().String())) {
+				d.add(DeadCodeField, newMemberReferenceTarget(s.PackageSummary().Path(), typeName, f.Name().String()), f.DefinedPos(), s.PackageSummary())
+			}
+		}
+		d.detectMethods(s.Type(), s.Methods(), d.usedInterfaces(s.ImplementInterfaces().InterfaceAll()))
+	}
+
+	for _, dt := range d.relations.DefinedTypes().DefinedTypeAll() {
+		if d.isTarget(dt.PackageSummary(), dt.Name().String()) && !d.used(dt.PackageSummary(), d.refs.DefinedTypeReferences(dt), token.IsExported(dt.Name().String())) {
+			d.add(DeadCodeDefinedType, newTypeReferenceTarget(dt.PackageSummary().Path(), dt.Name().String()), dt.DefinedPos(), dt.PackageSummary())
+		}
+		var implemented []*Interface
+		for _, i := range d.relations.Interfaces().InterfaceAll() {
+			if dt.Implements(i) {
+				implemented = append(implemented, i)
+			}
+		}
+		d.detectMethods(dt.Type(), dt.Methods(), d.usedInterfaces(implemented))
+	}
+
+	for _, a := range d.relations.TypeAliases().AliasAll() {
+		if d.isTarget(a.PackageSummary(), a.Name().String()) && !d.used(a.PackageSummary(), d.refs.TypeAliasReferences(a), token.IsExported(a.Name().String())) {
+			d.add(DeadCodeTypeAlias, newTypeReferenceTarget(a.PackageSummary().Path(), a.Name().String()), a.DefinedPos(), a.PackageSummary())
+		}
+	}
+}
+
+// detectMethods は、レシーバの型 receiver のメソッドのうち、参照されておらず、
+// 使われているinterfaceのメソッドも実装していないものを検出する。
+func (d *deadCodeDetector) detectMethods(receiver *Type, methods []*Function, interfaces []*types.Interface) {
+	for _, m := range methods {
+		if !d.isTarget(receiver.PackageSummary(), m.Name().String()) {
+			continue
+		}
+		exported := token.IsExported(receiver.TypeName().String()) && token.IsExported(m.Name().String())
+		if d.used(receiver.PackageSummary(), d.refs.MethodReferences(receiver, m), exported) {
+			continue
+		}
+		if d.satisfiesInterface(receiver, m, interfaces) {
+			continue
+		}
+		d.add(DeadCodeMethod, newMemberReferenceTarget(receiver.PackageSummary().Path(), receiver.TypeName().String(), m.Name().String()), m.DefinedPos(), receiver.PackageSummary())
+	}
+}
+
+// usedInterfaces は、 implemented のうち使われているinterfaceの types.Interface の一覧を返す。
+func (d *deadCodeDetector) usedInterfaces(implemented []*Interface) []*types.Interface {
+	var res []*types.Interface
+	for _, i := range implemented {
+		if len(d.refs.InterfaceReferences(i)) > 0 {
+			res = append(res, i.goInterface)
+			continue
+		}
+		for _, m := range i.Methods() {
+			if len(d.refs.InterfaceMethodReferences(i, m)) > 0 {
+				res = append(res, i.goInterface)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// satisfiesInterface は、メソッド m が interfaces または解析対象外のパッケージのinterfaceのメソッドを実装しているかを判定する。
+func (d *deadCodeDetector) satisfiesInterface(receiver *Type, m *Function, interfaces []*types.Interface) bool {
+	for _, candidates := range [][]*types.Interface{interfaces, d.interfaces} {
+		for _, i := range candidates {
+			if hasMethodNamed(i, m.Name().String()) && implementsBySignature(receiver.GoType(), i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// externalInterfaces は、解析したパッケージがimportしているパッケージで公開されたinterfaceと error の一覧を返す。
+// 解析対象外のinterfaceは使われているものとみなす。
+func (d *deadCodeDetector) externalInterfaces() []*types.Interface {
+	res := []*types.Interface{types.Universe.Lookup("error").Type().Underlying().(*types.Interface)}
+
+	var queue []*types.Package
+	for _, pkg := range d.relations.Packages().AsSlice() {
+		if pkg.GoPackage() != nil {
+			queue = append(queue, pkg.GoPackage().Imports()...)
+		}
+	}
+	visited := make(map[string]struct{})
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[pkg.Path()]; ok || d.relations.Packages().Contains(PackagePath(pkg.Path())) {
+			continue
+		}
+		visited[pkg.Path()] = struct{}{}
+
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !tn.Exported() {
+				continue
+			}
+			if i, ok := tn.Type().Underlying().(*types.Interface); ok && i.NumMethods() > 0 {
+				res = append(res, i)
+			}
+		}
+		queue = append(queue, pkg.Imports()...)
+	}
+	return res
+}
+
+// isTarget は、 name の要素が検出の対象であるかを判定する。
+func (d *deadCodeDetector) isTarget(ps *PackageSummary, name string) bool {
+	if !token.IsExported(name) {
+		return true
+	}
+	// main パッケージの公開された要素は、パッケージの外から参照されることがない。
+	return d.options.Exported && ps.Name() != "main"
+}
+
+// used は、 refs の中に使用とみなす参照があるかを判定する。
+// 公開された要素の検出では、 exported が真であれば定義されたパッケージの外からの参照のみを使用とみなす。
+// 非公開の型の公開されたフィールドとメソッドはパッケージの外から参照できないため、 exported を偽とする。
+func (d *deadCodeDetector) used(ps *PackageSummary, refs []*Reference, exported bool) bool {
+	for _, ref := range refs {
+		if ref.Kind() == ReferenceKindReceiver {
+			continue
+		}
+		if d.options.Exported && exported && ref.PackageSummary().Equal(ps) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (d *deadCodeDetector) add(kind DeadCodeKind, symbol ReferenceTarget, pos token.Pos, ps *PackageSummary) {
+	for _, re := range d.allowlist {
+		if re.MatchString(symbol.String()) {
+			return
+		}
+	}
+	d.findings = append(d.findings, &DeadCodeFinding{
+		kind:       kind,
+		symbol:     symbol,
+		pos:        pos,
+		position:   d.relations.Position(pos),
+		pkgSummary: ps,
+	})
+}
+
+func hasMethodNamed(i *types.Interface, name string) bool {
+	for j := 0; j < i.NumMethods(); j++ {
+		if i.Method(j).Name() == name {
+			return true
+		}
+	}
+	return false
+}
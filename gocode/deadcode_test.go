@@ -0,0 +1,162 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestRelations_DeadCode(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"store/store.go": `package store
+
+import "fmt"
+
+type reader interface {
+	read() string
+}
+
+type file struct {
+	name   string
+	unused int
+}
+
+func (f *file) read() string    { return f.name }
+func (f *file) String() string  { return fmt.Sprint(f.name) }
+func (f *file) close()          {}
+
+type orphan struct{}
+
+func (o orphan) helper() {}
+
+type level int
+
+type alias = int
+
+type Exported struct {
+	Used   string
+	Unused string
+}
+
+func Open(name string) string {
+	var r reader = &file{name: name}
+	return r.read()
+}
+
+func Point() (int, int) {
+	p := pair{1, 2}
+	return p.x, p.y
+}
+
+type pair struct {
+	x, y int
+}
+`,
+		"app/app.go": `package app
+
+import "example.com/testmodule/store"
+
+func Use() string {
+	e := store.Exported{Used: "x"}
+	return e.Used + store.Open("f")
+}
+`,
+	})
+
+	tests := []struct {
+		name    string
+		options *gocode.DeadCodeOptions
+		want    []string
+	}{
+		{
+			name:    "unexported",
+			options: &gocode.DeadCodeOptions{},
+			want: []string{
+				"field example.com/testmodule/store.file.unused",
+				"method example.com/testmodule/store.file.close",
+				"struct example.com/testmodule/store.orphan",
+				"method example.com/testmodule/store.orphan.helper",
+				"defined type example.com/testmodule/store.level",
+				"type alias example.com/testmodule/store.alias",
+			},
+		},
+		{
+			name:    "allowlist",
+			options: &gocode.DeadCodeOptions{Allowlist: []string{`\.orphan`, `\.(level|alias)$`}},
+			want: []string{
+				"field example.com/testmodule/store.file.unused",
+				"method example.com/testmodule/store.file.close",
+			},
+		},
+		{
+			name:    "exported",
+			options: &gocode.DeadCodeOptions{Exported: true, Allowlist: []string{`store\.(file|orphan|level|alias)`}},
+			want: []string{
+				"field example.com/testmodule/store.Exported.Unused",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			findings, err := r.DeadCode(test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Kind().String()+" "+f.Symbol().String())
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("DeadCode() = %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("DeadCode()[%d] = %s, want %s", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := r.DeadCode(&gocode.DeadCodeOptions{Allowlist: []string{"("}}); err == nil {
+		t.Error("DeadCode() expected error for invalid allowlist")
+	}
+}
+
+func TestRelations_DeadCode_ExportedMembersOfUnexportedType(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"p/p.go": `package p
+
+type foo struct {
+	Bar    string
+	Unused string
+}
+
+func (f foo) Hello() string { return f.Bar }
+
+func (f foo) Unused2() {}
+
+func Use() string {
+	f := foo{Bar: "x"}
+	return f.Bar + f.Hello()
+}
+`,
+	})
+
+	findings, err := r.DeadCode(&gocode.DeadCodeOptions{Exported: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Kind().String()+" "+f.Symbol().String())
+	}
+	// パッケージの外から参照できないため、同じパッケージからの参照を使用とみなす。
+	want := []string{
+		"field example.com/testmodule/p.foo.Unused",
+		"method example.com/testmodule/p.foo.Unused2",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("DeadCode() = %v, want %v", got, want)
+	}
+}
@@ -23,6 +23,8 @@ type (
 		pkgSummary *PackageSummary
 		// methods は定義されたメソッドの一覧。
 		methods *FunctionList
+		// includeEmptyInterfaces は LoadOptions.IncludeEmptyInterfaces の値。 Relations に登録する際に設定する。
+		includeEmptyInterfaces bool
 	}
 
 	// DefinedTypeList はdefined typeの一覧を表す。
@@ -35,7 +37,7 @@ func (dtn DefinedTypeName) String() string {
 	return string(dtn)
 }
 
-func newDefinedTypeIfObjectDefinedType(pkg packageIn, obj types.Object) (res *DefinedType, ok bool) {
+func newDefinedTypeIfObjectDefinedType(obj types.Object) (res *DefinedType, ok bool) {
 	tn, ok := obj.(*types.TypeName)
 	if !ok || tn.IsAlias() {
 		return &DefinedType{}, false
@@ -54,7 +56,7 @@ func newDefinedTypeIfObjectDefinedType(pkg packageIn, obj types.Object) (res *De
 		underlyingTyp: newType(pkgSummary, obj.Type().Underlying()),
 		pkgSummary:    pkgSummary,
 		name:          DefinedTypeName(obj.Name()),
-		methods:       newMethodsFromObject(pkg, obj),
+		methods:       newMethodsFromObject(obj),
 	}, true
 }
 
@@ -82,8 +84,16 @@ func (dt *DefinedType) Methods() []*Function {
 	return dt.methods.asSlice()
 }
 
+// Implements は、defined typeが i を実装しているかを返す。
+// メソッドを持たないinterfaceは LoadOptions.IncludeEmptyInterfaces が真の場合のみ実装しているとみなす。
 func (dt *DefinedType) Implements(i *Interface) bool {
-	return implements(dt.Type().GoType(), i.goInterface)
+	return implementsInterface(dt.Type().GoType(), i.goInterface, dt.includeEmptyInterfaces)
+}
+
+// Implementation は、defined typeによる i の実装を、interfaceのメソッドごとの対応と共に返す。
+// defined typeが i を実装していなければ ok は偽となる。
+func (dt *DefinedType) Implementation(i *Interface) (impl *Implementation, ok bool) {
+	return newImplementation(dt.pkgSummary, dt.typ.GoType(), i, dt.includeEmptyInterfaces)
 }
 
 func (dt *DefinedType) ImplementsGoTypes(i *types.Interface) bool {
@@ -93,7 +103,7 @@ func (dt *DefinedType) ImplementsGoTypes(i *types.Interface) bool {
 func newDefinedList(pkg packageIn) *DefinedTypeList {
 	var definedTypes []*DefinedType
 	for _, obj := range pkg.Typed() {
-		if a, ok := newDefinedTypeIfObjectDefinedType(pkg, obj); ok {
+		if a, ok := newDefinedTypeIfObjectDefinedType(obj); ok {
 			definedTypes = append(definedTypes, a)
 		}
 	}
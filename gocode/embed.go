@@ -12,6 +12,38 @@ type (
 	EmbedList struct {
 		embeds []*Embed
 	}
+
+	// FieldSelection は、structから選択可能なフィールドを、埋め込みによる昇格の情報と共に表す。
+	FieldSelection struct {
+		// field は選択されるフィールド。
+		field *Field
+		// path は field に辿り着くまでに経由する埋め込みフィールドの一覧。struct自身のフィールドであれば空となる。
+		path []*Field
+		// shadowed は、より浅い位置にある同名のフィールドによって隠されているかを表す。
+		shadowed bool
+		// ambiguous は、同じ深さに同名のフィールドがあるため選択できないかを表す。
+		ambiguous bool
+	}
+
+	// MethodSelection は、型のメソッドセットに含まれるメソッドを、埋め込みによる昇格の情報と共に表す。
+	MethodSelection struct {
+		// function は選択されるメソッド。
+		function *Function
+		// origin はメソッドが宣言されている型。
+		origin *Type
+		// path はメソッドに辿り着くまでに経由する埋め込みフィールドの一覧。型自身のメソッドであれば空となる。
+		path []*Field
+		// indirect は、メソッドの呼び出しにポインタの間接参照が必要かを表す。
+		indirect bool
+	}
+
+	// fieldSearchEntry は、埋め込みを辿ってフィールドを探索する際の探索対象を表す。
+	fieldSearchEntry struct {
+		typ  types.Type
+		path []*Field
+		// multiples は、同じ深さで同じ型に複数の経路で辿り着いたかを表す。その型のフィールドは全て曖昧となる。
+		multiples bool
+	}
 )
 
 func newEmbed(currentPkgSummary *PackageSummary, typ types.Type) *Embed {
@@ -31,6 +63,16 @@ func newEmbedListFromInterfaceType(currentPkgSummary *PackageSummary, interfaceT
 	return &EmbedList{embeds: embeds}
 }
 
+func newEmbedListFromStructType(currentPkgSummary *PackageSummary, structType *types.Struct) *EmbedList {
+	var embeds []*Embed
+	for i := 0; i < structType.NumFields(); i++ {
+		if f := structType.Field(i); f.Embedded() {
+			embeds = append(embeds, newEmbed(currentPkgSummary, f.Type()))
+		}
+	}
+	return &EmbedList{embeds: embeds}
+}
+
 func (el *EmbedList) asSlice() []*Embed {
 	var slice []*Embed
 	for i := range el.embeds {
@@ -38,3 +80,184 @@ func (el *EmbedList) asSlice() []*Embed {
 	}
 	return slice
 }
+
+func (fs *FieldSelection) Field() *Field {
+	return fs.field
+}
+
+// Path は、フィールドに辿り着くまでに経由する埋め込みフィールドの一覧を返す。
+func (fs *FieldSelection) Path() []*Field {
+	return append([]*Field{}, fs.path...)
+}
+
+// Depth は、埋め込みの深さを返す。struct自身のフィールドであれば0となる。
+func (fs *FieldSelection) Depth() int {
+	return len(fs.path)
+}
+
+// Promoted は、埋め込みによって昇格したフィールドであるかを返す。
+func (fs *FieldSelection) Promoted() bool {
+	return len(fs.path) > 0
+}
+
+// Shadowed は、より浅い位置にある同名のフィールドによって隠されているかを返す。
+func (fs *FieldSelection) Shadowed() bool {
+	return fs.shadowed
+}
+
+// Ambiguous は、同じ深さに同名のフィールドがあるため選択できないかを返す。
+func (fs *FieldSelection) Ambiguous() bool {
+	return fs.ambiguous
+}
+
+// Accessible は、structからセレクタでフィールドを選択できるかを返す。
+func (fs *FieldSelection) Accessible() bool {
+	return !fs.shadowed && !fs.ambiguous
+}
+
+func (ms *MethodSelection) Function() *Function {
+	return ms.function
+}
+
+// Origin は、メソッドが宣言されている型を返す。
+func (ms *MethodSelection) Origin() *Type {
+	return ms.origin
+}
+
+// Path は、メソッドに辿り着くまでに経由する埋め込みフィールドの一覧を返す。
+func (ms *MethodSelection) Path() []*Field {
+	return append([]*Field{}, ms.path...)
+}
+
+// Depth は、埋め込みの深さを返す。型自身のメソッドであれば0となる。
+func (ms *MethodSelection) Depth() int {
+	return len(ms.path)
+}
+
+// Promoted は、埋め込みによって昇格したメソッドであるかを返す。
+func (ms *MethodSelection) Promoted() bool {
+	return ms.function.Promoted()
+}
+
+// Indirect は、メソッドの呼び出しにポインタの間接参照が必要かを返す。
+func (ms *MethodSelection) Indirect() bool {
+	return ms.indirect
+}
+
+// newMethodSelections は、 typ のメソッドセットを返す。 pointer が真であれば *typ のメソッドセットを返す。
+func newMethodSelections(currentPkgSummary *PackageSummary, typ types.Type, pointer bool) []*MethodSelection {
+	if pointer {
+		typ = types.NewPointer(typ)
+	}
+	ms := types.NewMethodSet(typ)
+
+	var selections []*MethodSelection
+	for i := 0; i < ms.Len(); i++ {
+		sel := ms.At(i)
+		f, ok := sel.Obj().(*types.Func)
+		if !ok {
+			continue
+		}
+		fn, ok := newFunctionIfSignatureType(f)
+		if !ok {
+			continue
+		}
+		path := embeddedPath(typ, sel.Index())
+		fn.promoted = len(path) > 0
+		selections = append(selections, &MethodSelection{
+			function: fn,
+			origin:   newType(currentPkgSummary, derefType(f.Type().(*types.Signature).Recv().Type())),
+			path:     path,
+			indirect: sel.Indirect(),
+		})
+	}
+	return selections
+}
+
+// embeddedPath は、 types.Selection.Index の埋め込みフィールドのインデックスを Field の一覧に変換する。
+func embeddedPath(typ types.Type, index []int) []*Field {
+	var path []*Field
+	for _, i := range index[:len(index)-1] {
+		st, ok := derefType(typ).Underlying().(*types.Struct)
+		if !ok {
+			break
+		}
+		f := st.Field(i)
+		path = append(path, newField(f, st.Tag(i)))
+		typ = f.Type()
+	}
+	return path
+}
+
+// newFieldSelections は、 typ から選択可能な全てのフィールドを、埋め込みを幅優先で辿って返す。
+func newFieldSelections(typ types.Type) []*FieldSelection {
+	var selections []*FieldSelection
+	// shallower は、より浅い深さで見つかったフィールド名の集合。
+	shallower := make(map[string]struct{})
+	visited := make(map[types.Type]struct{})
+	current := []*fieldSearchEntry{{typ: typ}}
+
+	for len(current) > 0 {
+		var next []*fieldSearchEntry
+		var depthSelections []*FieldSelection
+		count := make(map[string]int)
+
+		current = consolidateFieldSearchEntries(current, visited)
+		for _, entry := range current {
+			visited[derefType(entry.typ)] = struct{}{}
+		}
+		for _, entry := range current {
+			st, ok := derefType(entry.typ).Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			for i := 0; i < st.NumFields(); i++ {
+				f := newField(st.Field(i), st.Tag(i))
+				depthSelections = append(depthSelections, &FieldSelection{field: f, path: entry.path})
+				count[f.Name().String()]++
+				if entry.multiples {
+					count[f.Name().String()]++
+				}
+				if f.Embedded() {
+					path := append(append([]*Field{}, entry.path...), f)
+					next = append(next, &fieldSearchEntry{typ: st.Field(i).Type(), path: path, multiples: entry.multiples})
+				}
+			}
+		}
+
+		for _, sel := range depthSelections {
+			name := sel.field.Name().String()
+			if _, ok := shallower[name]; ok {
+				sel.shadowed = true
+			} else if count[name] > 1 {
+				sel.ambiguous = true
+			}
+		}
+		for name := range count {
+			shallower[name] = struct{}{}
+		}
+		selections = append(selections, depthSelections...)
+		current = next
+	}
+	return selections
+}
+
+// consolidateFieldSearchEntries は、より浅い深さで探索済みの型を除き、同じ深さで重複する型を1つにまとめる。
+// go/types の lookupFieldOrMethod と同様に、重複した型は multiples を真とする。
+func consolidateFieldSearchEntries(entries []*fieldSearchEntry, visited map[types.Type]struct{}) []*fieldSearchEntry {
+	var res []*fieldSearchEntry
+	index := make(map[types.Type]int)
+	for _, entry := range entries {
+		t := derefType(entry.typ)
+		if _, ok := visited[t]; ok {
+			continue
+		}
+		if i, ok := index[t]; ok {
+			res[i].multiples = true
+			continue
+		}
+		index[t] = len(res)
+		res = append(res, entry)
+	}
+	return res
+}
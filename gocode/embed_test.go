@@ -0,0 +1,224 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+const embedTestSource = `package config
+
+type Named interface {
+	Name() string
+}
+
+type Base struct {
+	ID   string
+	Host string
+}
+
+func (b Base) Name() string { return b.ID }
+func (b *Base) Reset()     { b.ID = "" }
+
+type Network struct {
+	Host string
+	Port int
+}
+
+func (n *Network) Addr() string { return n.Host }
+
+type Server struct {
+	Base
+	*Network
+	Named
+	Host string
+}
+
+type Config struct {
+	Server
+	Debug bool
+}
+`
+
+func TestStruct_Embeds(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"config/config.go": embedTestSource})
+	s, ok := r.Structs().Get("config", "Server")
+	if !ok {
+		t.Fatal("Server not found")
+	}
+
+	var got []string
+	for _, e := range s.Embeds() {
+		got = append(got, e.Type().TypeName().String())
+	}
+	want := []string{"Base", "*Network", "Named"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Embeds() = %v, want %v", got, want)
+	}
+}
+
+func TestStruct_MethodSet(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"config/config.go": embedTestSource})
+	s, ok := r.Structs().Get("config", "Config")
+	if !ok {
+		t.Fatal("Config not found")
+	}
+
+	tests := []struct {
+		name    string
+		pointer bool
+		want    []string
+	}{
+		{
+			name:    "value",
+			pointer: false,
+			// Name は Base と Named の同じ深さに存在するため昇格しない。
+			want: []string{
+				"Addr Network Server.Network",
+			},
+		},
+		{
+			name:    "pointer",
+			pointer: true,
+			want: []string{
+				"Addr Network Server.Network",
+				"Reset Base Server.Base",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, m := range s.MethodSet(test.pointer) {
+				got = append(got, m.Function().Name().String()+" "+m.Origin().TypeName().String()+" "+pathString(m.Path()))
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("MethodSet(%v) = %v, want %v", test.pointer, got, test.want)
+			}
+		})
+	}
+}
+
+func TestStruct_AllFields(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"config/config.go": embedTestSource})
+	s, ok := r.Structs().Get("config", "Config")
+	if !ok {
+		t.Fatal("Config not found")
+	}
+
+	want := []string{
+		"Server 0  accessible",
+		"Debug 0  accessible",
+		"Base 1 Server accessible",
+		"Network 1 Server accessible",
+		"Named 1 Server accessible",
+		"Host 1 Server accessible",
+		"ID 2 Server.Base accessible",
+		"Host 2 Server.Base shadowed",
+		"Host 2 Server.Network shadowed",
+		"Port 2 Server.Network accessible",
+	}
+	var got []string
+	for _, f := range s.AllFields() {
+		state := "accessible"
+		switch {
+		case f.Shadowed():
+			state = "shadowed"
+		case f.Ambiguous():
+			state = "ambiguous"
+		}
+		got = append(got, strings.Join([]string{f.Field().Name().String(), string(rune('0' + f.Depth())), pathString(f.Path()), state}, " "))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("AllFields() = \n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestStruct_AllFields_Diamond(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"diamond/diamond.go": `package diamond
+
+type Base struct {
+	ID int
+}
+
+type Left struct {
+	Base
+}
+
+type Right struct {
+	Base
+}
+
+type Both struct {
+	Left
+	Right
+}
+`})
+	s, ok := r.Structs().Get("diamond", "Both")
+	if !ok {
+		t.Fatal("Both not found")
+	}
+
+	// Base には Left と Right の2つの経路で同じ深さに辿り着くため、 ID は選択できない。
+	want := []string{
+		"Left 0  accessible",
+		"Right 0  accessible",
+		"Base 1 Left ambiguous",
+		"Base 1 Right ambiguous",
+		"ID 2 Left.Base ambiguous",
+	}
+	var got []string
+	for _, f := range s.AllFields() {
+		state := "accessible"
+		switch {
+		case f.Shadowed():
+			state = "shadowed"
+		case f.Ambiguous():
+			state = "ambiguous"
+		}
+		got = append(got, strings.Join([]string{f.Field().Name().String(), string(rune('0' + f.Depth())), pathString(f.Path()), state}, " "))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("AllFields() = \n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func pathString(path []*gocode.Field) string {
+	var names []string
+	for _, f := range path {
+		names = append(names, f.Name().String())
+	}
+	return strings.Join(names, ".")
+}
+
+func TestStruct_MethodsIncludingPromoted(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"config/config.go": embedTestSource})
+	s, ok := r.Structs().Get("config", "Network")
+	if !ok {
+		t.Fatal("Network not found")
+	}
+	if got := len(s.MethodsIncludingPromoted()); got != 1 || s.MethodsIncludingPromoted()[0].Promoted() {
+		t.Errorf("Network.MethodsIncludingPromoted() = %d methods, want 1 declared method", got)
+	}
+
+	s, ok = r.Structs().Get("config", "Server")
+	if !ok {
+		t.Fatal("Server not found")
+	}
+	if len(s.Methods()) != 0 {
+		t.Errorf("Server.Methods() = %d methods, want 0", len(s.Methods()))
+	}
+	var got []string
+	for _, m := range s.MethodsIncludingPromoted() {
+		if !m.Promoted() {
+			t.Errorf("%s.Promoted() = false, want true", m.Name())
+		}
+		got = append(got, m.Name().String())
+	}
+	// Name は Base と Named の同じ深さに存在するため含まれない。
+	want := []string{"Addr", "Reset"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Server.MethodsIncludingPromoted() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package gocode
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+type (
+	// EmptyInterfaceUsageKind は、 interface{} が使われている箇所の種類を表す。
+	EmptyInterfaceUsageKind string
+
+	// EmptyInterfaceUsage は、型に interface{} または any を含むフィールド、パラメータ、戻り値を表す。
+	EmptyInterfaceUsage struct {
+		kind EmptyInterfaceUsageKind
+		// owner はフィールドを持つstruct、またはパラメータや戻り値を持つ関数のパッケージパス付きの名前。
+		owner string
+		// name はフィールド、パラメータ、戻り値の名前。名前のないパラメータや戻り値の場合は空となる。
+		name string
+		// index はパラメータや戻り値の位置。フィールドの場合はフィールドの位置。
+		index      int
+		typ        *Type
+		pos        token.Pos
+		position   token.Position
+		pkgSummary *PackageSummary
+	}
+
+	// emptyInterfaceCollector は、 Relations から interface{} の使用箇所を収集する。
+	emptyInterfaceCollector struct {
+		relations *Relations
+		usages    []*EmptyInterfaceUsage
+	}
+)
+
+const (
+	EmptyInterfaceField       EmptyInterfaceUsageKind = "field"
+	EmptyInterfaceParameter   EmptyInterfaceUsageKind = "parameter"
+	EmptyInterfaceReturnValue EmptyInterfaceUsageKind = "return value"
+)
+
+func (k EmptyInterfaceUsageKind) String() string {
+	return string(k)
+}
+
+func (u *EmptyInterfaceUsage) Kind() EmptyInterfaceUsageKind {
+	return u.kind
+}
+
+// Owner は、フィールドを持つstruct、またはパラメータや戻り値を持つ関数のパッケージパス付きの名前を返す。
+func (u *EmptyInterfaceUsage) Owner() string {
+	return u.owner
+}
+
+// Name は、フィールド、パラメータ、戻り値の名前を返す。名前のないパラメータや戻り値の場合は空となる。
+func (u *EmptyInterfaceUsage) Name() string {
+	return u.name
+}
+
+// Index は、パラメータや戻り値の位置を返す。フィールドの場合はフィールドの位置を返す。
+func (u *EmptyInterfaceUsage) Index() int {
+	return u.index
+}
+
+func (u *EmptyInterfaceUsage) Type() *Type {
+	return u.typ
+}
+
+func (u *EmptyInterfaceUsage) DefinedPos() token.Pos {
+	return u.pos
+}
+
+func (u *EmptyInterfaceUsage) Position() token.Position {
+	return u.position
+}
+
+func (u *EmptyInterfaceUsage) PackageSummary() *PackageSummary {
+	return u.pkgSummary
+}
+
+func (u *EmptyInterfaceUsage) String() string {
+	name := u.name
+	if name == "" {
+		name = fmt.Sprintf("#%d", u.index)
+	}
+	return fmt.Sprintf("%s: %s %s of %s has type %s", u.position, u.kind, name, u.owner, types.TypeString(u.typ.GoType(), nil))
+}
+
+// EmptyInterfaceUsages は、型に interface{} または any を含むstructのフィールド、
+// 関数とメソッドのパラメータ、戻り値の一覧を位置順に返す。
+//
+// []interface{} や map[string]interface{} のように要素の型として含む場合も対象とする。
+// interface{} を基底型とするdefined typeも対象とするが、 error のようなメソッドを持つinterfaceは対象としない。
+func (r *Relations) EmptyInterfaceUsages() []*EmptyInterfaceUsage {
+	c := &emptyInterfaceCollector{relations: r}
+
+	for _, pkg := range r.Packages().AsSlice() {
+		pkgPath := pkg.Summary().Path().String()
+		detail := pkg.Detail()
+		for _, s := range detail.Structs() {
+			owner := pkgPath + "." + s.Name().String()
+			for i, f := range s.Fields() {
+				if containsEmptyInterface(f.Type().GoType()) {
+					c.add(EmptyInterfaceField, owner, f.Name().String(), i, f.Type(), f.DefinedPos(), pkg.Summary())
+				}
+			}
+			c.collectFunctions(owner+".", s.Methods(), pkg.Summary())
+		}
+		for _, i := range detail.Interfaces() {
+			c.collectFunctions(pkgPath+"."+i.Name().String()+".", i.ExplicitMethods(), pkg.Summary())
+		}
+		for _, dt := range detail.DefinedTypes() {
+			c.collectFunctions(pkgPath+"."+dt.Name().String()+".", dt.Methods(), pkg.Summary())
+		}
+		c.collectFunctions(pkgPath+".", detail.Functions(), pkg.Summary())
+	}
+
+	sort.Slice(c.usages, func(i, j int) bool {
+		pi, pj := c.usages[i].position, c.usages[j].position
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Offset != pj.Offset {
+			return pi.Offset < pj.Offset
+		}
+		return c.usages[i].index < c.usages[j].index
+	})
+	return c.usages
+}
+
+func (c *emptyInterfaceCollector) collectFunctions(prefix string, functions []*Function, ps *PackageSummary) {
+	for _, fn := range functions {
+		owner := prefix + fn.Name().String()
+		for i, p := range fn.Parameters() {
+			if containsEmptyInterface(p.Type().GoType()) {
+				c.add(EmptyInterfaceParameter, owner, p.Name(), i, p.Type(), p.DefinedPos(), ps)
+			}
+		}
+		for i, rv := range fn.ReturnValues() {
+			if containsEmptyInterface(rv.Type().GoType()) {
+				c.add(EmptyInterfaceReturnValue, owner, rv.Name(), i, rv.Type(), rv.DefinedPos(), ps)
+			}
+		}
+	}
+}
+
+func (c *emptyInterfaceCollector) add(kind EmptyInterfaceUsageKind, owner, name string, index int, typ *Type, pos token.Pos, ps *PackageSummary) {
+	if name == "_" {
+		name = ""
+	}
+	c.usages = append(c.usages, &EmptyInterfaceUsage{
+		kind:       kind,
+		owner:      owner,
+		name:       name,
+		index:      index,
+		typ:        typ,
+		pos:        pos,
+		position:   c.relations.Position(pos),
+		pkgSummary: ps,
+	})
+}
+
+// containsEmptyInterface は、 typ が interface{} であるか、要素の型として interface{} を含むかを判定する。
+// 名前付きの型は基底型が interface{} の場合のみ対象とし、structのフィールドなどは辿らない。
+// any のようなtype aliasは参照先の型で判定する。
+func containsEmptyInterface(typ types.Type) bool {
+	switch t := unalias(typ).(type) {
+	case *types.Named:
+		i, ok := t.Underlying().(*types.Interface)
+		return ok && i.NumMethods() == 0
+	case *types.Interface:
+		return t.NumMethods() == 0
+	case *types.Pointer:
+		return containsEmptyInterface(t.Elem())
+	case *types.Slice:
+		return containsEmptyInterface(t.Elem())
+	case *types.Array:
+		return containsEmptyInterface(t.Elem())
+	case *types.Chan:
+		return containsEmptyInterface(t.Elem())
+	case *types.Map:
+		return containsEmptyInterface(t.Key()) || containsEmptyInterface(t.Elem())
+	default:
+		return false
+	}
+}
@@ -0,0 +1,155 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+const emptyInterfaceTestSource = `package api
+
+type Empty interface{}
+
+type Stringer interface {
+	String() string
+}
+
+type Request struct {
+	ID      string
+	Body    interface{}
+	Headers map[string]interface{}
+	Err     error
+}
+
+func (r *Request) Decode(v interface{}) error { return nil }
+
+type Level int
+
+type Handler interface {
+	Handle(Empty) ([]interface{}, error)
+}
+
+func Do(name string, args ...interface{}) (result interface{}, err error) { return nil, nil }
+`
+
+func TestRelations_EmptyInterfaceUsages(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"api/api.go": emptyInterfaceTestSource})
+
+	var got []string
+	for _, u := range r.EmptyInterfaceUsages() {
+		got = append(got, strings.Join([]string{u.Kind().String(), u.Owner(), u.Name(), u.Type().TypeName().String()}, " "))
+	}
+	want := []string{
+		"field example.com/testmodule/api.Request Body interface{}",
+		"field example.com/testmodule/api.Request Headers map[string]interface{}",
+		"parameter example.com/testmodule/api.Request.Decode v interface{}",
+		"parameter example.com/testmodule/api.Handler.Handle  Empty",
+		"return value example.com/testmodule/api.Handler.Handle  []interface{}",
+		"parameter example.com/testmodule/api.Do args []interface{}",
+		"return value example.com/testmodule/api.Do result interface{}",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("EmptyInterfaceUsages() = \n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRelations_EmptyInterfaceUsages_Any(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"go.mod": "module example.com/testmodule\n\ngo 1.18\n",
+		"api/api.go": `package api
+
+type Request struct {
+	Body    any
+	Headers map[string]any
+}
+
+func Do(v any) {}
+`,
+	})
+
+	var got []string
+	for _, u := range r.EmptyInterfaceUsages() {
+		got = append(got, strings.Join([]string{u.Kind().String(), u.Owner(), u.Name()}, " "))
+	}
+	want := []string{
+		"field example.com/testmodule/api.Request Body",
+		"field example.com/testmodule/api.Request Headers",
+		"parameter example.com/testmodule/api.Do v",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("EmptyInterfaceUsages() = \n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLoadOptions_IncludeEmptyInterfaces(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{"api/api.go": emptyInterfaceTestSource})
+
+	tests := []struct {
+		name    string
+		include bool
+		want    string
+	}{
+		{name: "excluded", include: false, want: ""},
+		{name: "included", include: true, want: "api.Empty"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := gocode.LoadRelations(&gocode.LoadOptions{
+				FileSystem:             afero.NewOsFs(),
+				Directories:            []string{dir},
+				Recursive:              true,
+				IncludeEmptyInterfaces: test.include,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.IncludesEmptyInterfaces() != test.include {
+				t.Errorf("IncludesEmptyInterfaces() = %v, want %v", r.IncludesEmptyInterfaces(), test.include)
+			}
+			s, ok := r.Structs().Get("api", "Request")
+			if !ok {
+				t.Fatal("Request not found")
+			}
+			var got []string
+			for _, name := range s.ImplementInterfaces().PackageInterfaceNames() {
+				got = append(got, name.String())
+			}
+			if strings.Join(got, ",") != test.want {
+				t.Errorf("ImplementInterfaces() = %v, want %s", got, test.want)
+			}
+
+			// 実装関係を判定する全てのメソッドが同じ規則に従う。
+			empty, ok := r.Interfaces().Get("api", "Empty")
+			if !ok {
+				t.Fatal("Empty not found")
+			}
+			if s.Implements(empty) != test.include {
+				t.Errorf("Struct.Implements(Empty) = %v, want %v", s.Implements(empty), test.include)
+			}
+			var impls []string
+			for _, impl := range s.Implementations() {
+				impls = append(impls, impl.Interface().Name().String())
+			}
+			if strings.Join(impls, ",") != strings.TrimPrefix(test.want, "api.") {
+				t.Errorf("Implementations() = %v, want %s", impls, test.want)
+			}
+			level, ok := r.DefinedTypes().Get("api", "Level")
+			if !ok {
+				t.Fatal("Level not found")
+			}
+			if _, ok := level.Implementation(empty); level.Implements(empty) != test.include || ok != test.include {
+				t.Errorf("DefinedType.Implements(Empty) = %v, Implementation ok = %v, want %v", level.Implements(empty), ok, test.include)
+			}
+
+			res, err := r.Query(`structs where implements "api.Empty"`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := res.Len() > 0, test.include; got != want {
+				t.Errorf("Query(implements api.Empty) matched = %v, want %v", got, want)
+			}
+		})
+	}
+}
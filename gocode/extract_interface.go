@@ -0,0 +1,233 @@
+package gocode
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+type (
+	// ExtractInterfaceOptions は、structのメソッドからのinterfaceの抽出のオプション。
+	ExtractInterfaceOptions struct {
+		// InterfaceName は抽出するinterfaceの名前。空の場合はstruct名に "Interface" を付与した名前となる。
+		InterfaceName string
+		// PackageName は出力するファイルのパッケージ名。空の場合はstructのパッケージ名となる。
+		PackageName string
+		// PackagePath は出力するファイルのパッケージパス。空の場合はstructのパッケージパスとなる。
+		PackagePath string
+	}
+
+	// ExtractedInterface は、structのメソッドから抽出したinterfaceを表す。
+	ExtractedInterface struct {
+		name        string
+		structure   *Struct
+		methods     []*Function
+		declaration string
+		source      []byte
+		satisfiers  []*Struct
+	}
+)
+
+// ExtractInterface は、 s のメソッドのうち methodNames のメソッドを持つinterfaceの宣言を生成する。
+// methodNames が空の場合は、公開された全てのメソッドを対象とする。埋め込みによって昇格したメソッドも指定できる。
+//
+// 合わせて、解析したstructのうち s 以外で抽出したinterfaceを満たすものを探す。
+func (r *Relations) ExtractInterface(s *Struct, methodNames []FunctionName, options *ExtractInterfaceOptions) (*ExtractedInterface, error) {
+	if options == nil {
+		options = &ExtractInterfaceOptions{}
+	}
+	opts := *options
+	if opts.InterfaceName == "" {
+		opts.InterfaceName = s.Name().String() + "Interface"
+	}
+	if opts.PackageName == "" {
+		opts.PackageName = s.PackageSummary().Name().String()
+	}
+	if opts.PackagePath == "" {
+		opts.PackagePath = s.PackageSummary().Path().String()
+	}
+
+	methods, err := selectMethods(s, methodNames)
+	if err != nil {
+		return nil, err
+	}
+	samePackage := opts.PackagePath == s.PackageSummary().Path().String()
+	for _, m := range methods {
+		if !samePackage && !m.Exported() {
+			return nil, fmt.Errorf("method %s of %s is unexported and cannot be part of an interface in another package", m.Name(), s.PackageStructName())
+		}
+	}
+
+	imports := newCodegenImports(opts.PackagePath)
+	if pkg, ok := r.Packages().Get(s.PackageSummary().Path()); ok {
+		imports.useAliases(pkg.Detail().Imports())
+	}
+
+	var decl bytes.Buffer
+	fmt.Fprintf(&decl, "// %s is an interface extracted from %s.%s.\n", opts.InterfaceName, s.PackageSummary().Path(), s.Name())
+	fmt.Fprintf(&decl, "type %s interface {\n", opts.InterfaceName)
+	for _, m := range methods {
+		fmt.Fprintf(&decl, "\t%s%s\n", m.Name(), methodSpec(imports, m.signature()))
+	}
+	decl.WriteString("}\n")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", opts.PackageName)
+	imports.writeTo(&buf)
+	buf.Write(decl.Bytes())
+	source, err := formatSource(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	declaration, err := formatSource(append([]byte("package p\n\n"), decl.Bytes()...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExtractedInterface{
+		name:        opts.InterfaceName,
+		structure:   s,
+		methods:     methods,
+		declaration: strings.TrimPrefix(string(declaration), "package p\n\n"),
+		source:      source,
+		satisfiers:  r.satisfiers(s, methods),
+	}, nil
+}
+
+// Name は、抽出したinterfaceの名前を返す。
+func (ei *ExtractedInterface) Name() string {
+	return ei.name
+}
+
+// Struct は、抽出元のstructを返す。
+func (ei *ExtractedInterface) Struct() *Struct {
+	return ei.structure
+}
+
+// Methods は、抽出したinterfaceのメソッドの一覧を返す。
+func (ei *ExtractedInterface) Methods() []*Function {
+	return append([]*Function{}, ei.methods...)
+}
+
+// Declaration は、抽出したinterfaceの型宣言を返す。
+func (ei *ExtractedInterface) Declaration() string {
+	return ei.declaration
+}
+
+// Source は、パッケージ宣言とimportを含む、抽出したinterfaceを宣言するファイルの内容を返す。
+func (ei *ExtractedInterface) Source() []byte {
+	return append([]byte{}, ei.source...)
+}
+
+// Satisfiers は、抽出元のstruct以外で、抽出したinterfaceを満たす解析したstructの一覧を返す。
+func (ei *ExtractedInterface) Satisfiers() []*Struct {
+	return append([]*Struct{}, ei.satisfiers...)
+}
+
+// selectMethods は、 s のメソッドから names のメソッドを names の順に選択する。
+// names が空の場合は、公開された全てのメソッドを名前順に返す。
+func selectMethods(s *Struct, names []FunctionName) ([]*Function, error) {
+	all := make(map[FunctionName]*Function)
+	for _, m := range s.MethodsIncludingPromoted() {
+		all[m.Name()] = m
+	}
+
+	if len(names) == 0 {
+		var methods []*Function
+		for _, m := range all {
+			if m.Exported() {
+				methods = append(methods, m)
+			}
+		}
+		if len(methods) == 0 {
+			return nil, fmt.Errorf("%s has no exported methods", s.PackageStructName())
+		}
+		sort.Slice(methods, func(i, j int) bool {
+			return methods[i].Name() < methods[j].Name()
+		})
+		return methods, nil
+	}
+
+	var methods []*Function
+	seen := make(map[FunctionName]struct{})
+	for _, name := range names {
+		m, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("%s has no method %s", s.PackageStructName(), name)
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+// methodSpec は、interfaceのメソッドの宣言に使う、パラメータ名を含むシグネチャの文字列を返す。
+func methodSpec(imports *codegenImports, sig *types.Signature) string {
+	params := make([]string, 0, sig.Params().Len())
+	for i := 0; i < sig.Params().Len(); i++ {
+		p := sig.Params().At(i)
+		typ := imports.typeString(p.Type())
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			typ = "..." + imports.typeString(p.Type().(*types.Slice).Elem())
+		}
+		params = append(params, strings.TrimSpace(p.Name()+" "+typ))
+	}
+
+	results := make([]string, 0, sig.Results().Len())
+	named := false
+	for i := 0; i < sig.Results().Len(); i++ {
+		rv := sig.Results().At(i)
+		named = named || rv.Name() != ""
+		results = append(results, strings.TrimSpace(rv.Name()+" "+imports.typeString(rv.Type())))
+	}
+
+	spec := "(" + strings.Join(params, ", ") + ")"
+	switch {
+	case len(results) == 0:
+		return spec
+	case len(results) == 1 && !named:
+		return spec + " " + results[0]
+	default:
+		return spec + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// satisfiers は、 s 以外で methods の全てのメソッドを同じシグネチャで持つstructの一覧を返す。
+// 非公開のメソッドを含む場合は、 s と同じパッケージのstructのみを対象とする。
+func (r *Relations) satisfiers(s *Struct, methods []*Function) []*Struct {
+	samePackageOnly := false
+	funcs := make([]*types.Func, 0, len(methods))
+	for _, m := range methods {
+		samePackageOnly = samePackageOnly || !m.Exported()
+		sig := m.signature()
+		funcs = append(funcs, types.NewFunc(token.NoPos, m.goFunc.Pkg(), m.Name().String(), types.NewSignature(nil, sig.Params(), sig.Results(), sig.Variadic())))
+	}
+	iface := types.NewInterfaceType(funcs, nil).Complete()
+
+	var res []*Struct
+	for _, other := range r.Structs().StructAll() {
+		if other == s || other.Type().GoType() == s.Type().GoType() {
+			continue
+		}
+		if samePackageOnly && !other.PackageSummary().Equal(s.PackageSummary()) {
+			continue
+		}
+		if implementsBySignature(other.Type().GoType(), iface) {
+			res = append(res, other)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		pi, pj := res[i].PackageSummary().Path(), res[j].PackageSummary().Path()
+		if pi != pj {
+			return pi < pj
+		}
+		return res[i].Name() < res[j].Name()
+	})
+	return res
+}
@@ -0,0 +1,106 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestRelations_ExtractInterface(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"client/client.go": `package client
+
+import (
+	stdctx "context"
+	"net/http"
+)
+
+type base struct{}
+
+func (b *base) Close() error { return nil }
+
+type Client struct {
+	base
+	http *http.Client
+}
+
+func (c *Client) Get(ctx stdctx.Context, key string) (body []byte, err error) { return nil, nil }
+func (c *Client) Put(ctx stdctx.Context, key string, values ...string) error { return nil }
+func (c *Client) do(req *http.Request) {}
+
+type Memory struct{}
+
+func (m Memory) Get(ctx stdctx.Context, key string) ([]byte, error) { return nil, nil }
+func (m Memory) Close() error                                        { return nil }
+`,
+		"cache/cache.go": `package cache
+
+import "context"
+
+type Cache struct{}
+
+func (c *Cache) Get(ctx context.Context, k string) ([]byte, error) { return nil, nil }
+func (c *Cache) Close() error                                    { return nil }
+
+type Partial struct{}
+
+func (p *Partial) Get(ctx context.Context, k string) (string, error) { return "", nil }
+func (p *Partial) Close() error                                     { return nil }
+`,
+	})
+	s, ok := r.Structs().Get("client", "Client")
+	if !ok {
+		t.Fatal("Client not found")
+	}
+
+	ei, err := r.ExtractInterface(s, []gocode.FunctionName{"Get", "Close"}, &gocode.ExtractInterfaceOptions{InterfaceName: "Getter"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDecl := `// Getter is an interface extracted from example.com/testmodule/client.Client.
+type Getter interface {
+	Get(ctx stdctx.Context, key string) (body []byte, err error)
+	Close() error
+}
+`
+	if ei.Declaration() != wantDecl {
+		t.Errorf("Declaration() = \n%s\nwant\n%s", ei.Declaration(), wantDecl)
+	}
+	if src := string(ei.Source()); !strings.HasPrefix(src, "package client\n\nimport (\n\tstdctx \"context\"\n)\n") {
+		t.Errorf("Source() = \n%s", src)
+	}
+
+	var got []string
+	for _, s := range ei.Satisfiers() {
+		got = append(got, s.PackageStructName().String())
+	}
+	if want := "cache.Cache,client.Memory"; strings.Join(got, ",") != want {
+		t.Errorf("Satisfiers() = %v, want %s", got, want)
+	}
+
+	t.Run("all exported methods", func(t *testing.T) {
+		ei, err := r.ExtractInterface(s, nil, &gocode.ExtractInterfaceOptions{PackageName: "iface", PackagePath: "example.com/testmodule/iface"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ei.Name() != "ClientInterface" {
+			t.Errorf("Name() = %s, want ClientInterface", ei.Name())
+		}
+		if !strings.Contains(ei.Declaration(), "\tPut(ctx stdctx.Context, key string, values ...string) error\n") {
+			t.Errorf("Declaration() = \n%s", ei.Declaration())
+		}
+		if strings.Contains(ei.Declaration(), "do(") {
+			t.Errorf("Declaration() contains unexported method:\n%s", ei.Declaration())
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := r.ExtractInterface(s, []gocode.FunctionName{"Missing"}, nil); err == nil {
+			t.Error("ExtractInterface() expected error for unknown method")
+		}
+		if _, err := r.ExtractInterface(s, []gocode.FunctionName{"do"}, &gocode.ExtractInterfaceOptions{PackagePath: "example.com/testmodule/other"}); err == nil {
+			t.Error("ExtractInterface() expected error for unexported method in another package")
+		}
+	})
+}
@@ -1,8 +1,10 @@
 package gocode
 
 import (
+	"fmt"
 	"go/token"
 	"go/types"
+	"reflect"
 )
 
 type (
@@ -16,12 +18,19 @@ type (
 		name       FieldName
 		pkgSummary *PackageSummary
 		typ        *Type
+		tag        reflect.StructTag
 	}
 
 	// FieldList はstructのフィールドのリストを表す。
 	FieldList struct {
 		fields []*Field
 	}
+
+	// StructField はフィールドと、そのフィールドを持つstructの組を表す。
+	StructField struct {
+		structure *Struct
+		field     *Field
+	}
 )
 
 func (fn FieldName) String() string {
@@ -31,8 +40,7 @@ func (fn FieldName) String() string {
 func newFieldListFromStructType(structType *types.Struct) *FieldList {
 	var fields []*Field
 	for i := 0; i < structType.NumFields(); i++ {
-		f := structType.Field(i)
-		fields = append(fields, newField(f))
+		fields = append(fields, newField(structType.Field(i), structType.Tag(i)))
 	}
 	return &FieldList{fields: fields}
 }
@@ -45,7 +53,7 @@ func (fl *FieldList) asSlice() []*Field {
 	return slice
 }
 
-func newField(field *types.Var) *Field {
+func newField(field *types.Var, tag string) *Field {
 	pkgSummary := newPackageSummaryFromGoTypes(field.Pkg())
 
 	return &Field{
@@ -54,6 +62,7 @@ func newField(field *types.Var) *Field {
 		pkgSummary: pkgSummary,
 		name:       FieldName(field.Name()),
 		typ:        newType(pkgSummary, field.Type()),
+		tag:        reflect.StructTag(tag),
 	}
 }
 
@@ -80,3 +89,24 @@ func (f *Field) Name() FieldName {
 func (f *Field) Type() *Type {
 	return f.typ
 }
+
+// Tag は、フィールドのタグを返す。タグがなければ空となる。
+func (f *Field) Tag() reflect.StructTag {
+	return f.tag
+}
+
+func newStructField(s *Struct, f *Field) *StructField {
+	return &StructField{structure: s, field: f}
+}
+
+func (sf *StructField) Struct() *Struct {
+	return sf.structure
+}
+
+func (sf *StructField) Field() *Field {
+	return sf.field
+}
+
+func (sf *StructField) String() string {
+	return fmt.Sprintf("%s.%s.%s", sf.structure.PackageSummary().Path(), sf.structure.Name(), sf.field.Name())
+}
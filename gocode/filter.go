@@ -0,0 +1,341 @@
+package gocode
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type (
+	// PathFilter は、パターンによって解析の対象を絞り込む。
+	//
+	// パターンは "re:" で始まれば正規表現、そうでなければ "**" で任意の数の階層に一致するglobとして扱う。
+	// "/" を含まないglobはパスの最後の要素のみと照合する。
+	PathFilter struct {
+		// Include が空でなければ、いずれかのパターンに一致するものだけを対象とする。
+		Include []string `yaml:"include" json:"include"`
+		// Exclude のいずれかのパターンに一致するものは対象から除く。 Include より優先する。
+		Exclude []string `yaml:"exclude" json:"exclude"`
+	}
+
+	// pathPattern は、コンパイルしたパターン。 re と glob のいずれか一方のみが設定される。
+	pathPattern struct {
+		re   *regexp.Regexp
+		glob []string
+	}
+
+	pathMatcher struct {
+		include []*pathPattern
+		exclude []*pathPattern
+	}
+
+	// loadFilter は、 LoadOptions のディレクトリ、パッケージ、ファイルの絞り込みの条件をまとめたもの。
+	loadFilter struct {
+		// roots は LoadOptions.Directories の絶対パス。 rootPaths は指定されたままのパス。
+		roots                    []string
+		rootPaths                []string
+		recursive                bool
+		ignoredDirectories       map[string]struct{}
+		directories              *pathMatcher
+		packages                 *pathMatcher
+		files                    *pathMatcher
+		excludeGenerated         bool
+		includeHiddenDirectories bool
+		includeVendor            bool
+	}
+
+	// packageInFilteredFiles は、除外したファイルで宣言されたものを含まない packageIn 。
+	packageInFilteredFiles struct {
+		packageIn
+		fset     *token.FileSet
+		excluded map[string]struct{}
+	}
+)
+
+// generatedCodePattern は、生成されたファイルであることを示すコメントに一致する。
+var generatedCodePattern = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+func compilePathPattern(pattern string) (*pathPattern, error) {
+	if strings.HasPrefix(pattern, "re:") {
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, "re:"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return &pathPattern{re: re}, nil
+	}
+	glob := strings.Split(strings.Trim(filepath.ToSlash(pattern), "/"), "/")
+	for _, segment := range glob {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return &pathPattern{glob: glob}, nil
+}
+
+// match は、 "/" 区切りのパス name がパターンに一致すれば真を返す。
+func (p *pathPattern) match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	if len(p.glob) == 1 {
+		ok, _ := path.Match(p.glob[0], path.Base(name))
+		return ok
+	}
+	return matchSegments(p.glob, strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+func compilePathPatterns(patterns []string) ([]*pathPattern, error) {
+	var res []*pathPattern
+	for _, pattern := range patterns {
+		p, err := compilePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+func newPathMatcher(filter PathFilter, exclude ...string) (*pathMatcher, error) {
+	include, err := compilePathPatterns(filter.Include)
+	if err != nil {
+		return nil, err
+	}
+	excludePatterns, err := compilePathPatterns(append(append([]string{}, filter.Exclude...), exclude...))
+	if err != nil {
+		return nil, err
+	}
+	return &pathMatcher{include: include, exclude: excludePatterns}, nil
+}
+
+func matchAny(patterns []*pathPattern, name string) bool {
+	for _, p := range patterns {
+		if p.match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *pathMatcher) included(name string) bool {
+	return len(m.include) == 0 || matchAny(m.include, name)
+}
+
+func (m *pathMatcher) excluded(name string) bool {
+	return matchAny(m.exclude, name)
+}
+
+func (m *pathMatcher) allows(name string) bool {
+	return m.included(name) && !m.excluded(name)
+}
+
+func newLoadFilter(options *LoadOptions) (*loadFilter, error) {
+	f := &loadFilter{
+		ignoredDirectories:       make(map[string]struct{}),
+		excludeGenerated:         options.ExcludeGenerated,
+		includeHiddenDirectories: options.IncludeHiddenDirectories,
+		includeVendor:            options.IncludeVendor,
+		recursive:                options.Recursive,
+	}
+	for _, dir := range options.Directories {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		f.roots = append(f.roots, abs)
+		f.rootPaths = append(f.rootPaths, dir)
+	}
+
+	// IgnoredDirectories は、パスそのものに一致するか、パターンとして一致するディレクトリを除く。
+	var ignoredPatterns []string
+	for _, dir := range options.IgnoredDirectories {
+		f.ignoredDirectories[dir] = struct{}{}
+		ignoredPatterns = append(ignoredPatterns, dir)
+	}
+	var err error
+	if f.directories, err = newPathMatcher(options.DirectoryFilter, ignoredPatterns...); err != nil {
+		return nil, err
+	}
+	if f.packages, err = newPathMatcher(options.PackageFilter); err != nil {
+		return nil, err
+	}
+	if f.files, err = newPathMatcher(options.FileFilter); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// relativePath は、 name を解析対象のディレクトリからの "/" 区切りの相対パスに変換する。
+// いずれの解析対象のディレクトリにも含まれなければ、 name を "/" 区切りにしたものを返す。
+func (f *loadFilter) relativePath(name string) string {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return filepath.ToSlash(name)
+	}
+	for _, root := range f.roots {
+		if rel, err := filepath.Rel(root, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(name)
+}
+
+// skipDirectory は、 dir とその下のディレクトリを走査しない場合に真を返す。 root は走査を始めたディレクトリ。
+func (f *loadFilter) skipDirectory(root, dir string) bool {
+	if dir != root {
+		name := filepath.Base(dir)
+		if !f.includeHiddenDirectories && strings.HasPrefix(name, ".") {
+			return true
+		}
+		if !f.includeVendor && name == "vendor" {
+			return true
+		}
+	}
+	if _, ok := f.ignoredDirectories[dir]; ok {
+		return true
+	}
+	return f.directories.excluded(f.relativePath(dir))
+}
+
+// includeDirectory は、 dir のパッケージを解析する場合に真を返す。
+func (f *loadFilter) includeDirectory(dir string) bool {
+	return f.directories.included(f.relativePath(dir))
+}
+
+// walksDirectory は、 dir が LoadOptions.Directories の走査で解析されるディレクトリであれば真を返す。
+// 走査と同じく、解析対象のディレクトリから dir までの各ディレクトリに skipDirectory を適用する。
+// 解析対象のディレクトリがない場合は、全てのディレクトリを解析するものとする。
+func (f *loadFilter) walksDirectory(dir string) bool {
+	if len(f.roots) == 0 {
+		return true
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for i, root := range f.roots {
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if rel != "." && !f.recursive {
+			continue
+		}
+		path := f.rootPaths[i]
+		skipped := f.skipDirectory(f.rootPaths[i], path)
+		if rel != "." {
+			for _, name := range strings.Split(rel, string(filepath.Separator)) {
+				if skipped {
+					break
+				}
+				path = filepath.Join(path, name)
+				skipped = f.skipDirectory(f.rootPaths[i], path)
+			}
+		}
+		if !skipped && f.includeDirectory(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *loadFilter) includePackage(pkgPath string) bool {
+	return f.packages.allows(pkgPath)
+}
+
+// excludedFiles は、 pkg のファイルのうち解析の対象から除くファイル名の一覧を返す。
+func (f *loadFilter) excludedFiles(fset *token.FileSet, files []*ast.File) map[string]struct{} {
+	excluded := make(map[string]struct{})
+	for _, file := range files {
+		tf := fset.File(file.Pos())
+		if tf == nil {
+			continue
+		}
+		if !f.files.allows(f.relativePath(tf.Name())) || (f.excludeGenerated && isGeneratedFile(file)) {
+			excluded[tf.Name()] = struct{}{}
+		}
+	}
+	return excluded
+}
+
+// isGeneratedFile は、package句より前に "// Code generated ... DO NOT EDIT." のコメントがあれば真を返す。
+func isGeneratedFile(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			return false
+		}
+		for _, c := range group.List {
+			if generatedCodePattern.MatchString(c.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newPackageInFilteredFiles(pkg packageIn, fset *token.FileSet, excluded map[string]struct{}) packageIn {
+	if len(excluded) == 0 {
+		return pkg
+	}
+	return &packageInFilteredFiles{packageIn: pkg, fset: fset, excluded: excluded}
+}
+
+// keep は、 pos が除いたファイルの位置でなければ真を返す。 //line によるファイル名の変更は適用しない。
+func (p *packageInFilteredFiles) keep(pos token.Pos) bool {
+	_, ok := p.excluded[p.fset.PositionFor(pos, false).Filename]
+	return !ok
+}
+
+func (p *packageInFilteredFiles) filterObjects(objs []types.Object) []types.Object {
+	var res []types.Object
+	for _, obj := range objs {
+		if obj == nil || p.keep(obj.Pos()) {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+func (p *packageInFilteredFiles) Defs() []types.Object {
+	return p.filterObjects(p.packageIn.Defs())
+}
+
+func (p *packageInFilteredFiles) Typed() []types.Object {
+	return p.filterObjects(p.packageIn.Typed())
+}
+
+func (p *packageInFilteredFiles) Files() []*ast.File {
+	var files []*ast.File
+	for _, file := range p.packageIn.Files() {
+		if p.keep(file.Pos()) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
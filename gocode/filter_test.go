@@ -0,0 +1,84 @@
+package gocode_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+func TestLoadRelations_Filter(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"a/a.go": `package a
+
+type A struct{}
+`,
+		// goyacc のように //line で元のファイルの位置を指す生成されたファイル。
+		"a/gen.go": `// Code generated by testgen. DO NOT EDIT.
+
+package a
+
+//line parser.y:10
+type Gen struct{}
+`,
+		"internal/x/x.go": `package x
+
+type X struct{}
+`,
+		"mocks/mock.go": `package mocks
+
+type Mock struct{}
+`,
+		".hidden/h.go": `package hidden
+
+type H struct{}
+`,
+	})
+
+	tests := []struct {
+		name    string
+		options gocode.LoadOptions
+		want    string
+	}{
+		{name: "default", want: "a.A a.Gen mocks.Mock x.X"},
+		{name: "hidden directories", options: gocode.LoadOptions{IncludeHiddenDirectories: true}, want: "a.A a.Gen hidden.H mocks.Mock x.X"},
+		{name: "generated files", options: gocode.LoadOptions{ExcludeGenerated: true}, want: "a.A mocks.Mock x.X"},
+		{name: "directory glob", options: gocode.LoadOptions{DirectoryFilter: gocode.PathFilter{Exclude: []string{"internal/**"}}}, want: "a.A a.Gen mocks.Mock"},
+		{name: "directory include", options: gocode.LoadOptions{DirectoryFilter: gocode.PathFilter{Include: []string{"**/x"}}}, want: "x.X"},
+		{name: "ignored directory regexp", options: gocode.LoadOptions{IgnoredDirectories: []string{"re:^mocks$"}}, want: "a.A a.Gen x.X"},
+		{name: "package regexp", options: gocode.LoadOptions{PackageFilter: gocode.PathFilter{Include: []string{"re:/internal/"}}}, want: "x.X"},
+		{name: "file glob", options: gocode.LoadOptions{FileFilter: gocode.PathFilter{Exclude: []string{"gen.go"}}}, want: "a.A mocks.Mock x.X"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := test.options
+			options.FileSystem = afero.NewOsFs()
+			options.Directories = []string{dir}
+			options.Recursive = true
+			options.FailFast = true
+			r, err := gocode.LoadRelations(&options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, s := range r.Structs().StructAll() {
+				names = append(names, s.PackageStructName().String())
+			}
+			sort.Strings(names)
+			if got := strings.Join(names, " "); got != test.want {
+				t.Errorf("structs = %s, want %s", got, test.want)
+			}
+		})
+	}
+
+	_, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:      afero.NewOsFs(),
+		Directories:     []string{dir},
+		DirectoryFilter: gocode.PathFilter{Exclude: []string{"re:("}},
+	})
+	if err == nil {
+		t.Error("LoadRelations() with an invalid pattern returns no error")
+	}
+}
@@ -1,7 +1,10 @@
 package gocode
 
 import (
+	"fmt"
+	"go/token"
 	"go/types"
+	"strings"
 )
 
 type (
@@ -13,8 +16,9 @@ type (
 
 	// Parameter は、関数のパラメータを表す。
 	Parameter struct {
-		name string
-		typ  *Type
+		definedPos token.Pos
+		name       string
+		typ        *Type
 	}
 
 	// Parameters は、関数のパラメータリスト。
@@ -25,8 +29,9 @@ type (
 
 	// ReturnValue は、関数の戻り値を表す。
 	ReturnValue struct {
-		name string
-		typ  *Type
+		definedPos token.Pos
+		name       string
+		typ        *Type
 	}
 
 	// ReturnValues は、関数の戻り値リスト。
@@ -34,15 +39,25 @@ type (
 
 	// Function は、関数を表す。
 	Function struct {
+		definedPos   token.Pos
+		goFunc       *types.Func
 		name         FunctionName
 		parameters   Parameters
 		returnValues ReturnValues
+		// promoted は埋め込みによって昇格したメソッドであるかを表す。
+		promoted bool
 	}
 
 	// FunctionList は、関数リストを表す。
 	FunctionList struct {
 		functions []*Function
 	}
+
+	// TypeMethod は、メソッドとそのレシーバの型の組を表す。
+	TypeMethod struct {
+		receiver *Type
+		method   *Function
+	}
 )
 
 func (f FunctionName) String() string {
@@ -51,11 +66,16 @@ func (f FunctionName) String() string {
 
 func newParameter(obj types.Object) *Parameter {
 	return &Parameter{
-		name: obj.Name(),
-		typ:  newType(newPackageSummaryFromGoTypes(obj.Pkg()), obj.Type()),
+		definedPos: obj.Pos(),
+		name:       obj.Name(),
+		typ:        newType(newPackageSummaryFromGoTypes(obj.Pkg()), obj.Type()),
 	}
 }
 
+func (p Parameter) DefinedPos() token.Pos {
+	return p.definedPos
+}
+
 func (p Parameter) Name() string {
 	return p.name
 }
@@ -66,11 +86,16 @@ func (p Parameter) Type() *Type {
 
 func newReturnValue(obj types.Object) *ReturnValue {
 	return &ReturnValue{
-		name: obj.Name(),
-		typ:  newType(newPackageSummaryFromGoTypes(obj.Pkg()), obj.Type()),
+		definedPos: obj.Pos(),
+		name:       obj.Name(),
+		typ:        newType(newPackageSummaryFromGoTypes(obj.Pkg()), obj.Type()),
 	}
 }
 
+func (rv ReturnValue) DefinedPos() token.Pos {
+	return rv.definedPos
+}
+
 func (rv ReturnValue) Name() string {
 	return rv.name
 }
@@ -81,7 +106,9 @@ func (rv ReturnValue) Type() *Type {
 
 func newFunctionIfSignatureType(f *types.Func) (*Function, bool) {
 	fn := &Function{
-		name: FunctionName(f.Name()),
+		definedPos: f.Pos(),
+		goFunc:     f,
+		name:       FunctionName(f.Name()),
 	}
 
 	s, ok := f.Type().(*types.Signature)
@@ -119,10 +146,42 @@ func newReturnValues(signature *types.Signature) ReturnValues {
 	return res
 }
 
+func (f *Function) DefinedPos() token.Pos {
+	return f.definedPos
+}
+
 func (f *Function) Name() FunctionName {
 	return f.name
 }
 
+func (f *Function) Exported() bool {
+	return f.goFunc.Exported()
+}
+
+// PointerReceiver は、メソッドのレシーバがポインタであるかを返す。
+func (f *Function) PointerReceiver() bool {
+	recv := f.signature().Recv()
+	if recv == nil {
+		return false
+	}
+	_, ok := recv.Type().(*types.Pointer)
+	return ok
+}
+
+// Promoted は、 Struct.MethodsIncludingPromoted などで埋め込みによって昇格したメソッドとして取得したかを返す。
+func (f *Function) Promoted() bool {
+	return f.promoted
+}
+
+// Signature は、パッケージ名で型を修飾した "Name(int, io.Reader) error" の形式のシグネチャを返す。
+func (f *Function) Signature() string {
+	return displaySignature(f)
+}
+
+func (f *Function) signature() *types.Signature {
+	return f.goFunc.Type().(*types.Signature)
+}
+
 func (f *Function) Parameters() Parameters {
 	return append(Parameters{}, f.parameters...)
 }
@@ -150,16 +209,76 @@ func (fl *FunctionList) asSlice() []*Function {
 	return slice
 }
 
-func newMethodsFromObject(pkg packageIn, obj types.Object) *FunctionList {
+// newFunctionListFromPackage は、パッケージに宣言された関数の一覧を返す。メソッドは含まない。
+func newFunctionListFromPackage(pkg packageIn) *FunctionList {
+	var functions []*Function
+	for _, obj := range pkg.Typed() {
+		if f, ok := obj.(*types.Func); ok {
+			if fn, ok := newFunctionIfSignatureType(f); ok {
+				functions = append(functions, fn)
+			}
+		}
+	}
+	return &FunctionList{functions: functions}
+}
+
+// newMethodsFromObject は、 obj の型に宣言されたメソッドの一覧を返す。
+// パッケージスコープ以外で宣言された型や、依存パッケージの型にも対応する。
+func newMethodsFromObject(obj types.Object) *FunctionList {
 	var methods []*Function
-	namedObj := pkg.Scope().Lookup(obj.Name())
-	if named, ok := namedObj.Type().(*types.Named); ok && named != nil {
+	if named, ok := obj.Type().(*types.Named); ok {
 		for i := 0; i < named.NumMethods(); i++ {
-			funcObj := named.Method(i)
-			if fn, ok := newFunctionIfSignatureType(funcObj); ok {
+			if fn, ok := newFunctionIfSignatureType(named.Method(i)); ok {
 				methods = append(methods, fn)
 			}
 		}
 	}
 	return &FunctionList{functions: methods}
 }
+
+// signatureKey は、パラメータ名を除いたパッケージパス付きのシグネチャ文字列を返す。
+// 別々にロードされた型同士でも文字列の比較でシグネチャの同一性を判定できる。
+func signatureKey(sig *types.Signature) string {
+	params := make([]string, 0, sig.Params().Len())
+	for i := 0; i < sig.Params().Len(); i++ {
+		t := sig.Params().At(i).Type()
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			if s, ok := t.(*types.Slice); ok {
+				params = append(params, "..."+types.TypeString(s.Elem(), nil))
+				continue
+			}
+		}
+		params = append(params, types.TypeString(t, nil))
+	}
+
+	results := make([]string, 0, sig.Results().Len())
+	for i := 0; i < sig.Results().Len(); i++ {
+		results = append(results, types.TypeString(sig.Results().At(i).Type(), nil))
+	}
+
+	switch len(results) {
+	case 0:
+		return fmt.Sprintf("func(%s)", strings.Join(params, ", "))
+	case 1:
+		return fmt.Sprintf("func(%s) %s", strings.Join(params, ", "), results[0])
+	default:
+		return fmt.Sprintf("func(%s) (%s)", strings.Join(params, ", "), strings.Join(results, ", "))
+	}
+}
+
+func newTypeMethod(receiver *Type, method *Function) *TypeMethod {
+	return &TypeMethod{receiver: receiver, method: method}
+}
+
+// Receiver は、メソッドのレシーバの型を返す。ポインタレシーバであってもポインタを外した型を返す。
+func (tm *TypeMethod) Receiver() *Type {
+	return tm.receiver
+}
+
+func (tm *TypeMethod) Method() *Function {
+	return tm.method
+}
+
+func (tm *TypeMethod) String() string {
+	return fmt.Sprintf("%s.%s.%s", tm.receiver.PackageSummary().Path(), tm.receiver.TypeName(), tm.method.Name())
+}
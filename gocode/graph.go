@@ -1,6 +1,9 @@
 package gocode
 
 import (
+	"bufio"
+	"fmt"
+	"io"
 	"sort"
 	"strings"
 )
@@ -71,3 +74,43 @@ func (pg *PackageGraph) SortedImportPackagePaths(pkgPath PackagePath) []*Package
 	})
 	return summaries
 }
+
+// WriteDOT は、パッケージの依存グラフをGraphvizのDOT形式で書き出す。
+func (pg *PackageGraph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph packages {")
+	for _, path := range pg.SortedPackagePaths() {
+		fmt.Fprintf(bw, "\t%q;\n", path)
+	}
+	for _, path := range pg.SortedPackagePaths() {
+		for _, ps := range pg.SortedImportPackagePaths(path) {
+			fmt.Fprintf(bw, "\t%q -> %q;\n", path, ps.Path())
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid は、パッケージの依存グラフをMermaidのflowchart形式で書き出す。
+func (pg *PackageGraph) WriteMermaid(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "graph LR")
+	ids := make(map[PackagePath]string)
+	nodeID := func(path PackagePath) string {
+		if id, ok := ids[path]; ok {
+			return id
+		}
+		ids[path] = fmt.Sprintf("p%d", len(ids))
+		fmt.Fprintf(bw, "\t%s[%q]\n", ids[path], path)
+		return ids[path]
+	}
+	for _, path := range pg.SortedPackagePaths() {
+		nodeID(path)
+	}
+	for _, path := range pg.SortedPackagePaths() {
+		for _, ps := range pg.SortedImportPackagePaths(path) {
+			fmt.Fprintf(bw, "\t%s --> %s\n", nodeID(path), nodeID(ps.Path()))
+		}
+	}
+	return bw.Flush()
+}
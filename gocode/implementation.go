@@ -0,0 +1,134 @@
+package gocode
+
+import "go/types"
+
+type (
+	// ReceiverKind は、interfaceのメソッドを実装するメソッドのレシーバの種類を表す。
+	ReceiverKind string
+
+	// Implementation は、型がinterfaceを実装していることを、interfaceのメソッドごとの対応と共に表す。
+	Implementation struct {
+		typ     *Type
+		iface   *Interface
+		methods []*MethodImplementation
+		// requiresPointer は、型の値ではなくポインタでなければinterfaceを実装しないかを表す。
+		requiresPointer bool
+	}
+
+	// MethodImplementation は、interfaceのメソッドとそれを実装する具体的なメソッドの対応を表す。
+	MethodImplementation struct {
+		// interfaceMethod は実装されるinterfaceのメソッド。
+		interfaceMethod *Function
+		// selection は実装するメソッドと、埋め込みによって昇格している場合の経路。
+		selection *MethodSelection
+	}
+)
+
+const (
+	// ReceiverKindValue は、値レシーバのメソッドを表す。
+	ReceiverKindValue ReceiverKind = "value"
+	// ReceiverKindPointer は、ポインタレシーバのメソッドを表す。
+	ReceiverKindPointer ReceiverKind = "pointer"
+	// ReceiverKindInterface は、埋め込まれたinterfaceのメソッドを表す。
+	ReceiverKindInterface ReceiverKind = "interface"
+)
+
+func (k ReceiverKind) String() string {
+	return string(k)
+}
+
+// newImplementation は、 typ による i の実装を返す。
+// Struct.Implements と同様に、メソッドを持たないinterfaceは includeEmpty が真の場合のみ実装しているものとして扱う。
+func newImplementation(currentPkgSummary *PackageSummary, typ types.Type, i *Interface, includeEmpty bool) (*Implementation, bool) {
+	if !implementsInterface(typ, i.goInterface, includeEmpty) {
+		return nil, false
+	}
+
+	selections := make(map[string]*MethodSelection)
+	for _, sel := range newMethodSelections(currentPkgSummary, typ, true) {
+		selections[sel.Function().Name().String()] = sel
+	}
+
+	impl := &Implementation{
+		typ:             newType(currentPkgSummary, typ),
+		iface:           i,
+		requiresPointer: !types.Implements(typ, i.goInterface),
+	}
+	for _, m := range i.Methods() {
+		sel, ok := selections[m.Name().String()]
+		if !ok {
+			return nil, false
+		}
+		impl.methods = append(impl.methods, &MethodImplementation{interfaceMethod: m, selection: sel})
+	}
+	return impl, true
+}
+
+// Type は、interfaceを実装する型を返す。
+func (impl *Implementation) Type() *Type {
+	return impl.typ
+}
+
+func (impl *Implementation) Interface() *Interface {
+	return impl.iface
+}
+
+// Methods は、interfaceのメソッドごとの実装をinterfaceのメソッドの順に返す。
+func (impl *Implementation) Methods() []*MethodImplementation {
+	return append([]*MethodImplementation{}, impl.methods...)
+}
+
+// Method は、interfaceのメソッド name の実装を返す。
+func (impl *Implementation) Method(name FunctionName) (*MethodImplementation, bool) {
+	for _, m := range impl.methods {
+		if m.interfaceMethod.Name() == name {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// RequiresPointer は、型の値ではなくポインタでなければinterfaceを実装しないかを返す。
+func (impl *Implementation) RequiresPointer() bool {
+	return impl.requiresPointer
+}
+
+// InterfaceMethod は、実装されるinterfaceのメソッドを返す。
+func (mi *MethodImplementation) InterfaceMethod() *Function {
+	return mi.interfaceMethod
+}
+
+// ConcreteMethod は、interfaceのメソッドを実装するメソッドを返す。
+// 埋め込まれたinterfaceのメソッドによって実装される場合は、そのinterfaceのメソッドを返す。
+func (mi *MethodImplementation) ConcreteMethod() *Function {
+	return mi.selection.Function()
+}
+
+// Origin は、実装するメソッドが宣言されている型を返す。
+func (mi *MethodImplementation) Origin() *Type {
+	return mi.selection.Origin()
+}
+
+// ReceiverKind は、実装するメソッドのレシーバの種類を返す。
+func (mi *MethodImplementation) ReceiverKind() ReceiverKind {
+	fn := mi.selection.Function()
+	switch {
+	case types.IsInterface(fn.signature().Recv().Type()):
+		return ReceiverKindInterface
+	case fn.PointerReceiver():
+		return ReceiverKindPointer
+	default:
+		return ReceiverKindValue
+	}
+}
+
+// EmbeddingPath は、実装するメソッドに辿り着くまでに経由する埋め込みフィールドの一覧を返す。
+// 型自身に宣言されたメソッドであれば空となる。
+func (mi *MethodImplementation) EmbeddingPath() []*Field {
+	return mi.selection.Path()
+}
+
+// Promoted は、実装するメソッドが埋め込みによって昇格したメソッドであるかを返す。
+func (mi *MethodImplementation) Promoted() bool {
+	return mi.selection.Promoted()
+}
@@ -0,0 +1,102 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+)
+
+const implementationTestSource = `package store
+
+type Logger interface {
+	Log(msg string)
+}
+
+type Store interface {
+	Get(key string) string
+	Put(key, value string)
+	Log(msg string)
+	Close() error
+}
+
+type cache struct{}
+
+func (c *cache) Get(key string) string { return "" }
+
+type Memory struct {
+	cache
+	Logger
+}
+
+func (m Memory) Close() error         { return nil }
+func (m *Memory) Put(key, value string) {}
+
+type ReadOnly struct {
+	Logger
+}
+
+func (r ReadOnly) Get(key string) string { return "" }
+`
+
+func TestStruct_Implementation(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"store/store.go": implementationTestSource})
+	s, ok := r.Structs().Get("store", "Memory")
+	if !ok {
+		t.Fatal("Memory not found")
+	}
+	i, ok := r.Interfaces().Get("store", "Store")
+	if !ok {
+		t.Fatal("Store not found")
+	}
+
+	impl, ok := s.Implementation(i)
+	if !ok {
+		t.Fatal("Implementation() ok = false, want true")
+	}
+	if !impl.RequiresPointer() {
+		t.Error("RequiresPointer() = false, want true")
+	}
+
+	var got []string
+	for _, m := range impl.Methods() {
+		got = append(got, strings.Join([]string{
+			m.InterfaceMethod().Name().String(),
+			m.Origin().TypeName().String(),
+			m.ReceiverKind().String(),
+			pathString(m.EmbeddingPath()),
+		}, " "))
+	}
+	want := []string{
+		"Close Memory value ",
+		"Get cache pointer cache",
+		"Log Logger interface Logger",
+		"Put Memory pointer ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Methods() = \n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if m, ok := impl.Method("Get"); !ok || !m.Promoted() {
+		t.Error("Method(Get).Promoted() = false, want true")
+	}
+
+	readOnly, ok := r.Structs().Get("store", "ReadOnly")
+	if !ok {
+		t.Fatal("ReadOnly not found")
+	}
+	if _, ok := readOnly.Implementation(i); ok {
+		t.Error("ReadOnly.Implementation(Store) ok = true, want false")
+	}
+	logger, _ := r.Interfaces().Get("store", "Logger")
+	impl, ok = readOnly.Implementation(logger)
+	if !ok || impl.RequiresPointer() {
+		t.Errorf("ReadOnly.Implementation(Logger) ok = %v, want true without pointer", ok)
+	}
+
+	var names []string
+	for _, impl := range s.Implementations() {
+		names = append(names, impl.Interface().Name().String())
+	}
+	if got := strings.Join(names, ","); got != "Logger,Store" {
+		t.Errorf("Memory.Implementations() = %s, want Logger,Store", got)
+	}
+}
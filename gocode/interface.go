@@ -17,16 +17,28 @@ type (
 	Interface struct {
 		definedPos  token.Pos
 		goInterface *types.Interface
+		typ         *Type
 		name        InterfaceName
 		pkgSummary  *PackageSummary
 		methods     *FunctionList
 		embeds      *EmbedList
+		// extends はメソッドセットが包含するinterfaceの一覧。
+		extends *PackageInterfaceMap
+		// extendedBy はメソッドセットを包含されるinterfaceの一覧。
+		extendedBy *PackageInterfaceMap
 	}
 
 	// InterfaceList はinterfaceのリストを表す。
 	InterfaceList struct {
 		interfaces []*Interface
 	}
+
+	// InterfaceEmbedNode は、interfaceの埋め込みの木構造の1つの節を表す。
+	InterfaceEmbedNode struct {
+		typ             *Type
+		explicitMethods *FunctionList
+		children        []*InterfaceEmbedNode
+	}
 )
 
 func (in InterfaceName) String() string {
@@ -60,6 +72,9 @@ func (il *InterfaceList) asSlice() []*Interface {
 }
 
 func newInterfaceIfInterfaceType(obj types.Object) (res *Interface, ok bool) {
+	if tn, ok := obj.(*types.TypeName); ok && tn.IsAlias() {
+		return &Interface{}, false
+	}
 	interfaceType, ok := obj.Type().Underlying().(*types.Interface)
 	if !ok {
 		return &Interface{}, false
@@ -70,10 +85,13 @@ func newInterfaceIfInterfaceType(obj types.Object) (res *Interface, ok bool) {
 	return &Interface{
 		definedPos:  obj.Pos(),
 		goInterface: interfaceType,
+		typ:         newType(pkgSummary, obj.Type()),
 		pkgSummary:  pkgSummary,
 		name:        InterfaceName(obj.Name()),
 		methods:     newFunctionListFromInterface(interfaceType),
 		embeds:      newEmbedListFromInterfaceType(pkgSummary, interfaceType),
+		extends:     newPackageInterfaceMap(),
+		extendedBy:  newPackageInterfaceMap(),
 	}, true
 }
 
@@ -89,6 +107,10 @@ func (i *Interface) Name() InterfaceName {
 	return i.name
 }
 
+func (i *Interface) Type() *Type {
+	return i.typ
+}
+
 func (i *Interface) PackageInterfaceName() PackageInterfaceName {
 	return NewPackageInterfaceName(i.PackageSummary().Name(), i.Name())
 }
@@ -101,6 +123,112 @@ func (i *Interface) Embeds() []*Embed {
 	return i.embeds.asSlice()
 }
 
+// ExplicitMethods は、埋め込みによるものを除いた、interfaceに直接宣言されたメソッドの一覧を返す。
+func (i *Interface) ExplicitMethods() []*Function {
+	return newExplicitMethodsFromInterface(i.goInterface).asSlice()
+}
+
+// EmbedTree は、interface自身を根とする埋め込みの木構造を返す。
+func (i *Interface) EmbedTree() *InterfaceEmbedNode {
+	return newInterfaceEmbedNode(i.pkgSummary, i.typ.GoType(), i.goInterface)
+}
+
+// Extends は、interfaceのメソッドセットが other のメソッドセットを包含するかを返す。
+// メソッドはメソッド名とシグネチャで比較するため、埋め込みの有無に関わらず判定する。
+// 自分自身とメソッドを持たないinterfaceは対象としない。
+func (i *Interface) Extends(other *Interface) bool {
+	if i == other || other.goInterface.NumMethods() == 0 {
+		return false
+	}
+	if i.pkgSummary.Path() == other.pkgSummary.Path() && i.name == other.name {
+		return false
+	}
+	return extendsBySignature(i.goInterface, other.goInterface)
+}
+
+// ExtendedInterfaces は、interfaceのメソッドセットが包含する解析対象のinterfaceの一覧を返す。
+func (i *Interface) ExtendedInterfaces() *PackageInterfaceMap {
+	return i.extends
+}
+
+// ExtendingInterfaces は、メソッドセットがinterfaceのメソッドセットを包含する解析対象のinterfaceの一覧を返す。
+func (i *Interface) ExtendingInterfaces() *PackageInterfaceMap {
+	return i.extendedBy
+}
+
+func (i *Interface) addInterfaceIfExtends(other *Interface) {
+	if i.Extends(other) {
+		i.extends.put(other)
+		other.extendedBy.put(i)
+	}
+}
+
+func newInterfaceEmbedNode(currentPkgSummary *PackageSummary, typ types.Type, interfaceType *types.Interface) *InterfaceEmbedNode {
+	node := &InterfaceEmbedNode{
+		typ:             newType(currentPkgSummary, typ),
+		explicitMethods: newExplicitMethodsFromInterface(interfaceType),
+	}
+	for j := 0; j < interfaceType.NumEmbeddeds(); j++ {
+		embedded := interfaceType.EmbeddedType(j)
+		if it, ok := embedded.Underlying().(*types.Interface); ok {
+			node.children = append(node.children, newInterfaceEmbedNode(currentPkgSummary, embedded, it))
+		}
+	}
+	return node
+}
+
+// Type は、節のinterfaceの型を返す。
+func (n *InterfaceEmbedNode) Type() *Type {
+	return n.typ
+}
+
+// ExplicitMethods は、節のinterfaceに直接宣言されたメソッドの一覧を返す。
+func (n *InterfaceEmbedNode) ExplicitMethods() []*Function {
+	return n.explicitMethods.asSlice()
+}
+
+// Children は、節のinterfaceに埋め込まれたinterfaceの節の一覧を返す。
+func (n *InterfaceEmbedNode) Children() []*InterfaceEmbedNode {
+	return append([]*InterfaceEmbedNode{}, n.children...)
+}
+
+func newExplicitMethodsFromInterface(interfaceType *types.Interface) *FunctionList {
+	var methods []*Function
+	for j := 0; j < interfaceType.NumExplicitMethods(); j++ {
+		if fn, ok := newFunctionIfSignatureType(interfaceType.ExplicitMethod(j)); ok {
+			methods = append(methods, fn)
+		}
+	}
+	return &FunctionList{functions: methods}
+}
+
+// extendsBySignature は、メソッド名とシグネチャの文字列の比較によって i が other の全てのメソッドを持つかを判定する。
+func extendsBySignature(i, other *types.Interface) bool {
+	for j := 0; j < other.NumMethods(); j++ {
+		m := other.Method(j)
+		found := false
+		for k := 0; k < i.NumMethods(); k++ {
+			if im := i.Method(k); im.Name() == m.Name() {
+				found = signatureKey(im.Type().(*types.Signature)) == signatureKey(m.Type().(*types.Signature))
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// implementsInterface は、 typ が i を実装しているかを判定する。
+// メソッドを持たないinterfaceは includeEmpty が真の場合のみ実装しているとみなす。
+func implementsInterface(typ types.Type, i *types.Interface, includeEmpty bool) bool {
+	if i.NumMethods() == 0 {
+		return includeEmpty
+	}
+	return implements(typ, i)
+}
+
 func implements(typ types.Type, i *types.Interface) bool {
 	if i.NumMethods() == 0 {
 		return false
@@ -113,3 +241,34 @@ func implements(typ types.Type, i *types.Interface) bool {
 	}
 	return types.Implements(typ, i)
 }
+
+// lookupMethod は、 typ のポインタのメソッドセットから name のメソッドを探す。
+// 埋め込みによって昇格したメソッドも対象とする。
+func lookupMethod(typ types.Type, name string) (*types.Func, bool) {
+	if _, ok := typ.(*types.Pointer); !ok {
+		typ = types.NewPointer(typ)
+	}
+	ms := types.NewMethodSet(typ)
+	for i := 0; i < ms.Len(); i++ {
+		if f, ok := ms.At(i).Obj().(*types.Func); ok && f.Name() == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// implementsBySignature は、メソッド名とシグネチャの文字列の比較によって typ が i を実装しているかを判定する。
+// 別々にロードされたパッケージの型同士は types.Implements で判定できないため、その代わりに用いる。
+func implementsBySignature(typ types.Type, i *types.Interface) bool {
+	if i.NumMethods() == 0 {
+		return false
+	}
+	for j := 0; j < i.NumMethods(); j++ {
+		m := i.Method(j)
+		f, ok := lookupMethod(typ, m.Name())
+		if !ok || signatureKey(f.Type().(*types.Signature)) != signatureKey(m.Type().(*types.Signature)) {
+			return false
+		}
+	}
+	return true
+}
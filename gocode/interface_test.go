@@ -0,0 +1,130 @@
+package gocode_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+const interfaceTestSource = `package plugin
+
+type Namer interface {
+	Name() string
+}
+
+type Starter interface {
+	Start() error
+}
+
+type Plugin interface {
+	Namer
+	Starter
+	Version() int
+}
+
+type Reloadable interface {
+	Plugin
+	Reload() error
+}
+
+type Named interface {
+	Name() string
+	Description() string
+}
+
+type Any interface{}
+`
+
+func TestInterface_ExplicitMethods(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"plugin/plugin.go": interfaceTestSource})
+	i, ok := r.Interfaces().Get("plugin", "Reloadable")
+	if !ok {
+		t.Fatal("Reloadable not found")
+	}
+	if got := functionNames(i.ExplicitMethods()); got != "Reload" {
+		t.Errorf("ExplicitMethods() = %s, want Reload", got)
+	}
+	if got := functionNames(i.Methods()); got != "Name,Reload,Start,Version" {
+		t.Errorf("Methods() = %s, want Name,Reload,Start,Version", got)
+	}
+}
+
+func TestInterface_EmbedTree(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"plugin/plugin.go": interfaceTestSource})
+	i, ok := r.Interfaces().Get("plugin", "Reloadable")
+	if !ok {
+		t.Fatal("Reloadable not found")
+	}
+
+	var lines []string
+	var walk func(n *gocode.InterfaceEmbedNode, depth int)
+	walk = func(n *gocode.InterfaceEmbedNode, depth int) {
+		lines = append(lines, strings.Repeat("  ", depth)+n.Type().TypeName().String()+" ["+functionNames(n.ExplicitMethods())+"]")
+		for _, c := range n.Children() {
+			walk(c, depth+1)
+		}
+	}
+	walk(i.EmbedTree(), 0)
+
+	want := []string{
+		"Reloadable [Reload]",
+		"  Plugin [Version]",
+		"    Namer [Name]",
+		"    Starter [Start]",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("EmbedTree() = \n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestInterface_Extends(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"plugin/plugin.go": interfaceTestSource})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Namer", want: ""},
+		{name: "Plugin", want: "plugin.Namer,plugin.Starter"},
+		{name: "Reloadable", want: "plugin.Namer,plugin.Plugin,plugin.Starter"},
+		// 埋め込みではなく同じメソッドを宣言していても包含とみなす。
+		{name: "Named", want: "plugin.Namer"},
+		{name: "Any", want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			i, ok := r.Interfaces().Get("plugin", gocode.InterfaceName(test.name))
+			if !ok {
+				t.Fatalf("%s not found", test.name)
+			}
+			var got []string
+			for _, name := range i.ExtendedInterfaces().PackageInterfaceNames() {
+				got = append(got, name.String())
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != test.want {
+				t.Errorf("ExtendedInterfaces() = %v, want %s", got, test.want)
+			}
+		})
+	}
+
+	namer, _ := r.Interfaces().Get("plugin", "Namer")
+	var got []string
+	for _, name := range namer.ExtendingInterfaces().PackageInterfaceNames() {
+		got = append(got, name.String())
+	}
+	sort.Strings(got)
+	if want := "plugin.Named,plugin.Plugin,plugin.Reloadable"; strings.Join(got, ",") != want {
+		t.Errorf("ExtendingInterfaces() = %v, want %s", got, want)
+	}
+}
+
+func functionNames(functions []*gocode.Function) string {
+	var names []string
+	for _, f := range functions {
+		names = append(names, f.Name().String())
+	}
+	return strings.Join(names, ",")
+}
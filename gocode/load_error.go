@@ -0,0 +1,128 @@
+package gocode
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+type (
+	// LoadErrorKind は、解析時に発生したエラーの種類を表す。
+	LoadErrorKind string
+
+	// LoadError は、ディレクトリの走査またはパッケージの解析で発生したエラーを表す。
+	LoadError struct {
+		kind LoadErrorKind
+		// directory はエラーが発生した時に走査または解析していたディレクトリ。
+		directory string
+		// pkgPath はエラーが発生したパッケージのパス。パッケージが特定できない場合は空となる。
+		pkgPath  PackagePath
+		position token.Position
+		err      error
+	}
+)
+
+const (
+	// LoadErrorWalk は、ディレクトリの走査の失敗を表す。
+	LoadErrorWalk LoadErrorKind = "walk"
+	// LoadErrorList は、パッケージの一覧の取得や依存関係の解決の失敗を表す。
+	LoadErrorList LoadErrorKind = "list"
+	// LoadErrorParse は、構文の誤りを表す。
+	LoadErrorParse LoadErrorKind = "parse"
+	// LoadErrorType は、型検査の誤りを表す。
+	LoadErrorType LoadErrorKind = "type"
+)
+
+func (k LoadErrorKind) String() string {
+	return string(k)
+}
+
+func newLoadErrorFromPackages(directory string, pkgPath string, pe packages.Error) *LoadError {
+	kind := LoadErrorList
+	switch pe.Kind {
+	case packages.ParseError:
+		kind = LoadErrorParse
+	case packages.TypeError:
+		kind = LoadErrorType
+	}
+	return &LoadError{
+		kind:      kind,
+		directory: directory,
+		pkgPath:   PackagePath(pkgPath),
+		position:  parseErrorPosition(pe.Pos),
+		err:       errors.New(pe.Msg),
+	}
+}
+
+// parseErrorPosition は、 packages.Error の "file:line:column" 形式の位置を変換する。行や列がなければ0となる。
+func parseErrorPosition(pos string) token.Position {
+	if pos == "" || pos == "-" {
+		return token.Position{}
+	}
+	var position token.Position
+	rest := pos
+	var numbers []int
+	for len(numbers) < 2 {
+		i := strings.LastIndex(rest, ":")
+		if i < 0 {
+			break
+		}
+		n, err := strconv.Atoi(rest[i+1:])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		rest = rest[:i]
+	}
+	position.Filename = rest
+	if len(numbers) > 0 {
+		position.Line = numbers[0]
+	}
+	if len(numbers) > 1 {
+		position.Column = numbers[1]
+	}
+	return position
+}
+
+func (e *LoadError) Error() string {
+	switch {
+	case e.position.Filename != "":
+		return fmt.Sprintf("%s: %v", e.position, e.err)
+	case e.pkgPath != "":
+		return fmt.Sprintf("%s: %v", e.pkgPath, e.err)
+	default:
+		return fmt.Sprintf("%s: %v", e.directory, e.err)
+	}
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.err
+}
+
+func (e *LoadError) Kind() LoadErrorKind {
+	return e.kind
+}
+
+func (e *LoadError) Directory() string {
+	return e.directory
+}
+
+// PackagePath は、エラーが発生したパッケージのパスを返す。ディレクトリの走査の失敗などパッケージが特定できない場合は空となる。
+func (e *LoadError) PackagePath() PackagePath {
+	return e.pkgPath
+}
+
+// Position は、エラーが発生したソースコードの位置を返す。位置が特定できない場合は Filename が空となる。
+func (e *LoadError) Position() token.Position {
+	return e.position
+}
+
+// sameDirectory は、 a と b が同じディレクトリを指していれば真を返す。
+func sameDirectory(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
@@ -0,0 +1,102 @@
+package gocode_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+func TestLoadRelations_Errors(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"good/good.go": `package good
+
+type Good struct{}
+`,
+		"typed/typed.go": `package typed
+
+type Partial struct {
+	Name string
+}
+
+var x int = "not an int"
+`,
+		"parsed/parsed.go": `package parsed
+
+type Broken struct {
+`,
+	})
+	directories := []string{dir, filepath.Join(dir, "missing")}
+
+	r, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:  afero.NewOsFs(),
+		Directories: directories,
+		Recursive:   true,
+	})
+	if err != nil {
+		t.Fatalf("LoadRelations() error = %v, want nil without FailFast", err)
+	}
+	for _, name := range []gocode.PackageStructName{"good.Good", "typed.Partial"} {
+		found := false
+		for _, s := range r.Structs().StructAll() {
+			found = found || s.PackageStructName() == name
+		}
+		if !found {
+			t.Errorf("struct %s is not loaded", name)
+		}
+	}
+
+	kinds := make(map[gocode.LoadErrorKind]*gocode.LoadError)
+	for _, e := range r.Errors() {
+		kinds[e.Kind()] = e
+	}
+	if e, ok := kinds[gocode.LoadErrorType]; !ok || e.PackagePath() != "example.com/testmodule/typed" || filepath.Base(e.Position().Filename) != "typed.go" || e.Position().Line != 7 {
+		t.Errorf("type error = %v, want typed.go:7 in example.com/testmodule/typed", e)
+	}
+	if e, ok := kinds[gocode.LoadErrorParse]; !ok || e.PackagePath() != "example.com/testmodule/parsed" {
+		t.Errorf("parse error = %v, want an error in example.com/testmodule/parsed", e)
+	}
+	if e, ok := kinds[gocode.LoadErrorWalk]; !ok || e.Directory() != directories[1] {
+		t.Errorf("walk error = %v, want an error for %s", e, directories[1])
+	}
+
+	_, err = gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:  afero.NewOsFs(),
+		Directories: directories,
+		Recursive:   true,
+		FailFast:    true,
+	})
+	var loadErr *gocode.LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("LoadRelations() error = %v, want a LoadError with FailFast", err)
+	}
+}
+
+func TestLoadRelations_Errors_NoGoFiles(t *testing.T) {
+	// モジュールのルートと docs にはGoのファイルがない。
+	dir := writeTestModule(t, map[string]string{
+		"docs/README.md": "# docs\n",
+		"good/good.go": `package good
+
+type Good struct{}
+`,
+	})
+
+	r, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:  afero.NewOsFs(),
+		Directories: []string{dir},
+		Recursive:   true,
+		FailFast:    true,
+	})
+	if err != nil {
+		t.Fatalf("LoadRelations() error = %v, want nil for directories without Go files", err)
+	}
+	if len(r.Errors()) != 0 {
+		t.Errorf("Errors() = %v, want none", r.Errors())
+	}
+	if !r.Structs().Contains("good", "Good") {
+		t.Error("struct good.Good is not loaded")
+	}
+}
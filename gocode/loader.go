@@ -2,8 +2,11 @@ package gocode
 
 import (
 	"fmt"
+	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/afero"
@@ -14,34 +17,87 @@ import (
 type (
 	// Relations は解析したgoコードの結果を保持する構造体。
 	Relations struct {
+		fset         *token.FileSet
 		packages     *PackageMap
 		structs      *PackageStructureMap
 		interfaces   *PackageInterfaceMap
 		typeAliases  *PackageTypeAliasMap
 		definedTypes *PackageDefinedTypeMap
+		// includeEmptyInterfaces が真であれば、メソッドを持たないinterfaceを全ての型が実装しているものとして扱う。
+		includeEmptyInterfaces bool
+		// failFast が真であれば、最初の LoadError で解析を中断する。
+		failFast bool
+		// fs は解析したディレクトリのファイルシステム。 Reload でディレクトリの有無を調べるのに使う。
+		fs     afero.Fs
+		filter *loadFilter
+		errors []*LoadError
 	}
 
 	// LoadOptions はgoコード解析時のオプション。
 	LoadOptions struct {
-		FileSystem         afero.Fs
-		Directories        []string
+		FileSystem  afero.Fs
+		Directories []string
+		// IgnoredDirectories は解析しないディレクトリ。パスそのもの、または DirectoryFilter と同じ形式のパターンで指定する。
 		IgnoredDirectories []string
 		Recursive          bool
+		// DirectoryFilter は、 Directories からの相対パスで解析するディレクトリを絞り込む。
+		// Exclude に一致したディレクトリはその下も走査しないが、 Include に一致しないディレクトリの下は走査を続ける。
+		DirectoryFilter PathFilter
+		// PackageFilter は、パッケージのパスで解析するパッケージを絞り込む。
+		PackageFilter PathFilter
+		// FileFilter は、 Directories からの相対パスで解析するファイルを絞り込む。
+		// 除いたファイルで宣言された型と関数は、パッケージの解析結果に含めない。
+		FileFilter PathFilter
+		// ExcludeGenerated が真であれば、 "// Code generated ... DO NOT EDIT." のコメントがある生成されたファイルを除く。
+		ExcludeGenerated bool
+		// IncludeHiddenDirectories が真であれば、 "." で始まる隠しディレクトリも走査する。
+		IncludeHiddenDirectories bool
+		// IncludeVendor が真であれば、 vendor ディレクトリも走査する。
+		IncludeVendor bool
+		// IncludeEmptyInterfaces が真であれば、 interface{} のようなメソッドを持たないinterfaceも
+		// 全ての型が実装しているものとして実装関係に含める。偽であれば実装関係から除外する。
+		IncludeEmptyInterfaces bool
+		// FailFast が真であれば、ディレクトリの走査やパッケージの解析で最初に発生した LoadError を返して解析を中断する。
+		// 偽であればエラーが発生したパッケージも解析できた範囲で追加し、エラーは Relations.Errors で取得できる。
+		FailFast bool
+	}
+
+	// AnalysisOptions は、 LoadRelationsFromAnalysisWithOptions の解析のオプション。
+	AnalysisOptions struct {
+		// IncludeEmptyInterfaces は LoadOptions.IncludeEmptyInterfaces と同じ。
+		IncludeEmptyInterfaces bool
 	}
 )
 
 func newRelations() *Relations {
-	return &Relations{
+	r := &Relations{
+		fset:         token.NewFileSet(),
 		packages:     newPackageMap(),
 		structs:      newPackageStructureMap(),
 		interfaces:   newPackageInterfaceMap(),
 		typeAliases:  newPackageTypeAliasMap(),
 		definedTypes: newPackageDefinedTypeMap(),
+		fs:           afero.NewOsFs(),
 	}
+	r.structs.aliases = r.typeAliases
+	r.interfaces.aliases = r.typeAliases
+	// 空の LoadOptions のパターンはコンパイルに失敗しない。
+	r.filter, _ = newLoadFilter(&LoadOptions{})
+	return r
 }
 
 func LoadRelations(options *LoadOptions) (*Relations, error) {
 	r := newRelations()
+	r.includeEmptyInterfaces = options.IncludeEmptyInterfaces
+	r.failFast = options.FailFast
+	if options.FileSystem != nil {
+		r.fs = options.FileSystem
+	}
+	filter, err := newLoadFilter(options)
+	if err != nil {
+		return r, err
+	}
+	r.filter = filter
 	if err := r.load(options); err != nil {
 		return r, err
 	}
@@ -49,12 +105,35 @@ func LoadRelations(options *LoadOptions) (*Relations, error) {
 }
 
 func LoadRelationsFromAnalysis(pass *analysis.Pass) *Relations {
+	return LoadRelationsFromAnalysisWithOptions(pass, &AnalysisOptions{})
+}
+
+// LoadRelationsFromAnalysisWithOptions は、 LoadRelationsFromAnalysis と同じく pass のパッケージを options に従って解析する。
+func LoadRelationsFromAnalysisWithOptions(pass *analysis.Pass, options *AnalysisOptions) *Relations {
 	r := newRelations()
+	r.includeEmptyInterfaces = options.IncludeEmptyInterfaces
+	r.fset = pass.Fset
 	p := newPackageFromAnalysis(pass)
 	r.addPackage(p)
+	r.registerRelations()
 	return r
 }
 
+// FileSet は、解析したコードの位置情報を保持する token.FileSet を返す。
+func (r *Relations) FileSet() *token.FileSet {
+	return r.fset
+}
+
+// Position は、 DefinedPos などの位置をファイル名と行番号を含む位置情報に変換する。
+func (r *Relations) Position(pos token.Pos) token.Position {
+	return r.fset.Position(pos)
+}
+
+// IncludesEmptyInterfaces は、メソッドを持たないinterfaceを実装関係に含めるかを返す。
+func (r *Relations) IncludesEmptyInterfaces() bool {
+	return r.includeEmptyInterfaces
+}
+
 func (r *Relations) Packages() *PackageMap {
 	return r.packages
 }
@@ -75,46 +154,105 @@ func (r *Relations) DefinedTypes() *PackageDefinedTypeMap {
 	return r.definedTypes
 }
 
+// Errors は、ディレクトリの走査とパッケージの解析で発生したエラーの一覧を返す。
+// Reload で再度解析したディレクトリのエラーは、再度解析した結果のエラーに置き換わる。
+func (r *Relations) Errors() []*LoadError {
+	return append([]*LoadError{}, r.errors...)
+}
+
+// reportError は、 failFast であれば err を返し、そうでなければ err を記録して nil を返す。
+func (r *Relations) reportError(err *LoadError) error {
+	if r.failFast {
+		return err
+	}
+	r.errors = append(r.errors, err)
+	return nil
+}
+
+// removeErrors は、 directory を解析した時に記録したエラーを取り除く。
+func (r *Relations) removeErrors(directory string) {
+	errs := r.errors[:0]
+	for _, e := range r.errors {
+		if !sameDirectory(e.directory, directory) {
+			errs = append(errs, e)
+		}
+	}
+	r.errors = errs
+}
+
 func (r *Relations) load(options *LoadOptions) error {
-	ignoreDirectoryMap := map[string]struct{}{}
-	for _, dir := range options.IgnoredDirectories {
-		ignoreDirectoryMap[dir] = struct{}{}
+	err := walkDirectories(options, func(path string) error {
+		return r.parseDirectory(path, nil)
+	}, r.reportError)
+	if err != nil {
+		return err
+	}
+
+	r.registerRelations()
+
+	return nil
+}
+
+// walkDirectories は、 options の解析対象のディレクトリごとに fn を呼び出す。
+// Recursive が真であれば、隠しディレクトリと vendor 、 IgnoredDirectories と DirectoryFilter で除いたものを除くサブディレクトリも対象とする。
+// 走査に失敗した場合は onError を呼び出し、 onError が nil を返せばそのディレクトリを除いて走査を続ける。
+// onError が nil の場合は走査の失敗をそのまま返す。
+func walkDirectories(options *LoadOptions, fn func(path string) error, onError func(err *LoadError) error) error {
+	handleError := func(path string, err error) error {
+		loadErr := &LoadError{kind: LoadErrorWalk, directory: path, err: err}
+		if onError == nil {
+			return loadErr
+		}
+		if err := onError(loadErr); err != nil {
+			return err
+		}
+		return filepath.SkipDir
+	}
+
+	filter, err := newLoadFilter(options)
+	if err != nil {
+		return err
 	}
 
 	for _, directoryPath := range options.Directories {
 		if options.Recursive {
 			err := afero.Walk(options.FileSystem, directoryPath, func(path string, info os.FileInfo, err error) error {
 				if err != nil {
-					return err
+					return handleError(path, err)
+				}
+				if !info.IsDir() {
+					return nil
+				}
+				if filter.skipDirectory(directoryPath, path) {
+					return filepath.SkipDir
 				}
-				if info.IsDir() {
-					if strings.HasPrefix(info.Name(), ".") || info.Name() == "vendor" {
-						return filepath.SkipDir
-					}
-					if _, ok := ignoreDirectoryMap[path]; ok {
-						return filepath.SkipDir
-					}
-					return r.parseDirectory(path)
+				if !filter.includeDirectory(path) {
+					return nil
 				}
-				return nil
+				return fn(path)
 			})
-			if err != nil {
+			if err != nil && err != filepath.SkipDir {
 				return err
 			}
 		} else {
-			err := r.parseDirectory(directoryPath)
+			if _, err := options.FileSystem.Stat(directoryPath); err != nil {
+				if err := handleError(directoryPath, err); err != filepath.SkipDir {
+					return err
+				}
+				continue
+			}
+			err := fn(directoryPath)
 			if err != nil {
 				return err
 			}
 		}
 	}
-
-	r.registerRelations()
-
 	return nil
 }
 
-func (r *Relations) parseDirectory(directoryPath string) error {
+// parseDirectory は、 directoryPath のパッケージを解析して追加する。
+// overlay には保存されていないファイルの内容をファイルの絶対パスごとに指定する。
+func (r *Relations) parseDirectory(directoryPath string, overlay map[string][]byte) error {
 	loadConfig := &packages.Config{
 		Mode: packages.NeedTypes |
 			packages.NeedTypesInfo |
@@ -122,15 +260,39 @@ func (r *Relations) parseDirectory(directoryPath string) error {
 			packages.NeedName |
 			packages.NeedFiles |
 			packages.NeedImports,
-		Dir: directoryPath,
+		Dir:     directoryPath,
+		Fset:    r.fset,
+		Overlay: overlay,
 	}
 	pkgs, err := packages.Load(loadConfig)
 	if err != nil {
-		return fmt.Errorf("load packages failed: %w", err)
+		return r.reportError(&LoadError{
+			kind:      LoadErrorList,
+			directory: directoryPath,
+			err:       fmt.Errorf("load packages failed: %w", err),
+		})
 	}
 	for i := range pkgs {
-		p := newPackageFromPackages(pkgs[i])
+		// Goのファイルがないディレクトリは、解析するものがないためエラーとしない。
+		if len(pkgs[i].GoFiles) == 0 && len(pkgs[i].CompiledGoFiles) == 0 {
+			continue
+		}
+		if !r.filter.includePackage(pkgs[i].PkgPath) {
+			continue
+		}
+		in := newPackageInPackages(pkgs[i])
+		excluded := r.filter.excludedFiles(r.fset, in.Files())
+		if len(in.Files()) > 0 && len(excluded) == len(in.Files()) {
+			continue
+		}
+		p := newPackage(newPackageInFilteredFiles(in, r.fset, excluded))
+		r.removePackage(p.Summary().Path())
 		r.addPackage(p)
+		for _, pe := range pkgs[i].Errors {
+			if err := r.reportError(newLoadErrorFromPackages(directoryPath, pkgs[i].PkgPath, pe)); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -147,6 +309,120 @@ func (r *Relations) addPackage(p *Package) {
 	r.registerDefinedTypes(p)
 }
 
+// removePackage は、 pkgPath のパッケージと、そのパッケージの型を取り除く。
+func (r *Relations) removePackage(pkgPath PackagePath) {
+	r.packages.remove(pkgPath)
+	r.structs.remove(pkgPath)
+	r.interfaces.remove(pkgPath)
+	r.typeAliases.remove(pkgPath)
+	r.definedTypes.remove(pkgPath)
+}
+
+// Reload は、 directories のパッケージのみを再度解析し、それらのパッケージに関わる実装関係と包含関係を計算し直す。
+// overlay には、エディタで編集中のように保存されていないファイルの内容をファイルの絶対パスごとに指定できる。
+// 戻り値は再度解析したパッケージと、ディレクトリから無くなったパッケージの一覧。
+// 解析に失敗した場合も、それまでに解析したパッケージの実装関係は計算し直す。
+// LoadOptions の走査で解析しないディレクトリは、指定されても解析しない。
+func (r *Relations) Reload(directories []string, overlay map[string][]byte) (reloaded, removed []PackagePath, err error) {
+	before := make(map[PackagePath]struct{})
+	for _, dir := range directories {
+		for _, path := range r.packagesInDirectory(dir) {
+			before[path] = struct{}{}
+			r.removePackage(path)
+		}
+	}
+
+	changed := make(map[PackagePath]struct{})
+	for path := range before {
+		changed[path] = struct{}{}
+	}
+	for _, dir := range directories {
+		r.removeErrors(dir)
+		if _, statErr := r.fs.Stat(dir); os.IsNotExist(statErr) {
+			continue
+		}
+		// 走査で除いたディレクトリや解析対象外のディレクトリは、解析すると結果に加わってしまうため解析しない。
+		if !r.filter.walksDirectory(dir) {
+			continue
+		}
+		if err = r.parseDirectory(dir, overlay); err != nil {
+			break
+		}
+		for _, path := range r.packagesInDirectory(dir) {
+			changed[path] = struct{}{}
+			reloaded = append(reloaded, path)
+		}
+	}
+	for path := range before {
+		if !r.packages.Contains(path) {
+			removed = append(removed, path)
+		}
+	}
+	sortPackagePaths(reloaded)
+	sortPackagePaths(removed)
+
+	r.updateRelations(changed)
+	return reloaded, removed, err
+}
+
+// packagesInDirectory は、 dir のファイルから解析したパッケージの一覧を返す。
+func (r *Relations) packagesInDirectory(dir string) []PackagePath {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	var paths []PackagePath
+	for _, pkg := range r.packages.AsSlice() {
+		for _, file := range pkg.files {
+			if tf := r.fset.File(file.Pos()); tf != nil && filepath.Dir(tf.Name()) == abs {
+				paths = append(paths, pkg.Summary().Path())
+				break
+			}
+		}
+	}
+	return paths
+}
+
+func sortPackagePaths(paths []PackagePath) {
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i] < paths[j]
+	})
+}
+
+// updateRelations は、 changed のパッケージの型が関わる実装関係と包含関係のみを計算し直す。
+func (r *Relations) updateRelations(changed map[PackagePath]struct{}) {
+	isChanged := func(ps *PackageSummary) bool {
+		_, ok := changed[ps.Path()]
+		return ok
+	}
+
+	interfaces := r.interfaces.InterfaceAll()
+	for _, s := range r.structs.StructAll() {
+		for path := range changed {
+			s.implements.remove(path)
+		}
+		for _, i := range interfaces {
+			if isChanged(s.PackageSummary()) || isChanged(i.PackageSummary()) {
+				s.addInterfaceIfImplements(i)
+			}
+		}
+	}
+
+	for _, i := range interfaces {
+		for path := range changed {
+			i.extends.remove(path)
+			i.extendedBy.remove(path)
+		}
+	}
+	for _, i := range interfaces {
+		for _, other := range interfaces {
+			if isChanged(i.PackageSummary()) || isChanged(other.PackageSummary()) {
+				i.addInterfaceIfExtends(other)
+			}
+		}
+	}
+}
+
 func (r *Relations) registerRelations() {
 	structs := r.structs.StructAll()
 	for si := range structs {
@@ -155,11 +431,19 @@ func (r *Relations) registerRelations() {
 			structs[si].addInterfaceIfImplements(interfaces[i])
 		}
 	}
+
+	interfaces := r.interfaces.InterfaceAll()
+	for i := range interfaces {
+		for j := range interfaces {
+			interfaces[i].addInterfaceIfExtends(interfaces[j])
+		}
+	}
 }
 
 func (r *Relations) registerStructs(pkg *Package) {
 	structs := pkg.Detail().Structs()
 	for i := range structs {
+		structs[i].includeEmptyInterfaces = r.includeEmptyInterfaces
 		r.structs.put(structs[i])
 	}
 }
@@ -174,6 +458,7 @@ func (r *Relations) registerInterfaces(pkg *Package) {
 func (r *Relations) registerTypeAliases(pkg *Package) {
 	aliases := pkg.Detail().TypeAliases()
 	for i := range aliases {
+		aliases[i].aliases = r.typeAliases
 		r.typeAliases.put(aliases[i])
 	}
 }
@@ -181,10 +466,51 @@ func (r *Relations) registerTypeAliases(pkg *Package) {
 func (r *Relations) registerDefinedTypes(pkg *Package) {
 	definedTypes := pkg.Detail().DefinedTypes()
 	for i := range definedTypes {
+		definedTypes[i].includeEmptyInterfaces = r.includeEmptyInterfaces
 		r.definedTypes.put(definedTypes[i])
 	}
 }
 
+// implements は、 typ が i を実装しているかを判定する。
+// メソッドを持たないinterfaceは IncludesEmptyInterfaces が真の場合のみ実装しているとみなす。
+func (r *Relations) implements(typ types.Type, i *types.Interface) bool {
+	return implementsInterface(typ, i, r.includeEmptyInterfaces)
+}
+
+// lookupTypeName は、"パッケージパス.型名" または "パッケージ名.型名" 形式の名前から型を探す。
+// 解析対象のパッケージに加え、それらがimportしているパッケージも探索の対象とする。
+func (r *Relations) lookupTypeName(qualifiedName string) (*types.TypeName, bool) {
+	sep := strings.LastIndex(qualifiedName, ".")
+	if sep < 0 {
+		return nil, false
+	}
+	pkgName, typeName := qualifiedName[:sep], qualifiedName[sep+1:]
+
+	var queue []*types.Package
+	for _, pkg := range r.packages.AsSlice() {
+		if pkg.GoPackage() != nil {
+			queue = append(queue, pkg.GoPackage())
+		}
+	}
+	visited := make(map[string]struct{})
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[pkg.Path()]; ok {
+			continue
+		}
+		visited[pkg.Path()] = struct{}{}
+
+		if pkg.Path() == pkgName || pkg.Name() == pkgName {
+			if tn, ok := pkg.Scope().Lookup(typeName).(*types.TypeName); ok {
+				return tn, true
+			}
+		}
+		queue = append(queue, pkg.Imports()...)
+	}
+	return nil, false
+}
+
 func (r *Relations) PackageGraph() *PackageGraph {
 	return newPackageGraph(r, false)
 }
@@ -1,6 +1,8 @@
 package gocode_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/keisuke-m123/goanalyzer/gocode"
@@ -24,3 +26,37 @@ func TestMain(m *testing.M) {
 
 	m.Run()
 }
+
+// writeTestModule は files をテスト用の一時ディレクトリに go.mod と共に書き出し、そのディレクトリを返す。
+// files に go.mod が含まれていなければ、 go 1.17 の example.com/testmodule として書き出す。
+func writeTestModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if _, ok := files["go.mod"]; !ok {
+		files["go.mod"] = "module example.com/testmodule\n\ngo 1.17\n"
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// loadTestModule は writeTestModule で書き出したモジュールを解析する。
+func loadTestModule(t *testing.T, files map[string]string) *gocode.Relations {
+	t.Helper()
+	r, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:  afero.NewOsFs(),
+		Directories: []string{writeTestModule(t, files)},
+		Recursive:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
@@ -0,0 +1,269 @@
+package gocode
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+type (
+	// MockStyle は、生成するモックの形式を表す。
+	MockStyle string
+
+	// MockOptions は、モックの生成のオプション。
+	MockOptions struct {
+		// Style は生成するモックの形式。空の場合は MockStyleGomock となる。
+		Style MockStyle
+		// PackageName は出力するファイルのパッケージ名。空の場合は "mock_" とinterfaceのパッケージ名を連結した名前となる。
+		PackageName string
+		// PackagePath は出力するファイルのパッケージパス。interfaceと同じパッケージに出力する場合に、型を修飾しないために指定する。
+		PackagePath string
+		// GomockPackage は MockStyleGomock で使う gomock パッケージのパス。空の場合は "github.com/golang/mock/gomock" となる。
+		GomockPackage string
+	}
+
+	// mockGenerator は、interfaceからモックのソースコードを生成する。
+	mockGenerator struct {
+		options *MockOptions
+		imports *codegenImports
+		body    bytes.Buffer
+	}
+
+	// mockMethod は、モックを生成するメソッドのシグネチャを出力するファイルから見た文字列として保持する。
+	mockMethod struct {
+		name     string
+		params   []string
+		types    []string
+		results  []string
+		variadic bool
+	}
+)
+
+const (
+	// MockStyleGomock は、gomock互換のモックを表す。
+	MockStyleGomock MockStyle = "gomock"
+	// MockStyleFake は、メソッドごとの関数フィールドを呼び出すフェイクを表す。
+	MockStyleFake MockStyle = "fake"
+)
+
+const defaultGomockPackage = "github.com/golang/mock/gomock"
+
+func (s MockStyle) String() string {
+	return string(s)
+}
+
+// ParseMockStyle は、"gomock" または "fake" の文字列を MockStyle に変換する。
+func ParseMockStyle(s string) (MockStyle, error) {
+	for _, style := range []MockStyle{MockStyleGomock, MockStyleFake} {
+		if style.String() == s {
+			return style, nil
+		}
+	}
+	return "", fmt.Errorf("unknown mock style: %q", s)
+}
+
+// GenerateMocks は、 interfaces のモックを1つのファイルとして生成する。
+//
+// 型を修飾するパッケージ名には、interfaceが定義されたパッケージでのimportのエイリアスを優先して使う。
+// 出力するパッケージが異なる場合、非公開のメソッドを持つinterfaceは実装できないためエラーとなる。
+func (r *Relations) GenerateMocks(interfaces []*Interface, options *MockOptions) ([]byte, error) {
+	if len(interfaces) == 0 {
+		return nil, fmt.Errorf("no interfaces to mock")
+	}
+	if options == nil {
+		options = &MockOptions{}
+	}
+	opts := *options
+	if opts.Style == "" {
+		opts.Style = MockStyleGomock
+	}
+	if opts.PackageName == "" {
+		opts.PackageName = "mock_" + interfaces[0].PackageSummary().Name().String()
+	}
+	if opts.GomockPackage == "" {
+		opts.GomockPackage = defaultGomockPackage
+	}
+
+	g := &mockGenerator{
+		options: &opts,
+		imports: newCodegenImports(opts.PackagePath),
+	}
+	for _, i := range interfaces {
+		if pkg, ok := r.Packages().Get(i.PackageSummary().Path()); ok {
+			g.imports.useAliases(pkg.Detail().Imports())
+		}
+	}
+	for _, i := range interfaces {
+		if err := g.generate(i); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by goanalyzer mockgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", opts.PackageName)
+	g.imports.writeTo(&buf)
+	buf.Write(g.body.Bytes())
+	return formatSource(buf.Bytes())
+}
+
+func (g *mockGenerator) generate(i *Interface) error {
+	samePackage := g.options.PackagePath == i.PackageSummary().Path().String()
+	var methods []*mockMethod
+	for _, m := range i.Methods() {
+		if !samePackage && !token.IsExported(m.Name().String()) {
+			return fmt.Errorf("interface %s has unexported method %s and cannot be implemented outside its package", i.PackageInterfaceName(), m.Name())
+		}
+		methods = append(methods, g.newMockMethod(m))
+	}
+
+	ifaceName := g.imports.typeString(i.Type().GoType())
+	switch g.options.Style {
+	case MockStyleGomock:
+		g.writeGomock(i.Name().String(), ifaceName, methods)
+	case MockStyleFake:
+		g.writeFake(i.Name().String(), ifaceName, methods)
+	default:
+		return fmt.Errorf("unknown mock style: %q", g.options.Style)
+	}
+	return nil
+}
+
+func (g *mockGenerator) newMockMethod(fn *Function) *mockMethod {
+	sig := fn.signature()
+	m := &mockMethod{name: fn.Name().String(), variadic: sig.Variadic()}
+	for j := 0; j < sig.Params().Len(); j++ {
+		t := sig.Params().At(j).Type()
+		typ := g.imports.typeString(t)
+		if m.variadic && j == sig.Params().Len()-1 {
+			typ = "..." + g.imports.typeString(t.(*types.Slice).Elem())
+		}
+		m.params = append(m.params, fmt.Sprintf("arg%d", j))
+		m.types = append(m.types, typ)
+	}
+	for j := 0; j < sig.Results().Len(); j++ {
+		m.results = append(m.results, g.imports.typeString(sig.Results().At(j).Type()))
+	}
+	return m
+}
+
+// signature は、パラメータ名を含むメソッドのシグネチャを返す。
+func (m *mockMethod) signature() string {
+	params := make([]string, len(m.params))
+	for j := range m.params {
+		params[j] = m.params[j] + " " + m.types[j]
+	}
+	return fmt.Sprintf("(%s)%s", strings.Join(params, ", "), m.resultList())
+}
+
+func (m *mockMethod) resultList() string {
+	switch len(m.results) {
+	case 0:
+		return ""
+	case 1:
+		return " " + m.results[0]
+	default:
+		return " (" + strings.Join(m.results, ", ") + ")"
+	}
+}
+
+// fixedParams は、可変長引数を除いたパラメータ名の一覧を返す。
+func (m *mockMethod) fixedParams() []string {
+	if m.variadic {
+		return m.params[:len(m.params)-1]
+	}
+	return m.params
+}
+
+func (g *mockGenerator) writeGomock(name, ifaceName string, methods []*mockMethod) {
+	gomock := g.imports.add(g.options.GomockPackage, "gomock")
+	mock := "Mock" + name
+	recorder := mock + "MockRecorder"
+	w := &g.body
+
+	fmt.Fprintf(w, "// %s is a mock of %s interface.\n", mock, name)
+	fmt.Fprintf(w, "type %s struct {\n\tctrl *%s.Controller\n\trecorder *%s\n}\n\n", mock, gomock, recorder)
+	fmt.Fprintf(w, "// %s is the mock recorder for %s.\n", recorder, mock)
+	fmt.Fprintf(w, "type %s struct {\n\tmock *%s\n}\n\n", recorder, mock)
+	fmt.Fprintf(w, "// New%s creates a new mock instance.\n", mock)
+	fmt.Fprintf(w, "func New%s(ctrl *%s.Controller) *%s {\n", mock, gomock, mock)
+	fmt.Fprintf(w, "\tmock := &%s{ctrl: ctrl}\n\tmock.recorder = &%s{mock}\n\treturn mock\n}\n\n", mock, recorder)
+	fmt.Fprintf(w, "// EXPECT returns an object that allows the caller to indicate expected use.\n")
+	fmt.Fprintf(w, "func (m *%s) EXPECT() *%s {\n\treturn m.recorder\n}\n\n", mock, recorder)
+	fmt.Fprintf(w, "var _ %s = (*%s)(nil)\n\n", ifaceName, mock)
+
+	for _, m := range methods {
+		fmt.Fprintf(w, "// %s mocks base method.\n", m.name)
+		fmt.Fprintf(w, "func (m *%s) %s%s {\n", mock, m.name, m.signature())
+		w.WriteString("\tm.ctrl.T.Helper()\n")
+		call := fmt.Sprintf("m.ctrl.Call(%s)", strings.Join(append([]string{"m", fmt.Sprintf("%q", m.name)}, m.params...), ", "))
+		if m.variadic {
+			last := m.params[len(m.params)-1]
+			fmt.Fprintf(w, "\tvarargs := []interface{}{%s}\n", strings.Join(m.fixedParams(), ", "))
+			fmt.Fprintf(w, "\tfor _, a := range %s {\n\t\tvarargs = append(varargs, a)\n\t}\n", last)
+			call = fmt.Sprintf("m.ctrl.Call(m, %q, varargs...)", m.name)
+		}
+		if len(m.results) == 0 {
+			fmt.Fprintf(w, "\t%s\n}\n\n", call)
+		} else {
+			fmt.Fprintf(w, "\tret := %s\n", call)
+			rets := make([]string, len(m.results))
+			for j, result := range m.results {
+				rets[j] = fmt.Sprintf("ret%d", j)
+				fmt.Fprintf(w, "\t%s, _ := ret[%d].(%s)\n", rets[j], j, result)
+			}
+			fmt.Fprintf(w, "\treturn %s\n}\n\n", strings.Join(rets, ", "))
+		}
+
+		reflect := g.imports.add("reflect", "reflect")
+		params := make([]string, len(m.params))
+		for j, p := range m.params {
+			params[j] = p + " interface{}"
+		}
+		if m.variadic {
+			params[len(params)-1] = m.params[len(m.params)-1] + " ...interface{}"
+		}
+		methodType := fmt.Sprintf("%s.TypeOf((*%s)(nil).%s)", reflect, mock, m.name)
+		fmt.Fprintf(w, "// %s indicates an expected call of %s.\n", m.name, m.name)
+		fmt.Fprintf(w, "func (mr *%s) %s(%s) *%s.Call {\n", recorder, m.name, strings.Join(params, ", "), gomock)
+		w.WriteString("\tmr.mock.ctrl.T.Helper()\n")
+		if m.variadic {
+			fmt.Fprintf(w, "\tvarargs := append([]interface{}{%s}, %s...)\n", strings.Join(m.fixedParams(), ", "), m.params[len(m.params)-1])
+			fmt.Fprintf(w, "\treturn mr.mock.ctrl.RecordCallWithMethodType(mr.mock, %q, %s, varargs...)\n}\n\n", m.name, methodType)
+		} else {
+			args := strings.Join(append([]string{"mr.mock", fmt.Sprintf("%q", m.name), methodType}, m.params...), ", ")
+			fmt.Fprintf(w, "\treturn mr.mock.ctrl.RecordCallWithMethodType(%s)\n}\n\n", args)
+		}
+	}
+}
+
+func (g *mockGenerator) writeFake(name, ifaceName string, methods []*mockMethod) {
+	fake := "Fake" + name
+	w := &g.body
+
+	fmt.Fprintf(w, "// %s is a fake implementation of %s whose methods call the corresponding function fields.\n", fake, name)
+	fmt.Fprintf(w, "type %s struct {\n", fake)
+	for _, m := range methods {
+		fmt.Fprintf(w, "\t%sFunc func%s\n", m.name, m.signature())
+	}
+	w.WriteString("}\n\n")
+	fmt.Fprintf(w, "var _ %s = (*%s)(nil)\n\n", ifaceName, fake)
+
+	for _, m := range methods {
+		args := append([]string{}, m.params...)
+		if m.variadic {
+			args[len(args)-1] += "..."
+		}
+		fmt.Fprintf(w, "// %s calls %sFunc.\n", m.name, m.name)
+		fmt.Fprintf(w, "func (f *%s) %s%s {\n", fake, m.name, m.signature())
+		fmt.Fprintf(w, "\tif f.%sFunc == nil {\n\t\tpanic(\"%s.%s: %sFunc is not set\")\n\t}\n", m.name, fake, m.name, m.name)
+		call := fmt.Sprintf("f.%sFunc(%s)", m.name, strings.Join(args, ", "))
+		if len(m.results) == 0 {
+			fmt.Fprintf(w, "\t%s\n}\n\n", call)
+		} else {
+			fmt.Fprintf(w, "\treturn %s\n}\n\n", call)
+		}
+	}
+}
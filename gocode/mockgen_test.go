@@ -0,0 +1,97 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+const mockgenTestSource = `package store
+
+import (
+	stdctx "context"
+	"io"
+)
+
+type Store interface {
+	Get(ctx stdctx.Context, key string) ([]byte, error)
+	Put(ctx stdctx.Context, key string, values ...[]byte)
+	io.Closer
+}
+
+type sealed interface {
+	seal()
+}
+`
+
+func TestRelations_GenerateMocks(t *testing.T) {
+	r := loadTestModule(t, map[string]string{"store/store.go": mockgenTestSource})
+	store, ok := r.Interfaces().Get("store", "Store")
+	if !ok {
+		t.Fatal("Store not found")
+	}
+
+	t.Run("gomock", func(t *testing.T) {
+		src, err := r.GenerateMocks([]*gocode.Interface{store}, &gocode.MockOptions{Style: gocode.MockStyleGomock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"package mock_store",
+			`gomock "github.com/golang/mock/gomock"`,
+			`stdctx "context"`,
+			`store "example.com/testmodule/store"`,
+			"var _ store.Store = (*MockStore)(nil)",
+			"func (m *MockStore) Get(arg0 stdctx.Context, arg1 string) ([]byte, error) {",
+			"ret0, _ := ret[0].([]byte)",
+			"func (m *MockStore) Put(arg0 stdctx.Context, arg1 string, arg2 ...[]byte) {",
+			"varargs := append([]interface{}{arg0, arg1}, arg2...)",
+			`return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStore)(nil).Close))`,
+		} {
+			if !strings.Contains(string(src), want) {
+				t.Errorf("generated source does not contain %q:\n%s", want, src)
+			}
+		}
+	})
+
+	t.Run("fake", func(t *testing.T) {
+		src, err := r.GenerateMocks([]*gocode.Interface{store}, &gocode.MockOptions{
+			Style:       gocode.MockStyleFake,
+			PackageName: "store",
+			PackagePath: "example.com/testmodule/store",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(string(src), "store.Store") {
+			t.Errorf("generated source in the same package qualifies Store:\n%s", src)
+		}
+
+		generated := loadTestModule(t, map[string]string{
+			"store/store.go": mockgenTestSource,
+			"store/fake.go":  string(src),
+		})
+		fake, ok := generated.Structs().Get("store", "FakeStore")
+		if !ok {
+			t.Fatalf("FakeStore not found in generated source:\n%s", src)
+		}
+		if !fake.ImplementInterfaces().Contains("store", "Store") {
+			t.Errorf("FakeStore does not implement store.Store:\n%s", src)
+		}
+	})
+
+	t.Run("unexported method", func(t *testing.T) {
+		sealed, ok := r.Interfaces().Get("store", "sealed")
+		if !ok {
+			t.Fatal("sealed not found")
+		}
+		if _, err := r.GenerateMocks([]*gocode.Interface{sealed}, nil); err == nil {
+			t.Error("GenerateMocks() expected error for interface with unexported method")
+		}
+		if _, err := r.GenerateMocks([]*gocode.Interface{sealed}, &gocode.MockOptions{PackagePath: "example.com/testmodule/store", PackageName: "store"}); err != nil {
+			t.Errorf("GenerateMocks() in the same package: %v", err)
+		}
+	})
+}
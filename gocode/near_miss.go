@@ -0,0 +1,293 @@
+package gocode
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+type (
+	// MethodDiffKind は、interfaceのメソッドとstructのメソッドの比較結果の種類を表す。
+	MethodDiffKind string
+
+	// NearMissOptions は、interfaceをほぼ実装しているstructの検出のオプション。
+	NearMissOptions struct {
+		// MaxDifferences は、一致しないメソッドがいくつまでであれば検出するか。0の場合は1となる。
+		// interfaceの全てのメソッドと名前が一致するメソッドがあれば、この値に関わらず検出する。
+		MaxDifferences int `yaml:"maxDifferences" json:"maxDifferences"`
+	}
+
+	// MethodDiff は、interfaceのメソッドとstructのメソッドの比較結果を表す。
+	MethodDiff struct {
+		kind            MethodDiffKind
+		interfaceMethod *Function
+		// method はstructの同名のメソッド。存在しなければ nil となる。
+		method *Function
+		// details はシグネチャの差分の説明の一覧。
+		details []string
+	}
+
+	// NearMiss は、structがinterfaceをほぼ実装しているが、一部のメソッドが不足または不一致であることを表す。
+	NearMiss struct {
+		structure *Struct
+		iface     *Interface
+		methods   []*MethodDiff
+	}
+)
+
+const (
+	MethodDiffMatched    MethodDiffKind = "matched"
+	MethodDiffMissing    MethodDiffKind = "missing"
+	MethodDiffMismatched MethodDiffKind = "mismatched"
+)
+
+func (k MethodDiffKind) String() string {
+	return string(k)
+}
+
+func (d *MethodDiff) Kind() MethodDiffKind {
+	return d.kind
+}
+
+// InterfaceMethod は、比較したinterfaceのメソッドを返す。
+func (d *MethodDiff) InterfaceMethod() *Function {
+	return d.interfaceMethod
+}
+
+// Method は、structの同名のメソッドを返す。存在しなければ ok は偽となる。
+func (d *MethodDiff) Method() (fn *Function, ok bool) {
+	return d.method, d.method != nil
+}
+
+// Want は、interfaceが要求するメソッドのシグネチャを返す。
+func (d *MethodDiff) Want() string {
+	return displaySignature(d.interfaceMethod)
+}
+
+// Have は、structのメソッドのシグネチャを返す。メソッドが存在しなければ空となる。
+func (d *MethodDiff) Have() string {
+	if d.method == nil {
+		return ""
+	}
+	return displaySignature(d.method)
+}
+
+// Details は、シグネチャの差分の説明の一覧を返す。
+func (d *MethodDiff) Details() []string {
+	return append([]string{}, d.details...)
+}
+
+func (nm *NearMiss) Struct() *Struct {
+	return nm.structure
+}
+
+func (nm *NearMiss) Interface() *Interface {
+	return nm.iface
+}
+
+// Methods は、interfaceの全てのメソッドの比較結果をinterfaceのメソッドの順に返す。
+func (nm *NearMiss) Methods() []*MethodDiff {
+	return append([]*MethodDiff{}, nm.methods...)
+}
+
+// Differences は、一致しなかったメソッドの比較結果の一覧を返す。
+func (nm *NearMiss) Differences() []*MethodDiff {
+	var diffs []*MethodDiff
+	for _, d := range nm.methods {
+		if d.kind != MethodDiffMatched {
+			diffs = append(diffs, d)
+		}
+	}
+	return diffs
+}
+
+// String は、一致しなかったメソッドごとの差分を含む複数行の説明を返す。
+func (nm *NearMiss) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s.%s nearly implements %s.%s (%d/%d methods match)",
+		nm.structure.PackageSummary().Path(), nm.structure.Name(),
+		nm.iface.PackageSummary().Path(), nm.iface.Name(),
+		len(nm.methods)-len(nm.Differences()), len(nm.methods))
+	for _, d := range nm.Differences() {
+		switch d.kind {
+		case MethodDiffMissing:
+			fmt.Fprintf(&b, "\n  - %s: missing, want %s", d.interfaceMethod.Name(), d.Want())
+		case MethodDiffMismatched:
+			fmt.Fprintf(&b, "\n  - %s: have %s, want %s", d.interfaceMethod.Name(), d.Have(), d.Want())
+			for _, detail := range d.details {
+				fmt.Fprintf(&b, "\n      %s", detail)
+			}
+		}
+	}
+	return b.String()
+}
+
+// NearMisses は、interfaceを実装していないが、一致しないメソッドが MaxDifferences 以下であるか、
+// 全てのメソッドの名前は一致するがシグネチャが異なるメソッドがあるstructとinterfaceの組を検出する。
+//
+// 一致するメソッドが1つもない組と、メソッドを持たないinterfaceは対象としない。
+// 非公開のメソッドを持つinterfaceは、同じパッケージのstructのみを対象とする。
+func (r *Relations) NearMisses(options *NearMissOptions) []*NearMiss {
+	maxDifferences := 1
+	if options != nil && options.MaxDifferences > 0 {
+		maxDifferences = options.MaxDifferences
+	}
+
+	var res []*NearMiss
+	for _, i := range r.Interfaces().InterfaceAll() {
+		if i.goInterface.NumMethods() == 0 {
+			continue
+		}
+		sealed := sealedInterface(i)
+		for _, s := range r.Structs().StructAll() {
+			if sealed && !s.PackageSummary().Equal(i.PackageSummary()) {
+				continue
+			}
+			if implementsBySignature(s.Type().GoType(), i.goInterface) {
+				continue
+			}
+			if nm, ok := newNearMiss(s, i, maxDifferences); ok {
+				res = append(res, nm)
+			}
+		}
+	}
+
+	sort.Slice(res, func(a, b int) bool {
+		sa, sb := res[a].structure, res[b].structure
+		if sa.PackageSummary().Path() != sb.PackageSummary().Path() {
+			return sa.PackageSummary().Path() < sb.PackageSummary().Path()
+		}
+		if sa.Name() != sb.Name() {
+			return sa.Name() < sb.Name()
+		}
+		ia, ib := res[a].iface, res[b].iface
+		if ia.PackageSummary().Path() != ib.PackageSummary().Path() {
+			return ia.PackageSummary().Path() < ib.PackageSummary().Path()
+		}
+		return ia.Name() < ib.Name()
+	})
+	return res
+}
+
+func newNearMiss(s *Struct, i *Interface, maxDifferences int) (*NearMiss, bool) {
+	nm := &NearMiss{structure: s, iface: i}
+	matched, mismatched := 0, 0
+	for _, im := range i.Methods() {
+		d := &MethodDiff{kind: MethodDiffMissing, interfaceMethod: im}
+		if f, ok := lookupMethod(s.Type().GoType(), im.Name().String()); ok {
+			if fn, ok := newFunctionIfSignatureType(f); ok {
+				d.method = fn
+				d.details = signatureDiff(fn.signature(), im.signature())
+				d.kind = MethodDiffMismatched
+				if len(d.details) == 0 {
+					d.kind = MethodDiffMatched
+				}
+			}
+		}
+		switch d.kind {
+		case MethodDiffMatched:
+			matched++
+		case MethodDiffMismatched:
+			mismatched++
+		}
+		nm.methods = append(nm.methods, d)
+	}
+
+	if matched == 0 && mismatched == 0 {
+		return nil, false
+	}
+	missing := len(nm.methods) - matched - mismatched
+	if missing == 0 || missing+mismatched <= maxDifferences {
+		return nm, true
+	}
+	return nil, false
+}
+
+// signatureDiff は、 have と want のシグネチャの差分の説明の一覧を返す。同一であれば空となる。
+func signatureDiff(have, want *types.Signature) []string {
+	if signatureKey(have) == signatureKey(want) {
+		return nil
+	}
+	details := tupleDiff("parameter", have.Params(), want.Params())
+	details = append(details, tupleDiff("result", have.Results(), want.Results())...)
+	if have.Variadic() != want.Variadic() {
+		if want.Variadic() {
+			details = append(details, "last parameter must be variadic")
+		} else {
+			details = append(details, "last parameter must not be variadic")
+		}
+	}
+	return details
+}
+
+// tupleDiff は、パラメータまたは戻り値の型の並びの差分の説明の一覧を返す。
+// 数が1つだけ異なり、それ以外が一致する場合は、不足または余分な要素として説明する。
+func tupleDiff(label string, have, want *types.Tuple) []string {
+	haveTypes, wantTypes := tupleTypeKeys(have), tupleTypeKeys(want)
+
+	switch {
+	case len(haveTypes)+1 == len(wantTypes):
+		if k, ok := singleInsertion(haveTypes, wantTypes); ok {
+			return []string{fmt.Sprintf("missing %s %d of type %s", label, k, DisplayType(want.At(k).Type()))}
+		}
+	case len(haveTypes) == len(wantTypes)+1:
+		if k, ok := singleInsertion(wantTypes, haveTypes); ok {
+			return []string{fmt.Sprintf("unexpected %s %d of type %s", label, k, DisplayType(have.At(k).Type()))}
+		}
+	case len(haveTypes) == len(wantTypes):
+		var details []string
+		for k := range haveTypes {
+			if haveTypes[k] != wantTypes[k] {
+				details = append(details, fmt.Sprintf("%s %d: have %s, want %s", label, k, DisplayType(have.At(k).Type()), DisplayType(want.At(k).Type())))
+			}
+		}
+		return details
+	}
+	return []string{fmt.Sprintf("have %d %ss, want %d", len(haveTypes), label, len(wantTypes))}
+}
+
+func tupleTypeKeys(t *types.Tuple) []string {
+	keys := make([]string, 0, t.Len())
+	for i := 0; i < t.Len(); i++ {
+		keys = append(keys, types.TypeString(t.At(i).Type(), nil))
+	}
+	return keys
+}
+
+// singleInsertion は、 shorter に1つの要素を挿入すると longer になる場合に、挿入する位置を返す。
+func singleInsertion(shorter, longer []string) (int, bool) {
+	for k := 0; k < len(longer); k++ {
+		ok := true
+		for j := 0; j < len(shorter); j++ {
+			l := j
+			if j >= k {
+				l = j + 1
+			}
+			if shorter[j] != longer[l] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// displaySignature は、パッケージ名で型を修飾した表示用のメソッドのシグネチャを返す。
+func displaySignature(fn *Function) string {
+	sig := fn.signature()
+	return fn.Name().String() + strings.TrimPrefix(types.TypeString(types.NewSignature(nil, sig.Params(), sig.Results(), sig.Variadic()), packageNameQualifier), "func")
+}
+
+// sealedInterface は、interfaceが非公開のメソッドを持つかを返す。
+func sealedInterface(i *Interface) bool {
+	for _, m := range i.Methods() {
+		if !m.Exported() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestRelations_NearMisses(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"repo/repo.go": `package repo
+
+import "context"
+
+type User struct{}
+
+type Repository interface {
+	Find(ctx context.Context, id string) (*User, error)
+	Save(ctx context.Context, u *User) error
+	Delete(ctx context.Context, id string) error
+}
+
+type memory struct{}
+
+func (m *memory) Find(id string) (*User, error)              { return nil, nil }
+func (m *memory) Save(ctx context.Context, u *User) error    { return nil }
+func (m *memory) Delete(ctx context.Context, id string) error { return nil }
+
+type partial struct{}
+
+func (p *partial) Find(ctx context.Context, id string) (*User, error) { return nil, nil }
+func (p *partial) Save(ctx context.Context, u *User) error           { return nil }
+
+type values struct{}
+
+func (v values) Find(ctx context.Context, id string) (User, error) { return User{}, nil }
+func (v values) Save(ctx context.Context, u User) error            { return nil }
+func (v values) Delete(ctx context.Context, id string) error       { return nil }
+
+type unrelated struct{}
+
+func (u *unrelated) Delete(id int) {}
+
+type complete struct {
+	partial
+}
+
+func (c *complete) Delete(ctx context.Context, id string) error { return nil }
+`,
+	})
+
+	var got []string
+	for _, nm := range r.NearMisses(nil) {
+		got = append(got, nm.String())
+	}
+	want := []string{
+		`example.com/testmodule/repo.memory nearly implements example.com/testmodule/repo.Repository (2/3 methods match)
+  - Find: have Find(id string) (*repo.User, error), want Find(ctx context.Context, id string) (*repo.User, error)
+      missing parameter 0 of type context.Context`,
+		`example.com/testmodule/repo.partial nearly implements example.com/testmodule/repo.Repository (2/3 methods match)
+  - Delete: missing, want Delete(ctx context.Context, id string) error`,
+		`example.com/testmodule/repo.values nearly implements example.com/testmodule/repo.Repository (1/3 methods match)
+  - Find: have Find(ctx context.Context, id string) (repo.User, error), want Find(ctx context.Context, id string) (*repo.User, error)
+      result 0: have repo.User, want *repo.User
+  - Save: have Save(ctx context.Context, u repo.User) error, want Save(ctx context.Context, u *repo.User) error
+      parameter 1: have repo.User, want *repo.User`,
+	}
+	if strings.Join(got, "\n\n") != strings.Join(want, "\n\n") {
+		t.Errorf("NearMisses() = \n%s\n\nwant\n%s", strings.Join(got, "\n\n"), strings.Join(want, "\n\n"))
+	}
+
+	if got := len(r.NearMisses(&gocode.NearMissOptions{MaxDifferences: 3})); got != 4 {
+		t.Errorf("NearMisses(MaxDifferences: 3) = %d, want 4", got)
+	}
+}
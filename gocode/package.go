@@ -1,6 +1,7 @@
 package gocode
 
 import (
+	"go/ast"
 	"go/types"
 
 	"golang.org/x/tools/go/analysis"
@@ -32,6 +33,8 @@ type (
 		typeAliases *TypeAliasList
 		// definedTypes はパッケージ内の defined type の一覧。
 		definedTypes *DefinedTypeList
+		// functions はパッケージ内の関数の一覧。
+		functions *FunctionList
 	}
 
 	// Package はパッケージ情報を表す。
@@ -40,15 +43,24 @@ type (
 		summary *PackageSummary
 		// detail はパッケージ内の詳細情報。
 		detail *PackageDetail
+		// goPackage は解析元の types.Package 。
+		goPackage *types.Package
+		// files は解析元の構文木の一覧。
+		files []*ast.File
+		// typesInfo は解析元の型情報。
+		typesInfo *types.Info
 	}
 
 	packageIn interface {
+		Types() *types.Package
 		PkgPath() string
 		PkgName() string
 		Import() []*types.Package
 		Defs() []types.Object
 		Scope() *types.Scope
 		Typed() []types.Object
+		Files() []*ast.File
+		TypesInfo() *types.Info
 	}
 
 	packageInPackagesPackage struct {
@@ -101,6 +113,7 @@ func newPackageDetail(pkg packageIn) *PackageDetail {
 		interfaces:   newInterfaceList(pkg),
 		typeAliases:  newAliasList(pkg),
 		definedTypes: newDefinedList(pkg),
+		functions:    newFunctionListFromPackage(pkg),
 	}
 }
 
@@ -124,17 +137,21 @@ func (pd *PackageDetail) DefinedTypes() []*DefinedType {
 	return pd.definedTypes.asSlice()
 }
 
+// Functions は、パッケージに宣言された関数の一覧を返す。メソッドは含まない。
+func (pd *PackageDetail) Functions() []*Function {
+	return pd.functions.asSlice()
+}
+
 func newPackage(pkg packageIn) *Package {
 	return &Package{
-		summary: newPackageSummary(pkg),
-		detail:  newPackageDetail(pkg),
+		summary:   newPackageSummary(pkg),
+		detail:    newPackageDetail(pkg),
+		goPackage: pkg.Types(),
+		files:     pkg.Files(),
+		typesInfo: pkg.TypesInfo(),
 	}
 }
 
-func newPackageFromPackages(pkg *packages.Package) *Package {
-	return newPackage(newPackageInPackages(pkg))
-}
-
 func newPackageFromAnalysis(pass *analysis.Pass) *Package {
 	return newPackage(newPackageInAnalysis(pass))
 }
@@ -147,12 +164,20 @@ func (p *Package) Detail() *PackageDetail {
 	return p.detail
 }
 
+func (p *Package) GoPackage() *types.Package {
+	return p.goPackage
+}
+
 func newPackageInPackages(pkg *packages.Package) packageIn {
 	return &packageInPackagesPackage{
 		pkg: pkg,
 	}
 }
 
+func (p *packageInPackagesPackage) Types() *types.Package {
+	return p.pkg.Types
+}
+
 func (p *packageInPackagesPackage) PkgPath() string {
 	return p.pkg.PkgPath
 }
@@ -185,12 +210,24 @@ func (p *packageInPackagesPackage) Typed() []types.Object {
 	return lookupTyped(p.pkg.Types.Scope(), p.pkg.TypesInfo)
 }
 
+func (p *packageInPackagesPackage) Files() []*ast.File {
+	return p.pkg.Syntax
+}
+
+func (p *packageInPackagesPackage) TypesInfo() *types.Info {
+	return p.pkg.TypesInfo
+}
+
 func newPackageInAnalysis(pass *analysis.Pass) packageIn {
 	return &packageInAnalysisPass{
 		pass: pass,
 	}
 }
 
+func (p *packageInAnalysisPass) Types() *types.Package {
+	return p.pass.Pkg
+}
+
 func (p *packageInAnalysisPass) PkgPath() string {
 	return p.pass.Pkg.Path()
 }
@@ -219,6 +256,14 @@ func (p *packageInAnalysisPass) Typed() []types.Object {
 	return lookupTyped(p.pass.Pkg.Scope(), p.pass.TypesInfo)
 }
 
+func (p *packageInAnalysisPass) Files() []*ast.File {
+	return p.pass.Files
+}
+
+func (p *packageInAnalysisPass) TypesInfo() *types.Info {
+	return p.pass.TypesInfo
+}
+
 func lookupTyped(scope *types.Scope, info *types.Info) []types.Object {
 	// 変数と定数(var, const)を取得
 	varAndConstNames := make(map[string]struct{}, 0)
@@ -0,0 +1,206 @@
+package gocode
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+type (
+	// PackageMetrics は、Robert C. Martin のパッケージメトリクスを表す。
+	//
+	// 結合度は解析したパッケージ間の依存のみを数える。標準ライブラリなど解析対象外のパッケージへの依存は含まない。
+	PackageMetrics struct {
+		pkgSummary *PackageSummary
+		// afferent はこのパッケージをimportしているパッケージの数(Ca)。
+		afferent int
+		// efferent はこのパッケージがimportしているパッケージの数(Ce)。
+		efferent int
+		// abstractTypes はパッケージ内のinterfaceの数。
+		abstractTypes int
+		// concreteTypes はパッケージ内のstructとdefined typeの数。
+		concreteTypes int
+	}
+
+	// PackageMetricsThresholds は、パッケージメトリクスの閾値。0の項目は検査しない。
+	PackageMetricsThresholds struct {
+		// MaxDistance は、主系列からの距離(D)の上限。
+		MaxDistance float64 `yaml:"maxDistance" json:"maxDistance"`
+		// MaxInstability は、不安定度(I)の上限。
+		MaxInstability float64 `yaml:"maxInstability" json:"maxInstability"`
+		// MaxEfferent は、遠心性結合(Ce)の上限。
+		MaxEfferent int `yaml:"maxEfferent" json:"maxEfferent"`
+		// MaxAfferent は、求心性結合(Ca)の上限。
+		MaxAfferent int `yaml:"maxAfferent" json:"maxAfferent"`
+	}
+
+	// PackageMetricsViolation は、パッケージメトリクスが閾値を超えたことを表す。
+	PackageMetricsViolation struct {
+		metrics   *PackageMetrics
+		metric    string
+		value     float64
+		threshold float64
+	}
+)
+
+// PackageMetrics は、解析したパッケージごとのメトリクスをパッケージパス順で返す。
+func (r *Relations) PackageMetrics() []*PackageMetrics {
+	metrics := make(map[PackagePath]*PackageMetrics)
+	for _, pkg := range r.Packages().AsSlice() {
+		detail := pkg.Detail()
+		metrics[pkg.Summary().Path()] = &PackageMetrics{
+			pkgSummary:    pkg.Summary(),
+			abstractTypes: len(detail.Interfaces()),
+			concreteTypes: len(detail.Structs()) + len(detail.DefinedTypes()),
+		}
+	}
+
+	graph := r.PackageGraph()
+	for _, path := range graph.SortedPackagePaths() {
+		imported := make(map[PackagePath]struct{})
+		for _, ps := range graph.SortedImportPackagePaths(path) {
+			if _, ok := imported[ps.Path()]; ok || ps.Path() == path {
+				continue
+			}
+			imported[ps.Path()] = struct{}{}
+			metrics[path].efferent++
+			if m, ok := metrics[ps.Path()]; ok {
+				m.afferent++
+			}
+		}
+	}
+
+	res := make([]*PackageMetrics, 0, len(metrics))
+	for _, m := range metrics {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].pkgSummary.Path() < res[j].pkgSummary.Path()
+	})
+	return res
+}
+
+func (m *PackageMetrics) PackageSummary() *PackageSummary {
+	return m.pkgSummary
+}
+
+// Afferent は、このパッケージをimportしているパッケージの数(Ca)を返す。
+func (m *PackageMetrics) Afferent() int {
+	return m.afferent
+}
+
+// Efferent は、このパッケージがimportしているパッケージの数(Ce)を返す。
+func (m *PackageMetrics) Efferent() int {
+	return m.efferent
+}
+
+// Instability は、不安定度 I = Ce / (Ca + Ce) を返す。依存がなければ0となる。
+func (m *PackageMetrics) Instability() float64 {
+	if m.afferent+m.efferent == 0 {
+		return 0
+	}
+	return float64(m.efferent) / float64(m.afferent+m.efferent)
+}
+
+// Abstractness は、抽象度 A = interfaceの数 / 型の数 を返す。型がなければ0となる。
+// 型の数はinterface、struct、defined typeの数の合計で、type aliasは含まない。
+func (m *PackageMetrics) Abstractness() float64 {
+	total := m.abstractTypes + m.concreteTypes
+	if total == 0 {
+		return 0
+	}
+	return float64(m.abstractTypes) / float64(total)
+}
+
+// Distance は、主系列からの距離 D = |A + I - 1| を返す。
+func (m *PackageMetrics) Distance() float64 {
+	return math.Abs(m.Abstractness() + m.Instability() - 1)
+}
+
+// Check は、メトリクスが thresholds を超えている項目の一覧を返す。
+func (m *PackageMetrics) Check(thresholds *PackageMetricsThresholds) []*PackageMetricsViolation {
+	var violations []*PackageMetricsViolation
+	add := func(metric string, value, threshold float64) {
+		if threshold > 0 && value > threshold {
+			violations = append(violations, &PackageMetricsViolation{metrics: m, metric: metric, value: value, threshold: threshold})
+		}
+	}
+	add("distance", m.Distance(), thresholds.MaxDistance)
+	add("instability", m.Instability(), thresholds.MaxInstability)
+	add("efferent coupling", float64(m.efferent), float64(thresholds.MaxEfferent))
+	add("afferent coupling", float64(m.afferent), float64(thresholds.MaxAfferent))
+	return violations
+}
+
+// CheckPackageMetrics は、 metrics のうち thresholds を超えている項目の一覧を返す。
+func CheckPackageMetrics(metrics []*PackageMetrics, thresholds *PackageMetricsThresholds) []*PackageMetricsViolation {
+	var violations []*PackageMetricsViolation
+	for _, m := range metrics {
+		violations = append(violations, m.Check(thresholds)...)
+	}
+	return violations
+}
+
+func (v *PackageMetricsViolation) Metrics() *PackageMetrics {
+	return v.metrics
+}
+
+// Metric は、閾値を超えたメトリクスの名前を返す。
+func (v *PackageMetricsViolation) Metric() string {
+	return v.metric
+}
+
+func (v *PackageMetricsViolation) Value() float64 {
+	return v.value
+}
+
+func (v *PackageMetricsViolation) Threshold() float64 {
+	return v.threshold
+}
+
+func (v *PackageMetricsViolation) String() string {
+	return fmt.Sprintf("%s: %s %s exceeds %s", v.metrics.pkgSummary.Path(), v.metric, formatMetric(v.value), formatMetric(v.threshold))
+}
+
+// WritePackageMetricsTable は、 metrics を表形式で w に書き出す。
+func WritePackageMetricsTable(w io.Writer, metrics []*PackageMetrics) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tCA\tCE\tI\tA\tD")
+	for _, m := range metrics {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f\t%.2f\t%.2f\n",
+			m.pkgSummary.Path(), m.afferent, m.efferent, m.Instability(), m.Abstractness(), m.Distance())
+	}
+	return tw.Flush()
+}
+
+// WritePackageMetricsCSV は、 metrics をヘッダ付きのCSVで w に書き出す。
+func WritePackageMetricsCSV(w io.Writer, metrics []*PackageMetrics) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"package", "ca", "ce", "instability", "abstractness", "distance"}); err != nil {
+		return err
+	}
+	for _, m := range metrics {
+		record := []string{
+			m.pkgSummary.Path().String(),
+			strconv.Itoa(m.afferent),
+			strconv.Itoa(m.efferent),
+			formatMetric(m.Instability()),
+			formatMetric(m.Abstractness()),
+			formatMetric(m.Distance()),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatMetric は、メトリクスの値を小数点以下3桁に丸めた文字列に変換する。
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
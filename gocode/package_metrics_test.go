@@ -0,0 +1,97 @@
+package gocode_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func loadMetricsTestModule(t *testing.T) *gocode.Relations {
+	t.Helper()
+	return loadTestModule(t, map[string]string{
+		"domain/domain.go": `package domain
+
+type Repository interface {
+	Find(id string) (*User, error)
+}
+
+type Notifier interface {
+	Notify(u *User) error
+}
+
+type User struct{}
+`,
+		"infra/infra.go": `package infra
+
+import (
+	"fmt"
+
+	"example.com/testmodule/domain"
+)
+
+type DB struct{}
+
+func (d *DB) Find(id string) (*domain.User, error) { return nil, fmt.Errorf("not found") }
+`,
+		"app/app.go": `package app
+
+import (
+	"example.com/testmodule/domain"
+	"example.com/testmodule/infra"
+)
+
+type Service struct {
+	repo domain.Repository
+}
+
+func New() *Service { return &Service{repo: &infra.DB{}} }
+`,
+	})
+}
+
+func TestRelations_PackageMetrics(t *testing.T) {
+	r := loadMetricsTestModule(t)
+
+	var buf bytes.Buffer
+	if err := gocode.WritePackageMetricsCSV(&buf, r.PackageMetrics()); err != nil {
+		t.Fatal(err)
+	}
+	want := `package,ca,ce,instability,abstractness,distance
+example.com/testmodule/app,0,2,1,0,0
+example.com/testmodule/domain,2,0,0,0.667,0.333
+example.com/testmodule/infra,1,1,0.5,0,0.5
+`
+	if buf.String() != want {
+		t.Errorf("WritePackageMetricsCSV() = \n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := gocode.WritePackageMetricsTable(&buf, r.PackageMetrics()); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[0], "PACKAGE") {
+		t.Errorf("WritePackageMetricsTable() = \n%s", buf.String())
+	}
+}
+
+func TestCheckPackageMetrics(t *testing.T) {
+	r := loadMetricsTestModule(t)
+
+	violations := gocode.CheckPackageMetrics(r.PackageMetrics(), &gocode.PackageMetricsThresholds{
+		MaxDistance: 0.4,
+		MaxEfferent: 1,
+	})
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.String())
+	}
+	want := []string{
+		"example.com/testmodule/app: efferent coupling 2 exceeds 1",
+		"example.com/testmodule/infra: distance 0.5 exceeds 0.4",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckPackageMetrics() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,642 @@
+package gocode
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	// QueryTarget は、クエリで検索する要素の種類を表す。
+	QueryTarget string
+
+	// Query は、 Relations に対するクエリを表す。
+	//
+	// クエリは次の構文で記述する。
+	//
+	//	query     = target [ "where" expr ]
+	//	target    = "structs" | "interfaces" | "fields" | "methods" | "definedtypes" | "aliases"
+	//	expr      = and { "or" and }
+	//	and       = unary { "and" unary }
+	//	unary     = "not" unary | "(" expr ")" | predicate
+	//	predicate = attribute [ [ "=" | "!=" | "~" | "!~" ] string ]
+	//
+	// 演算子を省略した場合、 attribute の後に文字列があれば "=" とみなし、文字列もなければ真偽値の属性として評価する。
+	// "~" と "!~" は文字列を正規表現として扱う。
+	Query struct {
+		target QueryTarget
+		expr   queryExpr
+	}
+
+	// QueryResult は、クエリの実行結果を表す。
+	// Target に対応するメソッドのみが結果を返す。
+	QueryResult struct {
+		target       QueryTarget
+		structs      []*Struct
+		interfaces   []*Interface
+		fields       []*StructField
+		methods      []*TypeMethod
+		definedTypes []*DefinedType
+		typeAliases  []*TypeAlias
+	}
+
+	// queryAttribute は、クエリで参照可能な要素の属性を表す。
+	queryAttribute struct {
+		// values は、文字列として比較する属性の値の一覧を返す。
+		values func(r *Relations, item interface{}) []string
+		// test は、真偽値の属性を評価する。
+		test func(r *Relations, item interface{}) bool
+		// relation は、引数の文字列との関係を評価する。 values より優先される。
+		relation func(r *Relations, item interface{}, arg string) bool
+	}
+
+	queryExpr interface {
+		eval(r *Relations, item interface{}) bool
+	}
+
+	queryOr struct {
+		left, right queryExpr
+	}
+
+	queryAnd struct {
+		left, right queryExpr
+	}
+
+	queryNot struct {
+		expr queryExpr
+	}
+
+	queryAlways struct{}
+
+	queryPredicate struct {
+		attribute *queryAttribute
+		operator  string
+		arg       string
+		pattern   *regexp.Regexp
+	}
+
+	queryTokenKind int
+
+	queryToken struct {
+		kind   queryTokenKind
+		text   string
+		offset int
+	}
+
+	queryParser struct {
+		tokens []*queryToken
+		pos    int
+		attrs  map[string]*queryAttribute
+	}
+)
+
+const (
+	QueryTargetStructs      QueryTarget = "structs"
+	QueryTargetInterfaces   QueryTarget = "interfaces"
+	QueryTargetFields       QueryTarget = "fields"
+	QueryTargetMethods      QueryTarget = "methods"
+	QueryTargetDefinedTypes QueryTarget = "definedtypes"
+	QueryTargetTypeAliases  QueryTarget = "aliases"
+)
+
+const (
+	queryTokenEOF queryTokenKind = iota
+	queryTokenIdent
+	queryTokenString
+	queryTokenOperator
+	queryTokenLParen
+	queryTokenRParen
+)
+
+func (qt QueryTarget) String() string {
+	return string(qt)
+}
+
+// CompileQuery は、クエリ文字列を解析して Query を返す。
+func CompileQuery(q string) (*Query, error) {
+	tokens, err := tokenizeQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	target := p.next()
+	if target.kind != queryTokenIdent {
+		return nil, p.errorf(target, "expected query target")
+	}
+	attrs, ok := queryAttributes[QueryTarget(target.text)]
+	if !ok {
+		return nil, p.errorf(target, "unknown query target %q", target.text)
+	}
+	p.attrs = attrs
+
+	query := &Query{target: QueryTarget(target.text), expr: queryAlways{}}
+	if t := p.next(); t.kind != queryTokenEOF {
+		if t.kind != queryTokenIdent || t.text != "where" {
+			return nil, p.errorf(t, "expected \"where\"")
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != queryTokenEOF {
+			return nil, p.errorf(t, "unexpected %q", t.text)
+		}
+		query.expr = expr
+	}
+	return query, nil
+}
+
+func (q *Query) Target() QueryTarget {
+	return q.target
+}
+
+// Evaluate は、 r に対してクエリを実行する。
+func (q *Query) Evaluate(r *Relations) *QueryResult {
+	res := &QueryResult{target: q.target}
+	switch q.target {
+	case QueryTargetStructs:
+		for _, s := range r.Structs().StructAll() {
+			if q.expr.eval(r, s) {
+				res.structs = append(res.structs, s)
+			}
+		}
+		sort.Slice(res.structs, func(i, j int) bool {
+			return queryLess(res.structs[i].PackageSummary(), res.structs[i].Name().String(), res.structs[j].PackageSummary(), res.structs[j].Name().String())
+		})
+	case QueryTargetInterfaces:
+		for _, i := range r.Interfaces().InterfaceAll() {
+			if q.expr.eval(r, i) {
+				res.interfaces = append(res.interfaces, i)
+			}
+		}
+		sort.Slice(res.interfaces, func(i, j int) bool {
+			return queryLess(res.interfaces[i].PackageSummary(), res.interfaces[i].Name().String(), res.interfaces[j].PackageSummary(), res.interfaces[j].Name().String())
+		})
+	case QueryTargetFields:
+		for _, s := range r.Structs().StructAll() {
+			for _, f := range s.Fields() {
+				if sf := newStructField(s, f); q.expr.eval(r, sf) {
+					res.fields = append(res.fields, sf)
+				}
+			}
+		}
+		sort.Slice(res.fields, func(i, j int) bool {
+			return res.fields[i].String() < res.fields[j].String()
+		})
+	case QueryTargetMethods:
+		for _, s := range r.Structs().StructAll() {
+			for _, m := range s.Methods() {
+				if tm := newTypeMethod(s.Type(), m); q.expr.eval(r, tm) {
+					res.methods = append(res.methods, tm)
+				}
+			}
+		}
+		for _, dt := range r.DefinedTypes().DefinedTypeAll() {
+			for _, m := range dt.Methods() {
+				if tm := newTypeMethod(dt.Type(), m); q.expr.eval(r, tm) {
+					res.methods = append(res.methods, tm)
+				}
+			}
+		}
+		sort.Slice(res.methods, func(i, j int) bool {
+			return res.methods[i].String() < res.methods[j].String()
+		})
+	case QueryTargetDefinedTypes:
+		for _, dt := range r.DefinedTypes().DefinedTypeAll() {
+			if q.expr.eval(r, dt) {
+				res.definedTypes = append(res.definedTypes, dt)
+			}
+		}
+		sort.Slice(res.definedTypes, func(i, j int) bool {
+			return queryLess(res.definedTypes[i].PackageSummary(), res.definedTypes[i].Name().String(), res.definedTypes[j].PackageSummary(), res.definedTypes[j].Name().String())
+		})
+	case QueryTargetTypeAliases:
+		for _, a := range r.TypeAliases().AliasAll() {
+			if q.expr.eval(r, a) {
+				res.typeAliases = append(res.typeAliases, a)
+			}
+		}
+		sort.Slice(res.typeAliases, func(i, j int) bool {
+			return queryLess(res.typeAliases[i].PackageSummary(), res.typeAliases[i].Name().String(), res.typeAliases[j].PackageSummary(), res.typeAliases[j].Name().String())
+		})
+	}
+	return res
+}
+
+// Query は、クエリ文字列を解析して実行する。
+func (r *Relations) Query(q string) (*QueryResult, error) {
+	query, err := CompileQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	return query.Evaluate(r), nil
+}
+
+func (qr *QueryResult) Target() QueryTarget {
+	return qr.target
+}
+
+// Len は、結果の件数を返す。
+func (qr *QueryResult) Len() int {
+	return len(qr.structs) + len(qr.interfaces) + len(qr.fields) + len(qr.methods) + len(qr.definedTypes) + len(qr.typeAliases)
+}
+
+func (qr *QueryResult) Structs() []*Struct {
+	return append([]*Struct{}, qr.structs...)
+}
+
+func (qr *QueryResult) Interfaces() []*Interface {
+	return append([]*Interface{}, qr.interfaces...)
+}
+
+func (qr *QueryResult) Fields() []*StructField {
+	return append([]*StructField{}, qr.fields...)
+}
+
+func (qr *QueryResult) Methods() []*TypeMethod {
+	return append([]*TypeMethod{}, qr.methods...)
+}
+
+func (qr *QueryResult) DefinedTypes() []*DefinedType {
+	return append([]*DefinedType{}, qr.definedTypes...)
+}
+
+func (qr *QueryResult) TypeAliases() []*TypeAlias {
+	return append([]*TypeAlias{}, qr.typeAliases...)
+}
+
+// Strings は、結果の各要素をパッケージパス付きの名前で返す。
+func (qr *QueryResult) Strings() []string {
+	var res []string
+	for _, s := range qr.structs {
+		res = append(res, fmt.Sprintf("%s.%s", s.PackageSummary().Path(), s.Name()))
+	}
+	for _, i := range qr.interfaces {
+		res = append(res, fmt.Sprintf("%s.%s", i.PackageSummary().Path(), i.Name()))
+	}
+	for _, f := range qr.fields {
+		res = append(res, f.String())
+	}
+	for _, m := range qr.methods {
+		res = append(res, m.String())
+	}
+	for _, dt := range qr.definedTypes {
+		res = append(res, fmt.Sprintf("%s.%s", dt.PackageSummary().Path(), dt.Name()))
+	}
+	for _, a := range qr.typeAliases {
+		res = append(res, fmt.Sprintf("%s.%s", a.PackageSummary().Path(), a.Name()))
+	}
+	return res
+}
+
+func queryLess(ps1 *PackageSummary, name1 string, ps2 *PackageSummary, name2 string) bool {
+	if ps1.Path() != ps2.Path() {
+		return ps1.Path() < ps2.Path()
+	}
+	return name1 < name2
+}
+
+func (e queryOr) eval(r *Relations, item interface{}) bool {
+	return e.left.eval(r, item) || e.right.eval(r, item)
+}
+
+func (e queryAnd) eval(r *Relations, item interface{}) bool {
+	return e.left.eval(r, item) && e.right.eval(r, item)
+}
+
+func (e queryNot) eval(r *Relations, item interface{}) bool {
+	return !e.expr.eval(r, item)
+}
+
+func (queryAlways) eval(*Relations, interface{}) bool {
+	return true
+}
+
+func (e *queryPredicate) eval(r *Relations, item interface{}) bool {
+	if e.operator == "" {
+		return e.attribute.test(r, item)
+	}
+	if e.attribute.relation != nil {
+		ok := e.attribute.relation(r, item, e.arg)
+		if e.operator == "!=" {
+			return !ok
+		}
+		return ok
+	}
+
+	matched := false
+	for _, v := range e.attribute.values(r, item) {
+		if e.pattern != nil {
+			matched = e.pattern.MatchString(v)
+		} else {
+			matched = v == e.arg
+		}
+		if matched {
+			break
+		}
+	}
+	if e.operator == "!=" || e.operator == "!~" {
+		return !matched
+	}
+	return matched
+}
+
+func (p *queryParser) next() *queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != queryTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) peek() *queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == queryTokenIdent && t.text == keyword
+}
+
+func (p *queryParser) errorf(t *queryToken, format string, args ...interface{}) error {
+	return fmt.Errorf("query: offset %d: %s", t.offset, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	t := p.next()
+	switch {
+	case t.kind == queryTokenIdent && t.text == "not":
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{expr: expr}, nil
+	case t.kind == queryTokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != queryTokenRParen {
+			return nil, p.errorf(closing, "expected \")\"")
+		}
+		return expr, nil
+	case t.kind == queryTokenIdent:
+		return p.parsePredicate(t)
+	default:
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+}
+
+func (p *queryParser) parsePredicate(name *queryToken) (queryExpr, error) {
+	attr, ok := p.attrs[name.text]
+	if !ok {
+		return nil, p.errorf(name, "unknown attribute %q", name.text)
+	}
+
+	pred := &queryPredicate{attribute: attr}
+	switch p.peek().kind {
+	case queryTokenOperator:
+		pred.operator = p.next().text
+	case queryTokenString:
+		pred.operator = "="
+	default:
+		if attr.test == nil {
+			return nil, p.errorf(p.peek(), "attribute %q requires a value", name.text)
+		}
+		return pred, nil
+	}
+
+	arg := p.next()
+	if arg.kind != queryTokenString {
+		return nil, p.errorf(arg, "expected string after %q", pred.operator)
+	}
+	if attr.values == nil && attr.relation == nil {
+		return nil, p.errorf(name, "attribute %q cannot be compared", name.text)
+	}
+	pred.arg = arg.text
+
+	if pred.operator == "~" || pred.operator == "!~" {
+		if attr.relation != nil {
+			return nil, p.errorf(name, "attribute %q does not support %q", name.text, pred.operator)
+		}
+		pattern, err := regexp.Compile(arg.text)
+		if err != nil {
+			return nil, p.errorf(arg, "invalid regular expression: %v", err)
+		}
+		pred.pattern = pattern
+	}
+	return pred, nil
+}
+
+func tokenizeQuery(q string) ([]*queryToken, error) {
+	var tokens []*queryToken
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, &queryToken{kind: queryTokenLParen, text: "(", offset: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, &queryToken{kind: queryTokenRParen, text: ")", offset: i})
+			i++
+		case c == '"' || c == '`':
+			quoted, err := strconv.QuotedPrefix(q[i:])
+			if err != nil {
+				return nil, fmt.Errorf("query: offset %d: unterminated string", i)
+			}
+			text, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("query: offset %d: %v", i, err)
+			}
+			tokens = append(tokens, &queryToken{kind: queryTokenString, text: text, offset: i})
+			i += len(quoted)
+		case strings.HasPrefix(q[i:], "!=") || strings.HasPrefix(q[i:], "!~") || strings.HasPrefix(q[i:], "=="):
+			op := q[i : i+2]
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, &queryToken{kind: queryTokenOperator, text: op, offset: i})
+			i += 2
+		case c == '=' || c == '~':
+			tokens = append(tokens, &queryToken{kind: queryTokenOperator, text: string(c), offset: i})
+			i++
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(q) && (q[i] == '_' || unicode.IsLetter(rune(q[i])) || unicode.IsDigit(rune(q[i]))) {
+				i++
+			}
+			tokens = append(tokens, &queryToken{kind: queryTokenIdent, text: q[start:i], offset: start})
+		default:
+			return nil, fmt.Errorf("query: offset %d: unexpected character %q", i, c)
+		}
+	}
+	return append(tokens, &queryToken{kind: queryTokenEOF, text: "EOF", offset: len(q)}), nil
+}
+
+// queryAttributes は、クエリの対象ごとに参照可能な属性の一覧。
+var queryAttributes = map[QueryTarget]map[string]*queryAttribute{
+	QueryTargetStructs: {
+		"name":     {values: func(_ *Relations, item interface{}) []string { return []string{item.(*Struct).Name().String()} }},
+		"package":  {values: func(_ *Relations, item interface{}) []string { return packageValues(item.(*Struct).PackageSummary()) }},
+		"exported": {test: func(_ *Relations, item interface{}) bool { return token.IsExported(item.(*Struct).Name().String()) }},
+		"field":    {values: func(_ *Relations, item interface{}) []string { return fieldNames(item.(*Struct).Fields()) }},
+		"method":   {values: func(_ *Relations, item interface{}) []string { return functionNames(item.(*Struct).Methods()) }},
+		"implements": {relation: func(r *Relations, item interface{}, arg string) bool {
+			return queryImplements(r, item.(*Struct).Type(), arg)
+		}},
+	},
+	QueryTargetInterfaces: {
+		"name": {values: func(_ *Relations, item interface{}) []string { return []string{item.(*Interface).Name().String()} }},
+		"package": {values: func(_ *Relations, item interface{}) []string {
+			return packageValues(item.(*Interface).PackageSummary())
+		}},
+		"exported": {test: func(_ *Relations, item interface{}) bool { return token.IsExported(item.(*Interface).Name().String()) }},
+		"method":   {values: func(_ *Relations, item interface{}) []string { return functionNames(item.(*Interface).Methods()) }},
+		"embeds": {values: func(_ *Relations, item interface{}) []string {
+			var values []string
+			for _, e := range item.(*Interface).Embeds() {
+				values = append(values, typeValues(e.Type())...)
+			}
+			return values
+		}},
+	},
+	QueryTargetFields: {
+		"name": {values: func(_ *Relations, item interface{}) []string {
+			return []string{item.(*StructField).Field().Name().String()}
+		}},
+		"type": {values: func(_ *Relations, item interface{}) []string { return typeValues(item.(*StructField).Field().Type()) }},
+		"struct": {values: func(_ *Relations, item interface{}) []string {
+			return []string{item.(*StructField).Struct().Name().String()}
+		}},
+		"package": {values: func(_ *Relations, item interface{}) []string {
+			return packageValues(item.(*StructField).Struct().PackageSummary())
+		}},
+		"exported": {test: func(_ *Relations, item interface{}) bool { return item.(*StructField).Field().Exported() }},
+		"embedded": {test: func(_ *Relations, item interface{}) bool { return item.(*StructField).Field().Embedded() }},
+	},
+	QueryTargetMethods: {
+		"name": {values: func(_ *Relations, item interface{}) []string {
+			return []string{item.(*TypeMethod).Method().Name().String()}
+		}},
+		"receiver": {values: func(_ *Relations, item interface{}) []string {
+			return []string{item.(*TypeMethod).Receiver().TypeName().String()}
+		}},
+		"package": {values: func(_ *Relations, item interface{}) []string {
+			return packageValues(item.(*TypeMethod).Receiver().PackageSummary())
+		}},
+		"exported": {test: func(_ *Relations, item interface{}) bool { return item.(*TypeMethod).Method().Exported() }},
+		"pointer":  {test: func(_ *Relations, item interface{}) bool { return item.(*TypeMethod).Method().PointerReceiver() }},
+	},
+	QueryTargetDefinedTypes: {
+		"name": {values: func(_ *Relations, item interface{}) []string { return []string{item.(*DefinedType).Name().String()} }},
+		"package": {values: func(_ *Relations, item interface{}) []string {
+			return packageValues(item.(*DefinedType).PackageSummary())
+		}},
+		"exported": {test: func(_ *Relations, item interface{}) bool {
+			return token.IsExported(item.(*DefinedType).Name().String())
+		}},
+		"underlying": {values: func(_ *Relations, item interface{}) []string { return typeValues(item.(*DefinedType).UnderlyingType()) }},
+		"method":     {values: func(_ *Relations, item interface{}) []string { return functionNames(item.(*DefinedType).Methods()) }},
+		"implements": {relation: func(r *Relations, item interface{}, arg string) bool {
+			return queryImplements(r, item.(*DefinedType).Type(), arg)
+		}},
+	},
+	QueryTargetTypeAliases: {
+		"name": {values: func(_ *Relations, item interface{}) []string { return []string{item.(*TypeAlias).Name().String()} }},
+		"package": {values: func(_ *Relations, item interface{}) []string {
+			return packageValues(item.(*TypeAlias).PackageSummary())
+		}},
+		"exported": {test: func(_ *Relations, item interface{}) bool { return token.IsExported(item.(*TypeAlias).Name().String()) }},
+		"type":     {values: func(_ *Relations, item interface{}) []string { return typeValues(item.(*TypeAlias).Type()) }},
+	},
+}
+
+func packageValues(ps *PackageSummary) []string {
+	return []string{ps.Path().String()}
+}
+
+// typeValues は、型をパッケージ名付きとパッケージパス付きの両方の形式で返す。
+func typeValues(t *Type) []string {
+	qualifiedByName := types.TypeString(t.GoType(), func(p *types.Package) string { return p.Name() })
+	qualifiedByPath := types.TypeString(t.GoType(), nil)
+	if qualifiedByName == qualifiedByPath {
+		return []string{qualifiedByName}
+	}
+	return []string{qualifiedByName, qualifiedByPath}
+}
+
+func fieldNames(fields []*Field) []string {
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name().String())
+	}
+	return names
+}
+
+func functionNames(functions []*Function) []string {
+	var names []string
+	for _, f := range functions {
+		names = append(names, f.Name().String())
+	}
+	return names
+}
+
+// queryImplements は、 typ が name のinterfaceを実装しているかを判定する。
+// name は "パッケージ名.interface名" または "パッケージパス.interface名" の形式で指定する。
+func queryImplements(r *Relations, typ *Type, name string) bool {
+	for _, i := range r.Interfaces().InterfaceAll() {
+		path := fmt.Sprintf("%s.%s", i.PackageSummary().Path(), i.Name())
+		if i.PackageInterfaceName().String() == name || path == name {
+			return r.implements(typ.GoType(), i.goInterface)
+		}
+	}
+	tn, ok := r.lookupTypeName(name)
+	if !ok {
+		return false
+	}
+	iface, ok := tn.Type().Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+	return r.implements(typ.GoType(), iface)
+}
@@ -0,0 +1,98 @@
+package gocode_test
+
+import (
+	"testing"
+)
+
+func TestRelations_Query(t *testing.T) {
+	const pkgPath = "github.com/keisuke-m123/goanalyzer/gocode/testdata"
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "structs implementing interface",
+			query: `structs where implements "testdata.ExportedInterface"`,
+			want:  []string{pkgPath + ".ExportedStruct"},
+		},
+		{
+			name:  "structs implementing external interface",
+			query: `structs where not implements "fmt.Stringer" and implements "testdata.internalInterface"`,
+			want:  []string{pkgPath + ".internalStruct"},
+		},
+		{
+			name:  "structs by package and exported",
+			query: `structs where package ~ "gocode/testdata$" and exported`,
+			want:  []string{pkgPath + ".ExportedStruct", pkgPath + ".TestingSupport"},
+		},
+		{
+			name:  "structs by name pattern",
+			query: `structs where name ~ "^internal"`,
+			want:  []string{pkgPath + ".internalStruct"},
+		},
+		{
+			name:  "fields by type",
+			query: `fields where type = "testdata.ExportedStruct"`,
+			want:  []string{pkgPath + ".TestingSupport.Es", pkgPath + ".TestingSupport.ExportedStruct", pkgPath + ".TestingSupport.es"},
+		},
+		{
+			name:  "fields not exported and not embedded",
+			query: `fields where struct = "TestingSupport" and not (exported or embedded)`,
+			want:  []string{pkgPath + ".TestingSupport.ei", pkgPath + ".TestingSupport.es", pkgPath + ".TestingSupport.ii", pkgPath + ".TestingSupport.is"},
+		},
+		{
+			name:  "methods with pointer receiver",
+			query: `methods where pointer and name != "Test"`,
+			want:  []string{pkgPath + ".internalStruct.InternalTest"},
+		},
+		{
+			name:  "interfaces without condition",
+			query: `interfaces`,
+			want:  []string{pkgPath + ".ExportedInterface", pkgPath + ".internalInterface"},
+		},
+		{
+			name:  "defined types by underlying type",
+			query: `definedtypes where underlying = "string"`,
+			want:  []string{pkgPath + ".DefinedTypeString"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := testingSupportPackages.Query(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := res.Strings()
+			if len(got) != len(test.want) {
+				t.Fatalf("Query(%q) = %v, want %v", test.query, got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("Query(%q)[%d] = %s, want %s", test.query, i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRelations_Query_Error(t *testing.T) {
+	queries := []string{
+		``,
+		`widgets`,
+		`structs where`,
+		`structs where bogus`,
+		`structs where name`,
+		`structs where name ~ "("`,
+		`structs where (exported`,
+		`structs where implements ~ "Reader"`,
+		`structs where name = "a" extra`,
+	}
+	for _, q := range queries {
+		if _, err := testingSupportPackages.Query(q); err == nil {
+			t.Errorf("Query(%q) expected error", q)
+		}
+	}
+}
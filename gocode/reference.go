@@ -0,0 +1,387 @@
+package gocode
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+type (
+	// ReferenceKind は、型やフィールド、メソッドがどのように使われているかを表す。
+	ReferenceKind string
+
+	// ReferenceTarget は、参照される要素を "パッケージパス.型名" または "パッケージパス.型名.メンバ名" の形式で表す。
+	ReferenceTarget string
+
+	// Reference は、型やフィールド、メソッドが使われている1箇所を表す。
+	Reference struct {
+		// target は参照されている要素。
+		target ReferenceTarget
+		// kind は参照の種類。
+		kind ReferenceKind
+		// pos は参照している識別子の位置。キーを省略した複合リテラルの場合は要素の位置。
+		pos token.Pos
+		// position は pos をファイル名と行番号に変換した位置。
+		position token.Position
+		// pkgSummary は参照しているパッケージのサマリ。
+		pkgSummary *PackageSummary
+	}
+
+	// ReferenceIndex は、解析したパッケージ内の参照を参照先ごとにまとめたもの。
+	ReferenceIndex struct {
+		references map[ReferenceTarget][]*Reference
+	}
+
+	// referenceIndexBuilder は、構文木を走査して ReferenceIndex を構築する。
+	referenceIndexBuilder struct {
+		fset  *token.FileSet
+		index *ReferenceIndex
+	}
+)
+
+const (
+	// ReferenceKindType は、変数やパラメータの型としての参照。
+	ReferenceKindType ReferenceKind = "type"
+	// ReferenceKindReceiver は、メソッドのレシーバの型としての参照。
+	ReferenceKindReceiver ReferenceKind = "receiver"
+	// ReferenceKindEmbedding は、structやinterfaceへの埋め込みとしての参照。
+	ReferenceKindEmbedding ReferenceKind = "embedding"
+	// ReferenceKindCompositeLiteral は、複合リテラルの型またはキーとしての参照。
+	ReferenceKindCompositeLiteral ReferenceKind = "composite literal"
+	// ReferenceKindConversion は、型変換としての参照。
+	ReferenceKindConversion ReferenceKind = "conversion"
+	// ReferenceKindTypeAssertion は、型アサーションや型switchとしての参照。
+	ReferenceKindTypeAssertion ReferenceKind = "type assertion"
+	// ReferenceKindFieldAccess は、セレクタによるフィールドへのアクセス。
+	ReferenceKindFieldAccess ReferenceKind = "field access"
+	// ReferenceKindMethodCall は、メソッドの呼び出し。
+	ReferenceKindMethodCall ReferenceKind = "method call"
+	// ReferenceKindMethodValue は、呼び出しを伴わないメソッド値やメソッド式としての参照。
+	ReferenceKindMethodValue ReferenceKind = "method value"
+)
+
+func (rk ReferenceKind) String() string {
+	return string(rk)
+}
+
+func (rt ReferenceTarget) String() string {
+	return string(rt)
+}
+
+func newTypeReferenceTarget(pkgPath PackagePath, typeName string) ReferenceTarget {
+	return ReferenceTarget(fmt.Sprintf("%s.%s", pkgPath, typeName))
+}
+
+func newMemberReferenceTarget(pkgPath PackagePath, typeName string, memberName string) ReferenceTarget {
+	return ReferenceTarget(fmt.Sprintf("%s.%s.%s", pkgPath, typeName, memberName))
+}
+
+func (r *Reference) Target() ReferenceTarget {
+	return r.target
+}
+
+func (r *Reference) Kind() ReferenceKind {
+	return r.kind
+}
+
+func (r *Reference) Pos() token.Pos {
+	return r.pos
+}
+
+func (r *Reference) Position() token.Position {
+	return r.position
+}
+
+// PackageSummary は、参照しているパッケージのサマリを返す。
+func (r *Reference) PackageSummary() *PackageSummary {
+	return r.pkgSummary
+}
+
+// References は、解析したパッケージ内の全ての参照を集めた ReferenceIndex を返す。
+func (r *Relations) References() *ReferenceIndex {
+	b := &referenceIndexBuilder{
+		fset:  r.fset,
+		index: &ReferenceIndex{references: make(map[ReferenceTarget][]*Reference)},
+	}
+	for _, pkg := range r.packages.AsSlice() {
+		if pkg.typesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.files {
+			b.inspectFile(pkg, file)
+		}
+	}
+	for target := range b.index.references {
+		refs := b.index.references[target]
+		sort.Slice(refs, func(i, j int) bool {
+			if refs[i].position.Filename != refs[j].position.Filename {
+				return refs[i].position.Filename < refs[j].position.Filename
+			}
+			return refs[i].position.Offset < refs[j].position.Offset
+		})
+	}
+	return b.index
+}
+
+// Lookup は、 target への参照の一覧を位置順で返す。
+func (ri *ReferenceIndex) Lookup(target ReferenceTarget) []*Reference {
+	return append([]*Reference{}, ri.references[target]...)
+}
+
+// Targets は、参照されている要素の一覧を返す。
+func (ri *ReferenceIndex) Targets() []ReferenceTarget {
+	var targets []ReferenceTarget
+	for target := range ri.references {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i] < targets[j]
+	})
+	return targets
+}
+
+func (ri *ReferenceIndex) StructReferences(s *Struct) []*Reference {
+	return ri.Lookup(newTypeReferenceTarget(s.PackageSummary().Path(), s.Name().String()))
+}
+
+func (ri *ReferenceIndex) InterfaceReferences(i *Interface) []*Reference {
+	return ri.Lookup(newTypeReferenceTarget(i.PackageSummary().Path(), i.Name().String()))
+}
+
+func (ri *ReferenceIndex) DefinedTypeReferences(dt *DefinedType) []*Reference {
+	return ri.Lookup(newTypeReferenceTarget(dt.PackageSummary().Path(), dt.Name().String()))
+}
+
+func (ri *ReferenceIndex) TypeAliasReferences(a *TypeAlias) []*Reference {
+	return ri.Lookup(newTypeReferenceTarget(a.PackageSummary().Path(), a.Name().String()))
+}
+
+// FieldReferences は、 s のフィールド f への参照の一覧を返す。
+func (ri *ReferenceIndex) FieldReferences(s *Struct, f *Field) []*Reference {
+	return ri.Lookup(newMemberReferenceTarget(s.PackageSummary().Path(), s.Name().String(), f.Name().String()))
+}
+
+// MethodReferences は、 receiver の型に定義されたメソッド m への参照の一覧を返す。
+// receiver には Struct.Type や DefinedType.Type を指定する。
+func (ri *ReferenceIndex) MethodReferences(receiver *Type, m *Function) []*Reference {
+	return ri.Lookup(newMemberReferenceTarget(receiver.PackageSummary().Path(), receiver.TypeName().String(), m.Name().String()))
+}
+
+// InterfaceMethodReferences は、interface i のメソッド m への参照の一覧を返す。
+func (ri *ReferenceIndex) InterfaceMethodReferences(i *Interface, m *Function) []*Reference {
+	return ri.Lookup(newMemberReferenceTarget(i.PackageSummary().Path(), i.Name().String(), m.Name().String()))
+}
+
+func (b *referenceIndexBuilder) inspectFile(pkg *Package, file *ast.File) {
+	var stack []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		stack = append(stack, n)
+		switch node := n.(type) {
+		case *ast.Ident:
+			b.addIdent(pkg, node, stack)
+		case *ast.CompositeLit:
+			b.addUnkeyedFields(pkg, node)
+		}
+		return true
+	})
+}
+
+func (b *referenceIndexBuilder) addIdent(pkg *Package, id *ast.Ident, stack []ast.Node) {
+	info := pkg.typesInfo
+	obj, ok := info.Uses[id]
+	if !ok || obj.Pkg() == nil {
+		return
+	}
+
+	switch o := obj.(type) {
+	case *types.TypeName:
+		b.add(pkg, id, newTypeReferenceTarget(PackagePath(o.Pkg().Path()), o.Name()), typeReferenceKind(id, stack))
+	case *types.Var:
+		if !o.IsField() {
+			return
+		}
+		owner, kind, ok := fieldOwner(info, id, stack)
+		if !ok {
+			return
+		}
+		b.add(pkg, id, newMemberReferenceTarget(PackagePath(owner.Obj().Pkg().Path()), owner.Obj().Name(), o.Name()), kind)
+	case *types.Func:
+		recv := o.Type().(*types.Signature).Recv()
+		if recv == nil {
+			return
+		}
+		owner, ok := namedOf(recv.Type())
+		if !ok {
+			return
+		}
+		b.add(pkg, id, newMemberReferenceTarget(PackagePath(owner.Obj().Pkg().Path()), owner.Obj().Name(), o.Name()), methodReferenceKind(id, stack))
+	}
+}
+
+// addUnkeyedFields は、キーを省略したstructの複合リテラルを、各要素に対応するフィールドへの参照として追加する。
+func (b *referenceIndexBuilder) addUnkeyedFields(pkg *Package, lit *ast.CompositeLit) {
+	if len(lit.Elts) == 0 {
+		return
+	}
+	if _, ok := lit.Elts[0].(*ast.KeyValueExpr); ok {
+		return
+	}
+	owner, ok := namedOf(pkg.typesInfo.TypeOf(lit))
+	if !ok {
+		return
+	}
+	st, ok := owner.Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+	for i, elt := range lit.Elts {
+		if i >= st.NumFields() {
+			break
+		}
+		b.add(pkg, elt, newMemberReferenceTarget(PackagePath(owner.Obj().Pkg().Path()), owner.Obj().Name(), st.Field(i).Name()), ReferenceKindCompositeLiteral)
+	}
+}
+
+func (b *referenceIndexBuilder) add(pkg *Package, node ast.Node, target ReferenceTarget, kind ReferenceKind) {
+	b.index.references[target] = append(b.index.references[target], &Reference{
+		target:     target,
+		kind:       kind,
+		pos:        node.Pos(),
+		position:   b.fset.Position(node.Pos()),
+		pkgSummary: pkg.Summary(),
+	})
+}
+
+// typeReferenceKind は、型名の識別子 id が使われている構文から参照の種類を判定する。
+// stack は構文木のルートから id までのノードの一覧。
+func typeReferenceKind(id *ast.Ident, stack []ast.Node) ReferenceKind {
+	var node ast.Node = id
+	i := len(stack) - 2
+	// pkg.T や *T, (T) は外側のノードを型の表現として扱う。
+	for i >= 0 && wrapsTypeExpr(stack[i], node) {
+		node = stack[i]
+		i--
+	}
+	if i < 0 {
+		return ReferenceKindType
+	}
+
+	switch p := stack[i].(type) {
+	case *ast.CompositeLit:
+		if p.Type == node {
+			return ReferenceKindCompositeLiteral
+		}
+	case *ast.CallExpr:
+		if p.Fun == node {
+			return ReferenceKindConversion
+		}
+	case *ast.TypeAssertExpr:
+		if p.Type == node {
+			return ReferenceKindTypeAssertion
+		}
+	case *ast.CaseClause:
+		if i >= 2 {
+			if _, ok := stack[i-2].(*ast.TypeSwitchStmt); ok {
+				return ReferenceKindTypeAssertion
+			}
+		}
+	case *ast.Field:
+		if i >= 2 {
+			if fd, ok := stack[i-2].(*ast.FuncDecl); ok && fd.Recv == stack[i-1] {
+				return ReferenceKindReceiver
+			}
+		}
+		if len(p.Names) == 0 && i >= 2 {
+			switch stack[i-2].(type) {
+			case *ast.StructType, *ast.InterfaceType:
+				return ReferenceKindEmbedding
+			}
+		}
+	}
+	return ReferenceKindType
+}
+
+// wrapsTypeExpr は、 parent が型の表現 child をそのまま包むノードであるかを返す。
+func wrapsTypeExpr(parent, child ast.Node) bool {
+	switch p := parent.(type) {
+	case *ast.SelectorExpr:
+		return p.Sel == child
+	case *ast.StarExpr, *ast.ParenExpr:
+		return true
+	}
+	return false
+}
+
+// fieldOwner は、フィールドの識別子 id からフィールドが宣言されている型と参照の種類を求める。
+func fieldOwner(info *types.Info, id *ast.Ident, stack []ast.Node) (*types.Named, ReferenceKind, bool) {
+	if len(stack) < 2 {
+		return nil, "", false
+	}
+	switch p := stack[len(stack)-2].(type) {
+	case *ast.SelectorExpr:
+		sel, ok := info.Selections[p]
+		if !ok || p.Sel != id {
+			return nil, "", false
+		}
+		// 埋め込みにより昇格したフィールドは、実際に宣言されているstructを辿る。
+		typ := sel.Recv()
+		indices := sel.Index()
+		for _, index := range indices[:len(indices)-1] {
+			st, ok := derefType(typ).Underlying().(*types.Struct)
+			if !ok {
+				return nil, "", false
+			}
+			typ = st.Field(index).Type()
+		}
+		owner, ok := namedOf(typ)
+		return owner, ReferenceKindFieldAccess, ok
+	case *ast.KeyValueExpr:
+		if p.Key != id || len(stack) < 3 {
+			return nil, "", false
+		}
+		lit, ok := stack[len(stack)-3].(*ast.CompositeLit)
+		if !ok {
+			return nil, "", false
+		}
+		owner, ok := namedOf(info.TypeOf(lit))
+		return owner, ReferenceKindCompositeLiteral, ok
+	}
+	return nil, "", false
+}
+
+// methodReferenceKind は、メソッドの識別子 id が呼び出しに使われているかを判定する。
+func methodReferenceKind(id *ast.Ident, stack []ast.Node) ReferenceKind {
+	if len(stack) >= 3 {
+		if sel, ok := stack[len(stack)-2].(*ast.SelectorExpr); ok && sel.Sel == id {
+			if call, ok := stack[len(stack)-3].(*ast.CallExpr); ok && call.Fun == sel {
+				return ReferenceKindMethodCall
+			}
+		}
+	}
+	return ReferenceKindMethodValue
+}
+
+func derefType(typ types.Type) types.Type {
+	if p, ok := typ.(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return typ
+}
+
+// namedOf は、ポインタを外した typ がパッケージに属する名前付きの型であればその型を返す。
+func namedOf(typ types.Type) (*types.Named, bool) {
+	if typ == nil {
+		return nil, false
+	}
+	named, ok := derefType(typ).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil, false
+	}
+	return named, true
+}
@@ -0,0 +1,119 @@
+package gocode_test
+
+import (
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestRelations_References(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"model/model.go": `package model
+
+type User struct {
+	Name string
+	age  int
+}
+
+func (u *User) Age() int { return u.age }
+
+type Named interface {
+	GetName() string
+}
+`,
+		"service/service.go": `package service
+
+import "example.com/testmodule/model"
+
+type Admin struct {
+	model.User
+}
+
+func New(name string) *model.User {
+	u := &model.User{Name: name}
+	_ = u.Age()
+	f := u.Age
+	_ = f
+	return u
+}
+
+func Check(v interface{}) bool {
+	if a, ok := v.(*Admin); ok {
+		return a.Name != ""
+	}
+	switch v.(type) {
+	case model.Named:
+		return true
+	}
+	return false
+}
+
+func Convert(v struct{ Name string; age int }) model.User {
+	return model.User(v)
+}
+`,
+	})
+	refs := r.References()
+
+	tests := []struct {
+		target gocode.ReferenceTarget
+		kinds  []gocode.ReferenceKind
+	}{
+		{
+			target: "example.com/testmodule/model.User",
+			kinds: []gocode.ReferenceKind{
+				gocode.ReferenceKindReceiver,
+				gocode.ReferenceKindEmbedding,
+				gocode.ReferenceKindType,
+				gocode.ReferenceKindCompositeLiteral,
+				gocode.ReferenceKindType,
+				gocode.ReferenceKindConversion,
+			},
+		},
+		{
+			target: "example.com/testmodule/model.User.Name",
+			kinds:  []gocode.ReferenceKind{gocode.ReferenceKindCompositeLiteral, gocode.ReferenceKindFieldAccess},
+		},
+		{
+			target: "example.com/testmodule/model.User.age",
+			kinds:  []gocode.ReferenceKind{gocode.ReferenceKindFieldAccess},
+		},
+		{
+			target: "example.com/testmodule/model.User.Age",
+			kinds:  []gocode.ReferenceKind{gocode.ReferenceKindMethodCall, gocode.ReferenceKindMethodValue},
+		},
+		{
+			target: "example.com/testmodule/service.Admin",
+			kinds:  []gocode.ReferenceKind{gocode.ReferenceKindTypeAssertion},
+		},
+		{
+			target: "example.com/testmodule/model.Named",
+			kinds:  []gocode.ReferenceKind{gocode.ReferenceKindTypeAssertion},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.target.String(), func(t *testing.T) {
+			got := refs.Lookup(test.target)
+			if len(got) != len(test.kinds) {
+				var kinds []gocode.ReferenceKind
+				for _, ref := range got {
+					kinds = append(kinds, ref.Kind())
+				}
+				t.Fatalf("Lookup() kinds = %v, want %v", kinds, test.kinds)
+			}
+			for i := range got {
+				if got[i].Kind() != test.kinds[i] {
+					t.Errorf("Lookup()[%d].Kind() = %s, want %s (%s)", i, got[i].Kind(), test.kinds[i], got[i].Position())
+				}
+			}
+		})
+	}
+
+	s, ok := r.Structs().Get("model", "User")
+	if !ok {
+		t.Fatal("expected to find struct")
+	}
+	if n := len(refs.StructReferences(s)); n != 6 {
+		t.Errorf("len(StructReferences()) = %d, want 6", n)
+	}
+}
@@ -0,0 +1,204 @@
+package gocode
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// SARIFOptions は、 WriteSARIF の出力の設定を表す。
+	SARIFOptions struct {
+		// ToolVersion は、ツールのバージョンとして出力する文字列。空であれば出力しない。
+		ToolVersion string
+		// BaseDirectory が空でなければ、その下のファイルの位置を BaseDirectory からの相対URIとして出力し、
+		// uriBaseId に SRCROOT を設定する。空であれば絶対パスの file URI として出力する。
+		BaseDirectory string
+	}
+
+	sarifLog struct {
+		Schema  string      `json:"$schema"`
+		Version string      `json:"version"`
+		Runs    []*sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool               sarifTool                         `json:"tool"`
+		OriginalURIBaseIDs map[string]*sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+		Results            []*sarifResult                    `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string       `json:"name"`
+		InformationURI string       `json:"informationUri"`
+		Version        string       `json:"version,omitempty"`
+		Rules          []*sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID    string           `json:"ruleId"`
+		RuleIndex int              `json:"ruleIndex"`
+		Level     string           `json:"level"`
+		Message   sarifMessage     `json:"message"`
+		Locations []*sarifLocation `json:"locations,omitempty"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation *sarifPhysicalLocation  `json:"physicalLocation,omitempty"`
+		LogicalLocations []*sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+
+	sarifLogicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+	}
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifSrcRoot = "SRCROOT"
+)
+
+// sarifRuleDescriptions は、検査ごとのルールの説明。
+var sarifRuleDescriptions = map[CheckName]string{
+	CheckDeadCode: "Unused struct, field, method, defined type or type alias",
+	CheckNearMiss: "Struct almost implements an interface",
+	CheckMetrics:  "Package metric exceeds the configured threshold",
+	CheckCycles:   "Cycle between types",
+	CheckUntyped:  "Field, parameter or return value typed interface{} or any",
+	CheckSemver:   "Public API change requiring a version bump",
+}
+
+// WriteSARIF は、 findings をSARIF 2.1.0の形式で w に書き出す。
+// ルールは Finding.Rule ごとに出力し、位置が特定できる検出には物理的な位置を、全ての検出に要素の名前を論理的な位置として付ける。
+func WriteSARIF(w io.Writer, findings []*Finding, options *SARIFOptions) error {
+	if options == nil {
+		options = &SARIFOptions{}
+	}
+	run := &sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "goanalyzer",
+			InformationURI: "https://github.com/keisuke-m123/goanalyzer",
+			Version:        options.ToolVersion,
+			Rules:          []*sarifRule{},
+		}},
+		Results: []*sarifResult{},
+	}
+	var baseDir string
+	if options.BaseDirectory != "" {
+		abs, err := filepath.Abs(options.BaseDirectory)
+		if err != nil {
+			return err
+		}
+		baseDir = abs
+		run.OriginalURIBaseIDs = map[string]*sarifArtifactLocation{
+			sarifSrcRoot: {URI: fileURI(baseDir) + "/"},
+		}
+	}
+
+	ruleIndexes := make(map[string]int)
+	for _, f := range findings {
+		index, ok := ruleIndexes[f.rule]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[f.rule] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, &sarifRule{
+				ID:                   f.rule,
+				ShortDescription:     sarifMessage{Text: sarifRuleDescriptions[f.check]},
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevel(f)},
+			})
+		}
+
+		location := &sarifLocation{
+			LogicalLocations: []*sarifLogicalLocation{{FullyQualifiedName: f.symbol}},
+		}
+		if f.position.Filename != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: artifactLocation(f.position.Filename, baseDir),
+			}
+			if f.position.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: f.position.Line, StartColumn: f.position.Column}
+			}
+		}
+		run.Results = append(run.Results, &sarifResult{
+			RuleID:    f.rule,
+			RuleIndex: index,
+			Level:     sarifLevel(f),
+			Message:   sarifMessage{Text: f.message},
+			Locations: []*sarifLocation{location},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []*sarifRun{run}})
+}
+
+// sarifLevel は、検出の重要度を返す。互換性を壊さないAPIの変更と interface{} の使用は情報として扱う。
+func sarifLevel(f *Finding) string {
+	switch {
+	case f.check == CheckUntyped:
+		return "note"
+	case f.check == CheckSemver && f.rule != ruleID(CheckSemver, SemverBumpMajor.String()):
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// artifactLocation は、 filename を baseDir からの相対URI、または file URI に変換する。
+func artifactLocation(filename, baseDir string) sarifArtifactLocation {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		abs = filename
+	}
+	if baseDir != "" {
+		if rel, err := filepath.Rel(baseDir, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return sarifArtifactLocation{URI: (&url.URL{Path: filepath.ToSlash(rel)}).String(), URIBaseID: sarifSrcRoot}
+		}
+	}
+	return sarifArtifactLocation{URI: fileURI(abs)}
+}
+
+func fileURI(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
@@ -0,0 +1,147 @@
+package gocode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+type sarifTestLog struct {
+	Version string `json:"version"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Rules []struct {
+					ID string `json:"id"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID    string `json:"ruleId"`
+			RuleIndex int    `json:"ruleIndex"`
+			Level     string `json:"level"`
+			Locations []struct {
+				PhysicalLocation *struct {
+					ArtifactLocation struct {
+						URI       string `json:"uri"`
+						URIBaseID string `json:"uriBaseId"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine int `json:"startLine"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+func TestWriteSARIF(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"app/app.go": `package app
+
+import "example.com/testmodule/model"
+
+type unused struct{}
+
+type App struct {
+	Model model.Model
+	Value interface{}
+}
+`,
+		"model/model.go": `package model
+
+type Model struct{}
+`,
+	})
+	r, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:  afero.NewOsFs(),
+		Directories: []string{dir},
+		Recursive:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := r.RunChecks(&gocode.CheckSettings{
+		Enabled: []gocode.CheckName{gocode.CheckDeadCode, gocode.CheckMetrics, gocode.CheckUntyped},
+		Metrics: gocode.PackageMetricsThresholds{MaxInstability: 0.5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := gocode.WriteSARIF(&buf, findings, &gocode.SARIFOptions{BaseDirectory: dir}); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifTestLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version = %s, runs = %d, want 2.1.0 and 1 run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	type location struct {
+		uri  string
+		line int
+	}
+	want := map[string]location{
+		"deadcode/struct":     {uri: "app/app.go", line: 5},
+		"metrics/instability": {uri: "app/app.go", line: 1},
+		"untyped/field":       {uri: "app/app.go", line: 9},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("number of results = %d, want %d\n%s", len(run.Results), len(want), buf.String())
+	}
+	for _, res := range run.Results {
+		w, ok := want[res.RuleID]
+		if !ok {
+			t.Errorf("unexpected rule %s", res.RuleID)
+			continue
+		}
+		if got := run.Tool.Driver.Rules[res.RuleIndex].ID; got != res.RuleID {
+			t.Errorf("%s: rules[ruleIndex].id = %s", res.RuleID, got)
+		}
+		if len(res.Locations) != 1 || res.Locations[0].PhysicalLocation == nil {
+			t.Errorf("%s: physical location is missing", res.RuleID)
+			continue
+		}
+		pl := res.Locations[0].PhysicalLocation
+		if pl.ArtifactLocation.URI != w.uri || pl.ArtifactLocation.URIBaseID != "SRCROOT" || pl.Region.StartLine != w.line {
+			t.Errorf("%s: location = %s (%s) line %d, want %s (SRCROOT) line %d",
+				res.RuleID, pl.ArtifactLocation.URI, pl.ArtifactLocation.URIBaseID, pl.Region.StartLine, w.uri, w.line)
+		}
+	}
+}
+
+func TestAPIChange_Finding(t *testing.T) {
+	old := loadTestModule(t, map[string]string{
+		"api/api.go": "package api\n\ntype Client struct{}\n\nfunc (c *Client) Close() {}\n",
+	})
+	new := loadTestModule(t, map[string]string{
+		"api/api.go": "package api\n\ntype Client struct{}\n\nfunc (c *Client) Open() {}\n",
+	})
+
+	got := make(map[string]int)
+	for _, c := range gocode.CompareAPI(old, new).Changes() {
+		f := c.Finding()
+		got[f.Rule()+" "+f.Symbol()] = f.Position().Line
+	}
+	want := map[string]int{
+		// 削除されたメソッドは、それを保持していた型の位置に報告する。
+		"semver/major example.com/testmodule/api.Client.Close": 3,
+		"semver/minor example.com/testmodule/api.Client.Open":  5,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	for k, line := range want {
+		if got[k] != line {
+			t.Errorf("%s: line = %d, want %d", k, got[k], line)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package gocode
 import (
 	"go/token"
 	"go/types"
+	"sort"
 	"strings"
 )
 
@@ -21,7 +22,10 @@ type (
 		pkgSummary *PackageSummary
 		methods    *FunctionList
 		fields     *FieldList
+		embeds     *EmbedList
 		implements *PackageInterfaceMap
+		// includeEmptyInterfaces は、 LoadOptions.IncludeEmptyInterfaces の値。 Relations に登録する際に設定する。
+		includeEmptyInterfaces bool
 	}
 
 	// StructList は、Goのstructのリストを表す。
@@ -53,7 +57,7 @@ func NewPackageStructName(pkgName PackageName, structName StructName) PackageStr
 func newStructList(pkg packageIn) *StructList {
 	var structs []*Struct
 	for _, obj := range pkg.Typed() {
-		if s, ok := newStructIfStructType(pkg, obj); ok {
+		if s, ok := newStructIfStructType(obj); ok {
 			structs = append(structs, s)
 		}
 	}
@@ -68,7 +72,10 @@ func (s *StructList) asSlice() []*Struct {
 	return slice
 }
 
-func newStructIfStructType(pkg packageIn, obj types.Object) (res *Struct, ok bool) {
+func newStructIfStructType(obj types.Object) (res *Struct, ok bool) {
+	if tn, ok := obj.(*types.TypeName); ok && tn.IsAlias() {
+		return &Struct{}, false
+	}
 	structType, ok := obj.Type().Underlying().(*types.Struct)
 	if !ok {
 		return &Struct{}, false
@@ -82,7 +89,8 @@ func newStructIfStructType(pkg packageIn, obj types.Object) (res *Struct, ok boo
 		typ:        newType(pkgSummary, obj.Type()),
 		structName: StructName(obj.Name()),
 		fields:     newFieldListFromStructType(structType),
-		methods:    newMethodsFromObject(pkg, obj),
+		embeds:     newEmbedListFromStructType(pkgSummary, structType),
+		methods:    newMethodsFromObject(obj),
 		implements: newPackageInterfaceMap(),
 	}
 
@@ -113,22 +121,75 @@ func (s *Struct) Methods() []*Function {
 	return s.methods.asSlice()
 }
 
+// MethodsIncludingPromoted は、埋め込みによって昇格したメソッドを含む、structのポインタのメソッドセットの全てのメソッドを返す。
+// 昇格の経路が必要な場合は MethodSet を用いる。
+func (s *Struct) MethodsIncludingPromoted() []*Function {
+	var methods []*Function
+	for _, sel := range s.MethodSet(true) {
+		methods = append(methods, sel.Function())
+	}
+	return methods
+}
+
 func (s *Struct) Fields() []*Field {
 	return s.fields.asSlice()
 }
 
+// Embeds は、structに埋め込まれた型の一覧を返す。
+func (s *Struct) Embeds() []*Embed {
+	return s.embeds.asSlice()
+}
+
+// MethodSet は、埋め込みによって昇格したメソッドを含むメソッドセットを返す。
+// pointer が真であればstructのポインタのメソッドセットを返す。
+func (s *Struct) MethodSet(pointer bool) []*MethodSelection {
+	return newMethodSelections(s.pkgSummary, s.typ.GoType(), pointer)
+}
+
+// AllFields は、埋め込みによって昇格したフィールドを含む全てのフィールドを、埋め込みの浅い順に返す。
+// より浅い位置にある同名のフィールドに隠されるフィールドや、同じ深さで名前が衝突するフィールドも含む。
+func (s *Struct) AllFields() []*FieldSelection {
+	return newFieldSelections(s.typ.GoType())
+}
+
 func (s *Struct) ImplementInterfaces() *PackageInterfaceMap {
 	return s.implements
 }
 
+// Implements は、structが i を実装しているかを返す。
+// メソッドを持たないinterfaceは LoadOptions.IncludeEmptyInterfaces が真の場合のみ実装しているとみなす。
 func (s *Struct) Implements(i *Interface) bool {
-	return implements(s.Type().GoType(), i.goInterface)
+	return implementsInterface(s.Type().GoType(), i.goInterface, s.includeEmptyInterfaces)
 }
 
 func (s *Struct) ImplementsGoTypes(i *types.Interface) bool {
 	return implements(s.Type().GoType(), i)
 }
 
+// Implementation は、structによる i の実装を、interfaceのメソッドごとの対応と共に返す。
+// structが i を実装していなければ ok は偽となる。
+func (s *Struct) Implementation(i *Interface) (impl *Implementation, ok bool) {
+	return newImplementation(s.pkgSummary, s.typ.GoType(), i, s.includeEmptyInterfaces)
+}
+
+// Implementations は、 ImplementInterfaces の各interfaceの実装を、interfaceのパッケージパスと名前の順で返す。
+func (s *Struct) Implementations() []*Implementation {
+	var res []*Implementation
+	for _, i := range s.implements.InterfaceAll() {
+		if impl, ok := s.Implementation(i); ok {
+			res = append(res, impl)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i].iface, res[j].iface
+		if a.PackageSummary().Path() != b.PackageSummary().Path() {
+			return a.PackageSummary().Path() < b.PackageSummary().Path()
+		}
+		return a.Name() < b.Name()
+	})
+	return res
+}
+
 func (s *Struct) addInterfaceIfImplements(i *Interface) {
 	if s.Implements(i) {
 		s.implements.put(i)
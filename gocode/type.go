@@ -50,6 +50,15 @@ func (ftn RelativeFullTypeName) String() string {
 	return string(ftn)
 }
 
+// DisplayType は、パッケージパスの代わりにパッケージ名で修飾した表示用の型名を返す。
+func DisplayType(typ types.Type) string {
+	return types.TypeString(typ, packageNameQualifier)
+}
+
+func packageNameQualifier(pkg *types.Package) string {
+	return pkg.Name()
+}
+
 func newTypeWithoutFundamentalTypes(currentPkgSummary *PackageSummary, typ types.Type) *Type {
 	t := &Type{
 		goType:     typ,
@@ -1,6 +1,8 @@
 package gocode
 
 import (
+	"go/ast"
+	"go/token"
 	"go/types"
 	"strings"
 )
@@ -14,9 +16,17 @@ type (
 
 	// TypeAlias は、型別名を表す。
 	TypeAlias struct {
+		obj        *types.TypeName
+		definedPos token.Pos
 		name       TypeAliasName
 		pkgSummary *PackageSummary
 		typ        *Type
+		// target は型別名が参照する型。 typ と異なり、名前付きの型であれば基底型に展開しない。
+		target *Type
+		// rhs は右辺が型別名の場合のその型別名。宣言の構文から求め、構文がなければ go/types の型別名の型から求める。
+		rhs *types.TypeName
+		// aliases は Relations に登録された型別名。 ResolveChain で右辺の型別名を探すのに使う。
+		aliases *PackageTypeAliasMap
 	}
 
 	// TypeAliasList は、型別名のリストを表す。
@@ -48,11 +58,22 @@ func newTypeAliasIfObjectTypeAlias(obj types.Object) (res *TypeAlias, ok bool) {
 
 	pkgSummary := newPackageSummaryFromGoTypes(obj.Pkg())
 
-	return &TypeAlias{
+	a := &TypeAlias{
+		obj:        tn,
+		definedPos: obj.Pos(),
 		name:       TypeAliasName(obj.Name()),
 		pkgSummary: pkgSummary,
 		typ:        newType(pkgSummary, obj.Type().Underlying()),
-	}, true
+		target:     newType(pkgSummary, unalias(obj.Type())),
+	}
+	if rhs, ok := rhsAlias(tn); ok {
+		a.rhs = rhs
+	}
+	return a, true
+}
+
+func (a *TypeAlias) DefinedPos() token.Pos {
+	return a.definedPos
 }
 
 func (a *TypeAlias) PackageSummary() *PackageSummary {
@@ -67,20 +88,122 @@ func (a *TypeAlias) Name() TypeAliasName {
 	return a.name
 }
 
+// Type は、型別名が参照する型の基底型を返す。参照する型そのものは Target で取得する。
 func (a *TypeAlias) Type() *Type {
 	return a.typ
 }
 
+// Target は、型別名が参照する型を返す。 type A = pkg.B であれば pkg.B となり、
+// 型別名の型別名であれば型別名でない型まで辿った型となる。
+func (a *TypeAlias) Target() *Type {
+	return a.target
+}
+
+// ResolveChain は、 a から右辺の型別名を順に辿った型別名の一覧を返す。先頭は a となる。
+// type A = B と type B = C であれば A、B の順となり、いずれの Target も C となる。
+// 右辺は解析したパッケージの宣言から辿る。解析対象外のパッケージの型別名の右辺は、
+// go/types が型別名を型として表す場合のみ辿れる。
+func (a *TypeAlias) ResolveChain() []*TypeAlias {
+	chain := []*TypeAlias{a}
+	seen := map[string]struct{}{typeNameKey(a.obj): {}}
+	for {
+		next := chain[len(chain)-1].rhs
+		if next == nil {
+			return chain
+		}
+		if _, ok := seen[typeNameKey(next)]; ok {
+			return chain
+		}
+		seen[typeNameKey(next)] = struct{}{}
+		alias, ok := a.lookupAlias(next)
+		if !ok {
+			return chain
+		}
+		chain = append(chain, alias)
+	}
+}
+
+// lookupAlias は、 obj の型別名を、 Relations に登録されていればそれを、なければ obj から作って返す。
+func (a *TypeAlias) lookupAlias(obj *types.TypeName) (*TypeAlias, bool) {
+	if a.aliases != nil && obj.Pkg() != nil {
+		if alias, ok := a.aliases.Get(PackageName(obj.Pkg().Name()), TypeAliasName(obj.Name())); ok && alias.obj.Pkg().Path() == obj.Pkg().Path() {
+			return alias, true
+		}
+	}
+	return newTypeAliasIfObjectTypeAlias(obj)
+}
+
 func newAliasList(pkg packageIn) *TypeAliasList {
+	rhs := rhsAliasesFromSyntax(pkg)
 	var aliases []*TypeAlias
 	for _, obj := range pkg.Typed() {
 		if a, ok := newTypeAliasIfObjectTypeAlias(obj); ok {
+			if next, ok := rhs[a.obj]; ok {
+				a.rhs = next
+			}
 			aliases = append(aliases, a)
 		}
 	}
 	return &TypeAliasList{aliases: aliases}
 }
 
+// rhsAliasesFromSyntax は、 pkg の型別名の宣言のうち、右辺が型別名であるものの右辺の型別名を返す。
+// go/types が型別名を型として表さない場合も辿れるように、右辺の識別子が指す型名を TypesInfo.Uses から求める。
+func rhsAliasesFromSyntax(pkg packageIn) map[*types.TypeName]*types.TypeName {
+	res := make(map[*types.TypeName]*types.TypeName)
+	info := pkg.TypesInfo()
+	if info == nil {
+		return res
+	}
+	for _, file := range pkg.Files() {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok || !ts.Assign.IsValid() {
+					continue
+				}
+				obj, ok := info.Defs[ts.Name].(*types.TypeName)
+				if !ok {
+					continue
+				}
+				expr := ts.Type
+				for {
+					paren, ok := expr.(*ast.ParenExpr)
+					if !ok {
+						break
+					}
+					expr = paren.X
+				}
+				var ident *ast.Ident
+				switch e := expr.(type) {
+				case *ast.Ident:
+					ident = e
+				case *ast.SelectorExpr:
+					ident = e.Sel
+				default:
+					continue
+				}
+				if next, ok := info.Uses[ident].(*types.TypeName); ok && next.IsAlias() {
+					res[obj] = next
+				}
+			}
+		}
+	}
+	return res
+}
+
+// typeNameKey は、パッケージの解析ごとに異なる *types.TypeName を同じ型名として比較するためのキーを返す。
+func typeNameKey(obj *types.TypeName) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
 func (al *TypeAliasList) asSlice() []*TypeAlias {
 	var slice []*TypeAlias
 	for i := range al.aliases {
@@ -88,3 +211,39 @@ func (al *TypeAliasList) asSlice() []*TypeAlias {
 	}
 	return slice
 }
+
+// targetTypeName は、型別名が参照する型が名前付きの型であれば、その型名を返す。
+func (a *TypeAlias) targetTypeName() (*types.TypeName, bool) {
+	named, ok := a.target.GoType().(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil, false
+	}
+	return named.Obj(), true
+}
+
+// rhsAlias は、型別名 obj の右辺が型別名であればその型別名を返す。
+func rhsAlias(obj *types.TypeName) (*types.TypeName, bool) {
+	alias, ok := obj.Type().(interface{ Rhs() types.Type })
+	if !ok {
+		return nil, false
+	}
+	next, ok := alias.Rhs().(interface {
+		Obj() *types.TypeName
+		Rhs() types.Type
+	})
+	if !ok {
+		return nil, false
+	}
+	return next.Obj(), true
+}
+
+// unalias は、go/types が型別名を型として表す場合に、型別名を辿った先の型を返す。
+func unalias(typ types.Type) types.Type {
+	for {
+		alias, ok := typ.(interface{ Rhs() types.Type })
+		if !ok {
+			return typ
+		}
+		typ = alias.Rhs()
+	}
+}
@@ -0,0 +1,94 @@
+package gocode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestTypeAlias_ResolveChain(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"model/model.go": `package model
+
+type Node struct {
+	Name string
+}
+
+type Reader interface {
+	Read() *Node
+}
+
+type Entity = Node
+`,
+		"api/api.go": `package api
+
+import "example.com/testmodule/model"
+
+type Node = model.Node
+
+type Item = Node
+
+type Reader = model.Reader
+
+type Names = []string
+
+type Entity = (model.Entity)
+`,
+	})
+
+	tests := []struct {
+		alias  gocode.TypeAliasName
+		target string
+		chain  string
+	}{
+		{alias: "Node", target: "model.Node", chain: "api.Node"},
+		{alias: "Item", target: "model.Node", chain: "api.Item api.Node"},
+		{alias: "Reader", target: "model.Reader", chain: "api.Reader"},
+		{alias: "Names", target: "[]string", chain: "api.Names"},
+		{alias: "Entity", target: "model.Node", chain: "api.Entity model.Entity"},
+	}
+	for _, test := range tests {
+		t.Run(test.alias.String(), func(t *testing.T) {
+			a, ok := r.TypeAliases().Get("api", test.alias)
+			if !ok {
+				t.Fatalf("type alias %s is not found", test.alias)
+			}
+			if got := a.Target().RelativeFullTypeName().String(); got != test.target {
+				t.Errorf("Target().RelativeFullTypeName() = %s, want %s", got, test.target)
+			}
+			var chain []string
+			for _, c := range a.ResolveChain() {
+				chain = append(chain, c.PackageAliasName().String())
+			}
+			if got := strings.Join(chain, " "); got != test.chain {
+				t.Errorf("ResolveChain() = %s, want %s", got, test.chain)
+			}
+		})
+	}
+
+	// 解析したパッケージの型別名は、宣言から右辺を辿った登録済みの型別名となる。
+	entity, _ := r.TypeAliases().Get("api", "Entity")
+	modelEntity, _ := r.TypeAliases().Get("model", "Entity")
+	if chain := entity.ResolveChain(); len(chain) != 2 || chain[1] != modelEntity {
+		t.Errorf("ResolveChain()[1] of api.Entity is not the loaded model.Entity")
+	}
+
+	if n := len(r.Structs().StructAll()); n != 1 {
+		t.Errorf("number of structs = %d, want 1 without the aliases", n)
+	}
+	if n := len(r.Interfaces().InterfaceAll()); n != 1 {
+		t.Errorf("number of interfaces = %d, want 1 without the aliases", n)
+	}
+	for _, name := range []gocode.StructName{"Node", "Item"} {
+		if s, ok := r.Structs().Get("api", name); !ok || s.PackageStructName() != "model.Node" {
+			t.Errorf("Structs().Get(api, %s) = %v, %v, want model.Node", name, s, ok)
+		}
+	}
+	if i, ok := r.Interfaces().Get("api", "Reader"); !ok || i.PackageInterfaceName() != "model.Reader" {
+		t.Errorf("Interfaces().Get(api, Reader) = %v, %v, want model.Reader", i, ok)
+	}
+	if _, ok := r.Structs().Get("api", "Names"); ok {
+		t.Errorf("Structs().Get(api, Names) is found, want not found")
+	}
+}
@@ -0,0 +1,478 @@
+package gocode
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+type (
+	// TypeNodeID は、型の依存グラフの節を "パッケージパス.型名" の形式で表す。
+	TypeNodeID string
+
+	// TypeNodeKind は、型の依存グラフの節の種類を表す。
+	TypeNodeKind string
+
+	// TypeEdgeKind は、型の依存グラフの辺の種類を表す。
+	TypeEdgeKind string
+
+	// TypeNode は、型の依存グラフの節を表す。
+	TypeNode struct {
+		id         TypeNodeID
+		kind       TypeNodeKind
+		name       string
+		pkgSummary *PackageSummary
+	}
+
+	// TypeEdge は、型から型への依存を表す。
+	TypeEdge struct {
+		from TypeNodeID
+		to   TypeNodeID
+		kind TypeEdgeKind
+	}
+
+	// TypeGraph は、解析したstruct、interface、defined type、type aliasの間の依存グラフを表す。
+	TypeGraph struct {
+		nodes map[TypeNodeID]*TypeNode
+		out   map[TypeNodeID][]*TypeEdge
+		in    map[TypeNodeID][]*TypeEdge
+		// edges は重複した辺を除くための集合。
+		edges map[TypeEdge]struct{}
+	}
+)
+
+const (
+	TypeNodeStruct      TypeNodeKind = "struct"
+	TypeNodeInterface   TypeNodeKind = "interface"
+	TypeNodeDefinedType TypeNodeKind = "defined type"
+	TypeNodeTypeAlias   TypeNodeKind = "type alias"
+)
+
+const (
+	// TypeEdgeField は、フィールドの型への依存を表す。
+	TypeEdgeField TypeEdgeKind = "field"
+	// TypeEdgeParameter は、メソッドのパラメータの型への依存を表す。
+	TypeEdgeParameter TypeEdgeKind = "parameter"
+	// TypeEdgeResult は、メソッドの戻り値の型への依存を表す。
+	TypeEdgeResult TypeEdgeKind = "result"
+	// TypeEdgeEmbed は、埋め込んだ型への依存を表す。
+	TypeEdgeEmbed TypeEdgeKind = "embed"
+	// TypeEdgeImplements は、実装しているinterfaceへの依存を表す。
+	TypeEdgeImplements TypeEdgeKind = "implements"
+	// TypeEdgeUnderlying は、defined typeの基底型が参照する型への依存を表す。
+	TypeEdgeUnderlying TypeEdgeKind = "underlying"
+	// TypeEdgeAlias は、type aliasが参照する型への依存を表す。
+	TypeEdgeAlias TypeEdgeKind = "alias"
+)
+
+func (id TypeNodeID) String() string {
+	return string(id)
+}
+
+func (k TypeNodeKind) String() string {
+	return string(k)
+}
+
+func (k TypeEdgeKind) String() string {
+	return string(k)
+}
+
+// TypeGraph は、解析した型の間の依存グラフを生成する。
+// 解析対象外のパッケージの型と、組み込み型への依存は含まない。
+func (r *Relations) TypeGraph() *TypeGraph {
+	g := &TypeGraph{
+		nodes: make(map[TypeNodeID]*TypeNode),
+		out:   make(map[TypeNodeID][]*TypeEdge),
+		in:    make(map[TypeNodeID][]*TypeEdge),
+		edges: make(map[TypeEdge]struct{}),
+	}
+
+	for _, s := range r.Structs().StructAll() {
+		g.addNode(TypeNodeStruct, s.Name().String(), s.PackageSummary())
+	}
+	for _, i := range r.Interfaces().InterfaceAll() {
+		g.addNode(TypeNodeInterface, i.Name().String(), i.PackageSummary())
+	}
+	for _, dt := range r.DefinedTypes().DefinedTypeAll() {
+		g.addNode(TypeNodeDefinedType, dt.Name().String(), dt.PackageSummary())
+	}
+	for _, a := range r.TypeAliases().AliasAll() {
+		g.addNode(TypeNodeTypeAlias, a.Name().String(), a.PackageSummary())
+	}
+
+	for _, s := range r.Structs().StructAll() {
+		from := newTypeNodeID(s.PackageSummary(), s.Name().String())
+		for _, f := range s.Fields() {
+			kind := TypeEdgeField
+			if f.Embedded() {
+				kind = TypeEdgeEmbed
+			}
+			g.addEdgesTo(from, f.Type().GoType(), kind)
+		}
+		g.addMethodEdges(from, s.Methods())
+		for _, i := range s.ImplementInterfaces().InterfaceAll() {
+			g.addEdge(from, newTypeNodeID(i.PackageSummary(), i.Name().String()), TypeEdgeImplements)
+		}
+	}
+	for _, i := range r.Interfaces().InterfaceAll() {
+		from := newTypeNodeID(i.PackageSummary(), i.Name().String())
+		for _, e := range i.Embeds() {
+			g.addEdgesTo(from, e.Type().GoType(), TypeEdgeEmbed)
+		}
+		g.addMethodEdges(from, i.ExplicitMethods())
+	}
+	for _, dt := range r.DefinedTypes().DefinedTypeAll() {
+		from := newTypeNodeID(dt.PackageSummary(), dt.Name().String())
+		g.addEdgesTo(from, dt.UnderlyingType().GoType(), TypeEdgeUnderlying)
+		g.addMethodEdges(from, dt.Methods())
+		for _, i := range r.Interfaces().InterfaceAll() {
+			if dt.Implements(i) {
+				g.addEdge(from, newTypeNodeID(i.PackageSummary(), i.Name().String()), TypeEdgeImplements)
+			}
+		}
+	}
+	for _, a := range r.TypeAliases().AliasAll() {
+		from := newTypeNodeID(a.PackageSummary(), a.Name().String())
+		// 型別名の型は、型別名を辿った先の型として辺を追加する。
+		g.addEdgesTo(from, a.obj.Type(), TypeEdgeAlias)
+	}
+
+	for id := range g.out {
+		sortTypeEdges(g.out[id], func(e *TypeEdge) TypeNodeID { return e.to })
+	}
+	for id := range g.in {
+		sortTypeEdges(g.in[id], func(e *TypeEdge) TypeNodeID { return e.from })
+	}
+	return g
+}
+
+// TypeAt は、ファイル filename の line 行 column 列にある識別子が指す型の節のIDを返す。
+// line と column は1始まりで、 column はバイト単位とする。識別子が解析したパッケージの型を指していなければ ok は偽となる。
+func (r *Relations) TypeAt(filename string, line, column int) (id TypeNodeID, ok bool) {
+	filename = filepath.Clean(filename)
+	for _, pkg := range r.packages.AsSlice() {
+		if pkg.typesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.files {
+			tf := r.fset.File(file.Pos())
+			if tf == nil || filepath.Clean(tf.Name()) != filename || line < 1 || line > tf.LineCount() {
+				continue
+			}
+			pos := tf.LineStart(line) + token.Pos(column-1)
+
+			var ident *ast.Ident
+			ast.Inspect(file, func(n ast.Node) bool {
+				if n == nil || ident != nil || pos < n.Pos() || pos > n.End() {
+					return false
+				}
+				if i, ok := n.(*ast.Ident); ok {
+					ident = i
+				}
+				return true
+			})
+			if ident == nil {
+				return "", false
+			}
+			tn, ok := pkg.typesInfo.ObjectOf(ident).(*types.TypeName)
+			if !ok || tn.Pkg() == nil || !r.packages.Contains(PackagePath(tn.Pkg().Path())) {
+				return "", false
+			}
+			return TypeNodeID(fmt.Sprintf("%s.%s", tn.Pkg().Path(), tn.Name())), true
+		}
+	}
+	return "", false
+}
+
+func newTypeNodeID(ps *PackageSummary, name string) TypeNodeID {
+	return TypeNodeID(fmt.Sprintf("%s.%s", ps.Path(), name))
+}
+
+func (g *TypeGraph) addNode(kind TypeNodeKind, name string, ps *PackageSummary) {
+	id := newTypeNodeID(ps, name)
+	g.nodes[id] = &TypeNode{id: id, kind: kind, name: name, pkgSummary: ps}
+}
+
+// addEdgesTo は、 typ の基底となる型のうち、グラフの節である型への辺を追加する。
+func (g *TypeGraph) addEdgesTo(from TypeNodeID, typ types.Type, kind TypeEdgeKind) {
+	for _, named := range graphNamedTypes(typ) {
+		if named.Obj().Pkg() != nil {
+			g.addEdge(from, TypeNodeID(typeKey(named)), kind)
+		}
+	}
+}
+
+// graphNamedTypes は、 typ の基底となる名前付きの型の一覧を返す。
+// go/types が型別名を型として表す場合も、型別名を辿った先の型を返す。
+func graphNamedTypes(typ types.Type) []*types.Named {
+	switch t := unalias(typ).(type) {
+	case *types.Named:
+		return []*types.Named{t}
+	case *types.Slice:
+		return graphNamedTypes(t.Elem())
+	case *types.Array:
+		return graphNamedTypes(t.Elem())
+	case *types.Map:
+		return append(graphNamedTypes(t.Key()), graphNamedTypes(t.Elem())...)
+	case *types.Pointer:
+		return graphNamedTypes(t.Elem())
+	case *types.Chan:
+		return graphNamedTypes(t.Elem())
+	case *types.Signature:
+		var res []*types.Named
+		for i := 0; i < t.Params().Len(); i++ {
+			res = append(res, graphNamedTypes(t.Params().At(i).Type())...)
+		}
+		for i := 0; i < t.Results().Len(); i++ {
+			res = append(res, graphNamedTypes(t.Results().At(i).Type())...)
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+func (g *TypeGraph) addMethodEdges(from TypeNodeID, methods []*Function) {
+	for _, m := range methods {
+		for _, p := range m.Parameters() {
+			g.addEdgesTo(from, p.Type().GoType(), TypeEdgeParameter)
+		}
+		for _, rv := range m.ReturnValues() {
+			g.addEdgesTo(from, rv.Type().GoType(), TypeEdgeResult)
+		}
+	}
+}
+
+func (g *TypeGraph) addEdge(from, to TypeNodeID, kind TypeEdgeKind) {
+	if _, ok := g.nodes[to]; !ok {
+		return
+	}
+	key := TypeEdge{from: from, to: to, kind: kind}
+	if _, ok := g.edges[key]; ok {
+		return
+	}
+	g.edges[key] = struct{}{}
+	e := &TypeEdge{from: from, to: to, kind: kind}
+	g.out[from] = append(g.out[from], e)
+	g.in[to] = append(g.in[to], e)
+}
+
+func (n *TypeNode) ID() TypeNodeID {
+	return n.id
+}
+
+func (n *TypeNode) Kind() TypeNodeKind {
+	return n.kind
+}
+
+func (n *TypeNode) Name() string {
+	return n.name
+}
+
+func (n *TypeNode) PackageSummary() *PackageSummary {
+	return n.pkgSummary
+}
+
+func (e *TypeEdge) From() TypeNodeID {
+	return e.from
+}
+
+func (e *TypeEdge) To() TypeNodeID {
+	return e.to
+}
+
+func (e *TypeEdge) Kind() TypeEdgeKind {
+	return e.kind
+}
+
+// Nodes は、全ての節をIDの順で返す。
+func (g *TypeGraph) Nodes() []*TypeNode {
+	nodes := make([]*TypeNode, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].id < nodes[j].id
+	})
+	return nodes
+}
+
+func (g *TypeGraph) Node(id TypeNodeID) (node *TypeNode, ok bool) {
+	node, ok = g.nodes[id]
+	return node, ok
+}
+
+// Edges は、全ての辺を依存元、依存先、種類の順で返す。
+func (g *TypeGraph) Edges() []*TypeEdge {
+	var edges []*TypeEdge
+	for _, n := range g.Nodes() {
+		edges = append(edges, g.out[n.id]...)
+	}
+	return edges
+}
+
+// OutEdges は、 id の型からの依存の一覧を返す。
+func (g *TypeGraph) OutEdges(id TypeNodeID) []*TypeEdge {
+	return append([]*TypeEdge{}, g.out[id]...)
+}
+
+// InEdges は、 id の型への依存の一覧を返す。
+func (g *TypeGraph) InEdges(id TypeNodeID) []*TypeEdge {
+	return append([]*TypeEdge{}, g.in[id]...)
+}
+
+// Cycles は、型の間の循環依存を強連結成分として返す。自身への依存も循環とみなす。
+// kinds を指定した場合は、その種類の辺のみを辿る。
+func (g *TypeGraph) Cycles(kinds ...TypeEdgeKind) [][]TypeNodeID {
+	allowed := make(map[TypeEdgeKind]struct{})
+	for _, k := range kinds {
+		allowed[k] = struct{}{}
+	}
+	successors := func(id TypeNodeID) []TypeNodeID {
+		var res []TypeNodeID
+		for _, e := range g.out[id] {
+			if _, ok := allowed[e.kind]; len(allowed) == 0 || ok {
+				res = append(res, e.to)
+			}
+		}
+		return res
+	}
+
+	var ids []TypeNodeID
+	for _, n := range g.Nodes() {
+		ids = append(ids, n.id)
+	}
+
+	var cycles [][]TypeNodeID
+	for _, component := range stronglyConnectedComponents(ids, successors) {
+		if len(component) == 1 && !containsTypeNodeID(successors(component[0]), component[0]) {
+			continue
+		}
+		cycles = append(cycles, component)
+	}
+	return cycles
+}
+
+// WriteDOT は、依存グラフをGraphvizのDOT形式で書き出す。implements の辺は破線で表す。
+func (g *TypeGraph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph types {")
+	for _, n := range g.Nodes() {
+		shape := "box"
+		if n.kind == TypeNodeInterface {
+			shape = "ellipse"
+		}
+		fmt.Fprintf(bw, "\t%q [shape=%s];\n", n.id, shape)
+	}
+	for _, e := range g.Edges() {
+		style := ""
+		if e.kind == TypeEdgeImplements {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(bw, "\t%q -> %q [label=%q%s];\n", e.from, e.to, e.kind, style)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid は、依存グラフをMermaidのflowchart形式で書き出す。implements の辺は点線で表す。
+func (g *TypeGraph) WriteMermaid(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "graph LR")
+	ids := make(map[TypeNodeID]string)
+	for i, n := range g.Nodes() {
+		ids[n.id] = fmt.Sprintf("t%d", i)
+		if n.kind == TypeNodeInterface {
+			fmt.Fprintf(bw, "\t%s([%q])\n", ids[n.id], n.id)
+		} else {
+			fmt.Fprintf(bw, "\t%s[%q]\n", ids[n.id], n.id)
+		}
+	}
+	for _, e := range g.Edges() {
+		arrow := "-->"
+		if e.kind == TypeEdgeImplements {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(bw, "\t%s %s|%s| %s\n", ids[e.from], arrow, e.kind, ids[e.to])
+	}
+	return bw.Flush()
+}
+
+func sortTypeEdges(edges []*TypeEdge, key func(e *TypeEdge) TypeNodeID) {
+	sort.Slice(edges, func(i, j int) bool {
+		if key(edges[i]) != key(edges[j]) {
+			return key(edges[i]) < key(edges[j])
+		}
+		return edges[i].kind < edges[j].kind
+	})
+}
+
+func containsTypeNodeID(ids []TypeNodeID, id TypeNodeID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// stronglyConnectedComponents は、Tarjanのアルゴリズムで強連結成分を求める。
+// 各成分の節はIDの順、成分は先頭の節のIDの順に並べる。
+func stronglyConnectedComponents(ids []TypeNodeID, successors func(TypeNodeID) []TypeNodeID) [][]TypeNodeID {
+	index := make(map[TypeNodeID]int)
+	lowLink := make(map[TypeNodeID]int)
+	onStack := make(map[TypeNodeID]bool)
+	var stack []TypeNodeID
+	var components [][]TypeNodeID
+
+	var strongConnect func(v TypeNodeID)
+	strongConnect = func(v TypeNodeID) {
+		index[v] = len(index)
+		lowLink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range successors(v) {
+			if _, ok := index[w]; !ok {
+				strongConnect(w)
+				if lowLink[w] < lowLink[v] {
+					lowLink[v] = lowLink[w]
+				}
+			} else if onStack[w] && index[w] < lowLink[v] {
+				lowLink[v] = index[w]
+			}
+		}
+
+		if lowLink[v] == index[v] {
+			var component []TypeNodeID
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Slice(component, func(i, j int) bool {
+				return component[i] < component[j]
+			})
+			components = append(components, component)
+		}
+	}
+
+	for _, id := range ids {
+		if _, ok := index[id]; !ok {
+			strongConnect(id)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+	return components
+}
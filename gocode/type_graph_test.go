@@ -0,0 +1,197 @@
+package gocode_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+func TestRelations_TypeGraph(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"model/model.go": `package model
+
+type ID string
+
+type Node struct {
+	ID       ID
+	Children []*Node
+	Parent   *Tree
+}
+
+type Tree struct {
+	Root *Node
+}
+
+type NodeAlias = Node
+`,
+		"store/store.go": `package store
+
+import "example.com/testmodule/model"
+
+type Reader interface {
+	Find(id model.ID) (*model.Node, error)
+}
+
+type Store struct {
+	Reader
+	nodes map[model.ID]*model.Node
+}
+
+func (s *Store) Find(id model.ID) (*model.Node, error) { return nil, nil }
+`,
+	})
+
+	g := r.TypeGraph()
+
+	var nodes []string
+	for _, n := range g.Nodes() {
+		nodes = append(nodes, fmt.Sprintf("%s(%s)", n.ID(), n.Kind()))
+	}
+	wantNodes := []string{
+		"example.com/testmodule/model.ID(defined type)",
+		"example.com/testmodule/model.Node(struct)",
+		"example.com/testmodule/model.NodeAlias(type alias)",
+		"example.com/testmodule/model.Tree(struct)",
+		"example.com/testmodule/store.Reader(interface)",
+		"example.com/testmodule/store.Store(struct)",
+	}
+	if strings.Join(nodes, "\n") != strings.Join(wantNodes, "\n") {
+		t.Errorf("Nodes() = %v, want %v", nodes, wantNodes)
+	}
+
+	var edges []string
+	for _, e := range g.OutEdges("example.com/testmodule/store.Store") {
+		edges = append(edges, fmt.Sprintf("%s %s", e.Kind(), e.To()))
+	}
+	wantEdges := []string{
+		"field example.com/testmodule/model.ID",
+		"parameter example.com/testmodule/model.ID",
+		"field example.com/testmodule/model.Node",
+		"result example.com/testmodule/model.Node",
+		"embed example.com/testmodule/store.Reader",
+		"implements example.com/testmodule/store.Reader",
+	}
+	if strings.Join(edges, "\n") != strings.Join(wantEdges, "\n") {
+		t.Errorf("OutEdges(Store) = %v, want %v", edges, wantEdges)
+	}
+
+	var aliasEdges []string
+	for _, e := range g.InEdges("example.com/testmodule/model.Node") {
+		if e.Kind() == gocode.TypeEdgeAlias {
+			aliasEdges = append(aliasEdges, e.From().String())
+		}
+	}
+	if strings.Join(aliasEdges, ",") != "example.com/testmodule/model.NodeAlias" {
+		t.Errorf("InEdges(Node) alias = %v, want [example.com/testmodule/model.NodeAlias]", aliasEdges)
+	}
+
+	var cycles []string
+	for _, c := range g.Cycles() {
+		var ids []string
+		for _, id := range c {
+			ids = append(ids, id.String())
+		}
+		cycles = append(cycles, strings.Join(ids, ","))
+	}
+	wantCycles := []string{
+		"example.com/testmodule/model.Node,example.com/testmodule/model.Tree",
+	}
+	if strings.Join(cycles, "\n") != strings.Join(wantCycles, "\n") {
+		t.Errorf("Cycles() = %v, want %v", cycles, wantCycles)
+	}
+	if got := g.Cycles(gocode.TypeEdgeParameter, gocode.TypeEdgeResult); len(got) != 0 {
+		t.Errorf("Cycles(parameter, result) = %v, want no cycles", got)
+	}
+
+	var dot bytes.Buffer
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dot.String(), `"example.com/testmodule/store.Store" -> "example.com/testmodule/store.Reader" [label="implements", style=dashed];`) {
+		t.Errorf("WriteDOT() = \n%s\nwant implements edge", dot.String())
+	}
+
+	var mermaid bytes.Buffer
+	if err := g.WriteMermaid(&mermaid); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(mermaid.String(), "graph LR\n") || !strings.Contains(mermaid.String(), "t5 -.->|implements| t4") {
+		t.Errorf("WriteMermaid() = \n%s\nwant implements edge from t5 to t4", mermaid.String())
+	}
+}
+
+func TestRelations_TypeGraph_Alias(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"model/model.go": `package model
+
+type Node struct{}
+
+type NodeAlias = Node
+
+type AliasOfAlias = NodeAlias
+
+type Cache struct {
+	last  *NodeAlias
+	nodes map[string][]AliasOfAlias
+}
+
+func (c *Cache) Get(key string) (AliasOfAlias, bool) { return Node{}, false }
+`,
+	})
+
+	g := r.TypeGraph()
+
+	// 型別名を介した型も、型別名を辿った先の型への辺となる。
+	var edges []string
+	for _, e := range g.OutEdges("example.com/testmodule/model.Cache") {
+		edges = append(edges, fmt.Sprintf("%s %s", e.Kind(), e.To()))
+	}
+	wantEdges := []string{
+		"field example.com/testmodule/model.Node",
+		"result example.com/testmodule/model.Node",
+	}
+	if strings.Join(edges, "\n") != strings.Join(wantEdges, "\n") {
+		t.Errorf("OutEdges(Cache) = %v, want %v", edges, wantEdges)
+	}
+
+	for _, id := range []gocode.TypeNodeID{"example.com/testmodule/model.NodeAlias", "example.com/testmodule/model.AliasOfAlias"} {
+		var edges []string
+		for _, e := range g.OutEdges(id) {
+			edges = append(edges, fmt.Sprintf("%s %s", e.Kind(), e.To()))
+		}
+		if strings.Join(edges, ",") != "alias example.com/testmodule/model.Node" {
+			t.Errorf("OutEdges(%s) = %v, want [alias example.com/testmodule/model.Node]", id, edges)
+		}
+	}
+}
+
+func TestPackageGraph_WriteMermaid(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"a/a.go": `package a
+
+import "example.com/testmodule/b"
+
+var _ = b.B
+`,
+		"b/b.go": `package b
+
+const B = 1
+`,
+	})
+
+	var buf bytes.Buffer
+	if err := r.PackageGraph().WriteMermaid(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `graph LR
+	p0["example.com/testmodule/a"]
+	p1["example.com/testmodule/b"]
+	p0 --> p1
+`
+	if buf.String() != want {
+		t.Errorf("WriteMermaid() = \n%s\nwant\n%s", buf.String(), want)
+	}
+}
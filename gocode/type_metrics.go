@@ -0,0 +1,282 @@
+package gocode
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+type (
+	// TypeMetric は、型のメトリクスの項目を表す。
+	TypeMetric string
+
+	// TypeMetrics は、structまたはdefined typeの複雑さのメトリクスを表す。
+	TypeMetrics struct {
+		// kind は "struct" または "defined type" 。
+		kind       string
+		name       string
+		typ        *Type
+		pkgSummary *PackageSummary
+		values     map[TypeMetric]int
+		// fanOutPackages はフィールドとメソッドの型が参照する、型が所属するパッケージ以外のパッケージの一覧。
+		fanOutPackages []PackagePath
+	}
+)
+
+const (
+	TypeMetricFields          TypeMetric = "fields"
+	TypeMetricExportedFields  TypeMetric = "exported-fields"
+	TypeMetricMethods         TypeMetric = "methods"
+	TypeMetricExportedMethods TypeMetric = "exported-methods"
+	TypeMetricInterfaces      TypeMetric = "interfaces"
+	TypeMetricFanOut          TypeMetric = "fan-out"
+	TypeMetricEmbeddingDepth  TypeMetric = "embedding-depth"
+)
+
+// typeMetricOrder は、表やCSVに出力するメトリクスの順序。
+var typeMetricOrder = []TypeMetric{
+	TypeMetricFields,
+	TypeMetricExportedFields,
+	TypeMetricMethods,
+	TypeMetricExportedMethods,
+	TypeMetricInterfaces,
+	TypeMetricFanOut,
+	TypeMetricEmbeddingDepth,
+}
+
+func (m TypeMetric) String() string {
+	return string(m)
+}
+
+// ParseTypeMetric は、メトリクスの項目名を TypeMetric に変換する。
+func ParseTypeMetric(s string) (TypeMetric, error) {
+	for _, m := range typeMetricOrder {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown type metric: %q", s)
+}
+
+// TypeMetrics は、解析した全てのstructとdefined typeのメトリクスをパッケージパスと型名の順で返す。
+func (r *Relations) TypeMetrics() []*TypeMetrics {
+	var res []*TypeMetrics
+	for _, s := range r.Structs().StructAll() {
+		m := newTypeMetrics("struct", s.Name().String(), s.Type(), s.PackageSummary())
+		m.countFields(s.Fields())
+		m.countMethods(s.Methods())
+		m.values[TypeMetricInterfaces] = len(s.ImplementInterfaces().InterfaceAll())
+		for _, sel := range s.AllFields() {
+			if sel.Field().Embedded() && sel.Depth()+1 > m.values[TypeMetricEmbeddingDepth] {
+				m.values[TypeMetricEmbeddingDepth] = sel.Depth() + 1
+			}
+		}
+		m.countFanOut(s.Fields(), s.Methods())
+		res = append(res, m)
+	}
+	for _, dt := range r.DefinedTypes().DefinedTypeAll() {
+		m := newTypeMetrics("defined type", dt.Name().String(), dt.Type(), dt.PackageSummary())
+		m.countMethods(dt.Methods())
+		for _, i := range r.Interfaces().InterfaceAll() {
+			if dt.Implements(i) {
+				m.values[TypeMetricInterfaces]++
+			}
+		}
+		m.countFanOut(nil, dt.Methods())
+		res = append(res, m)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].pkgSummary.Path() != res[j].pkgSummary.Path() {
+			return res[i].pkgSummary.Path() < res[j].pkgSummary.Path()
+		}
+		return res[i].name < res[j].name
+	})
+	return res
+}
+
+func newTypeMetrics(kind, name string, typ *Type, ps *PackageSummary) *TypeMetrics {
+	return &TypeMetrics{
+		kind:       kind,
+		name:       name,
+		typ:        typ,
+		pkgSummary: ps,
+		values:     make(map[TypeMetric]int),
+	}
+}
+
+func (m *TypeMetrics) countFields(fields []*Field) {
+	for _, f := range fields {
+		m.values[TypeMetricFields]++
+		if f.Exported() {
+			m.values[TypeMetricExportedFields]++
+		}
+	}
+}
+
+func (m *TypeMetrics) countMethods(methods []*Function) {
+	for _, fn := range methods {
+		m.values[TypeMetricMethods]++
+		if fn.Exported() {
+			m.values[TypeMetricExportedMethods]++
+		}
+	}
+}
+
+// countFanOut は、フィールドとメソッドのパラメータ、戻り値の型が参照するパッケージを数える。
+func (m *TypeMetrics) countFanOut(fields []*Field, methods []*Function) {
+	var types []*Type
+	for _, f := range fields {
+		types = append(types, f.Type())
+	}
+	for _, fn := range methods {
+		for _, p := range fn.Parameters() {
+			types = append(types, p.Type())
+		}
+		for _, rv := range fn.ReturnValues() {
+			types = append(types, rv.Type())
+		}
+	}
+
+	packages := make(map[PackagePath]struct{})
+	for _, t := range types {
+		for _, ft := range t.FundamentalTypes() {
+			path := ft.PackageSummary().Path()
+			if ft.Builtin() || path == "" || path == m.pkgSummary.Path() {
+				continue
+			}
+			packages[path] = struct{}{}
+		}
+	}
+	for path := range packages {
+		m.fanOutPackages = append(m.fanOutPackages, path)
+	}
+	sort.Slice(m.fanOutPackages, func(i, j int) bool {
+		return m.fanOutPackages[i] < m.fanOutPackages[j]
+	})
+	m.values[TypeMetricFanOut] = len(m.fanOutPackages)
+}
+
+// Kind は、 "struct" または "defined type" を返す。
+func (m *TypeMetrics) Kind() string {
+	return m.kind
+}
+
+func (m *TypeMetrics) Name() string {
+	return m.name
+}
+
+func (m *TypeMetrics) Type() *Type {
+	return m.typ
+}
+
+func (m *TypeMetrics) PackageSummary() *PackageSummary {
+	return m.pkgSummary
+}
+
+// Value は、メトリクスの項目 metric の値を返す。
+func (m *TypeMetrics) Value(metric TypeMetric) int {
+	return m.values[metric]
+}
+
+// Fields は、フィールドの数を返す。埋め込まれたフィールドを含み、昇格したフィールドは含まない。
+func (m *TypeMetrics) Fields() int {
+	return m.values[TypeMetricFields]
+}
+
+func (m *TypeMetrics) ExportedFields() int {
+	return m.values[TypeMetricExportedFields]
+}
+
+// Methods は、型に宣言されたメソッドの数を返す。昇格したメソッドは含まない。
+func (m *TypeMetrics) Methods() int {
+	return m.values[TypeMetricMethods]
+}
+
+func (m *TypeMetrics) ExportedMethods() int {
+	return m.values[TypeMetricExportedMethods]
+}
+
+// Interfaces は、実装している解析したinterfaceの数を返す。
+func (m *TypeMetrics) Interfaces() int {
+	return m.values[TypeMetricInterfaces]
+}
+
+// FanOut は、フィールドとメソッドの型が参照する、型が所属するパッケージ以外のパッケージの数を返す。
+func (m *TypeMetrics) FanOut() int {
+	return m.values[TypeMetricFanOut]
+}
+
+// FanOutPackages は、フィールドとメソッドの型が参照する、型が所属するパッケージ以外のパッケージの一覧を返す。
+func (m *TypeMetrics) FanOutPackages() []PackagePath {
+	return append([]PackagePath{}, m.fanOutPackages...)
+}
+
+// EmbeddingDepth は、埋め込みの最大の深さを返す。埋め込みがなければ0となる。
+func (m *TypeMetrics) EmbeddingDepth() int {
+	return m.values[TypeMetricEmbeddingDepth]
+}
+
+// RankTypeMetrics は、 metrics を metric の値の降順に並べ替えた上位 limit 件を返す。
+// limit が0以下の場合は全件を返す。値が等しい場合はパッケージパスと型名の順となる。
+func RankTypeMetrics(metrics []*TypeMetrics, metric TypeMetric, limit int) []*TypeMetrics {
+	ranked := append([]*TypeMetrics{}, metrics...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		vi, vj := ranked[i].values[metric], ranked[j].values[metric]
+		if vi != vj {
+			return vi > vj
+		}
+		if ranked[i].pkgSummary.Path() != ranked[j].pkgSummary.Path() {
+			return ranked[i].pkgSummary.Path() < ranked[j].pkgSummary.Path()
+		}
+		return ranked[i].name < ranked[j].name
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// WriteTypeMetricsTable は、 metrics を表形式で w に書き出す。
+func WriteTypeMetricsTable(w io.Writer, metrics []*TypeMetrics) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprint(tw, "TYPE\tKIND")
+	for _, metric := range typeMetricOrder {
+		fmt.Fprintf(tw, "\t%s", metric)
+	}
+	fmt.Fprintln(tw)
+	for _, m := range metrics {
+		fmt.Fprintf(tw, "%s.%s\t%s", m.pkgSummary.Path(), m.name, m.kind)
+		for _, metric := range typeMetricOrder {
+			fmt.Fprintf(tw, "\t%d", m.values[metric])
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// WriteTypeMetricsCSV は、 metrics をヘッダ付きのCSVで w に書き出す。
+func WriteTypeMetricsCSV(w io.Writer, metrics []*TypeMetrics) error {
+	cw := csv.NewWriter(w)
+	header := []string{"package", "type", "kind"}
+	for _, metric := range typeMetricOrder {
+		header = append(header, metric.String())
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, m := range metrics {
+		record := []string{m.pkgSummary.Path().String(), m.name, m.kind}
+		for _, metric := range typeMetricOrder {
+			record = append(record, strconv.Itoa(m.values[metric]))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
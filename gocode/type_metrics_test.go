@@ -0,0 +1,137 @@
+package gocode_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+func TestRelations_TypeMetrics(t *testing.T) {
+	r := loadTestModule(t, map[string]string{
+		"model/model.go": `package model
+
+type ID string
+
+func (id ID) String() string { return string(id) }
+`,
+		"server/server.go": `package server
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"example.com/testmodule/model"
+)
+
+type Base struct {
+	ID model.ID
+}
+
+type Config struct {
+	Base
+	Timeout time.Duration
+}
+
+type Server struct {
+	Config
+	handler http.Handler
+	Name    string
+	count   int
+}
+
+func (s *Server) Start() error                 { return nil }
+func (s *Server) Write(p []byte) (int, error)  { return 0, nil }
+func (s *Server) Close() error                 { return nil }
+func (s *Server) log(w io.Writer, args ...int) {}
+
+type Level int
+
+func (l Level) String() string { return "" }
+`,
+	})
+
+	var buf bytes.Buffer
+	if err := gocode.WriteTypeMetricsCSV(&buf, r.TypeMetrics()); err != nil {
+		t.Fatal(err)
+	}
+	want := `package,type,kind,fields,exported-fields,methods,exported-methods,interfaces,fan-out,embedding-depth
+example.com/testmodule/model,ID,defined type,0,0,1,1,0,0,0
+example.com/testmodule/server,Base,struct,1,1,0,0,0,1,0
+example.com/testmodule/server,Config,struct,2,2,0,0,0,1,1
+example.com/testmodule/server,Level,defined type,0,0,1,1,0,0,0
+example.com/testmodule/server,Server,struct,4,2,4,3,0,2,2
+`
+	if buf.String() != want {
+		t.Errorf("WriteTypeMetricsCSV() = \n%s\nwant\n%s", buf.String(), want)
+	}
+
+	var got []string
+	for _, m := range gocode.RankTypeMetrics(r.TypeMetrics(), gocode.TypeMetricFields, 2) {
+		got = append(got, m.Name())
+	}
+	if strings.Join(got, ",") != "Server,Config" {
+		t.Errorf("RankTypeMetrics(fields, 2) = %v, want [Server Config]", got)
+	}
+
+	server := gocode.RankTypeMetrics(r.TypeMetrics(), gocode.TypeMetricMethods, 1)[0]
+	var packages []string
+	for _, p := range server.FanOutPackages() {
+		packages = append(packages, p.String())
+	}
+	if strings.Join(packages, ",") != "io,net/http" {
+		t.Errorf("FanOutPackages() = %v, want [io net/http]", packages)
+	}
+}
+
+func TestRelations_TypeMetrics_IncludeEmptyInterfaces(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"api/api.go": `package api
+
+type Empty interface{}
+
+type Stringer interface {
+	String() string
+}
+
+type Name struct{}
+
+func (n Name) String() string { return "" }
+
+type Level int
+
+func (l Level) String() string { return "" }
+`,
+	})
+
+	tests := []struct {
+		name    string
+		include bool
+		want    int
+	}{
+		{name: "excluded", include: false, want: 1},
+		{name: "included", include: true, want: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := gocode.LoadRelations(&gocode.LoadOptions{
+				FileSystem:             afero.NewOsFs(),
+				Directories:            []string{dir},
+				Recursive:              true,
+				IncludeEmptyInterfaces: test.include,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// structと定義型は同じ規則でinterfaceを数える。
+			for _, m := range r.TypeMetrics() {
+				if got := m.Interfaces(); got != test.want {
+					t.Errorf("%s: Interfaces() = %d, want %d", m.Name(), got, test.want)
+				}
+			}
+		})
+	}
+}
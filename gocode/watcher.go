@@ -0,0 +1,242 @@
+package gocode
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+type (
+	// RelationsWatcher は、 LoadOptions のディレクトリを定期的に調べ、Goのファイルが変更されたパッケージのみを再度解析する。
+	// 変更の検出は FileSystem のファイルの更新時刻と大きさの比較によって行うため、afero の任意の FileSystem で動作する。
+	RelationsWatcher struct {
+		options   LoadOptions
+		interval  time.Duration
+		mu        sync.RWMutex
+		relations *Relations
+		// files は前回調べた時点のディレクトリごとのGoのファイルの状態。
+		files  map[string]map[string]fileStamp
+		events chan *RelationsChangeEvent
+		stop   chan struct{}
+		done   chan struct{}
+		// pollMu は Poll が同時に実行されないようにする。
+		pollMu sync.Mutex
+		once   sync.Once
+	}
+
+	// RelationsChangeEvent は、 RelationsWatcher が検出した変更と再度解析した結果を表す。
+	RelationsChangeEvent struct {
+		directories []string
+		reloaded    []PackagePath
+		removed     []PackagePath
+		err         error
+	}
+
+	// fileStamp は、変更の検出に使うファイルの状態。
+	fileStamp struct {
+		modTime time.Time
+		size    int64
+	}
+)
+
+// DefaultWatchInterval は、 WatchRelations で間隔を指定しなかった場合にファイルを調べる間隔。
+const DefaultWatchInterval = time.Second
+
+// WatchRelations は、 options のディレクトリを解析し、 interval ごとに変更を調べる RelationsWatcher を開始する。
+// interval が0以下の場合は DefaultWatchInterval となる。停止するには Close を呼び出す。
+func WatchRelations(options *LoadOptions, interval time.Duration) (*RelationsWatcher, error) {
+	w, err := NewRelationsWatcher(options)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	w.interval = interval
+	go w.run()
+	return w, nil
+}
+
+// NewRelationsWatcher は、 options のディレクトリを解析した RelationsWatcher を生成する。
+// 定期的には調べず、変更は Poll を呼び出した時のみ検出する。
+func NewRelationsWatcher(options *LoadOptions) (*RelationsWatcher, error) {
+	w := &RelationsWatcher{
+		options: *options,
+		events:  make(chan *RelationsChangeEvent, 16),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	files, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	r, err := LoadRelations(options)
+	if err != nil {
+		return nil, err
+	}
+	w.relations = r
+	w.files = files
+	return w, nil
+}
+
+// View は、解析結果を読み取る fn を、再度の解析と同時に実行されないように呼び出す。
+// fn の外で Relations やその要素を保持して使ってはならない。
+func (w *RelationsWatcher) View(fn func(r *Relations)) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	fn(w.relations)
+}
+
+// Events は、変更を検出して再度解析する度に送られるイベントのチャネルを返す。Close の後に閉じられる。
+// イベントを受け取らなければ、チャネルのバッファが空くまで次の変更の検出が止まる。
+func (w *RelationsWatcher) Events() <-chan *RelationsChangeEvent {
+	return w.events
+}
+
+// Close は、変更の監視を停止して Events のチャネルを閉じる。
+func (w *RelationsWatcher) Close() error {
+	w.once.Do(func() {
+		close(w.stop)
+		if w.interval > 0 {
+			<-w.done
+		}
+		w.pollMu.Lock()
+		close(w.events)
+		w.pollMu.Unlock()
+	})
+	return nil
+}
+
+func (w *RelationsWatcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.Poll()
+		}
+	}
+}
+
+// Poll は、前回から変更されたディレクトリを調べ、変更があればそのディレクトリのパッケージを再度解析する。
+// 変更がなければ ok は偽となる。変更を検出した場合は、同じイベントを Events のチャネルにも送る。
+func (w *RelationsWatcher) Poll() (event *RelationsChangeEvent, ok bool) {
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+	select {
+	case <-w.stop:
+		return nil, false
+	default:
+	}
+
+	files, err := w.scan()
+	if err != nil {
+		event = &RelationsChangeEvent{err: err}
+	} else {
+		dirs := changedDirectories(w.files, files)
+		if len(dirs) == 0 {
+			return nil, false
+		}
+		event = &RelationsChangeEvent{directories: dirs}
+		w.mu.Lock()
+		event.reloaded, event.removed, event.err = w.relations.Reload(dirs, nil)
+		w.mu.Unlock()
+		if event.err == nil {
+			w.files = files
+		}
+	}
+
+	select {
+	case w.events <- event:
+	case <-w.stop:
+	}
+	return event, true
+}
+
+// scan は、監視対象のディレクトリごとにGoのファイルの状態を集める。
+func (w *RelationsWatcher) scan() (map[string]map[string]fileStamp, error) {
+	res := make(map[string]map[string]fileStamp)
+	err := walkDirectories(&w.options, func(dir string) error {
+		infos, err := afero.ReadDir(w.options.FileSystem, dir)
+		if err != nil {
+			return err
+		}
+		files := make(map[string]fileStamp)
+		for _, info := range infos {
+			if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") {
+				continue
+			}
+			files[filepath.Join(dir, info.Name())] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		res[dir] = files
+		return nil
+	}, nil)
+	if errors.Is(err, os.ErrNotExist) {
+		return res, nil
+	}
+	return res, err
+}
+
+// changedDirectories は、Goのファイルが追加、削除または変更されたディレクトリの一覧を返す。
+func changedDirectories(before, after map[string]map[string]fileStamp) []string {
+	changed := make(map[string]struct{})
+	for dir, files := range after {
+		if !sameFileStamps(before[dir], files) {
+			changed[dir] = struct{}{}
+		}
+	}
+	for dir, files := range before {
+		if _, ok := after[dir]; !ok && len(files) > 0 {
+			changed[dir] = struct{}{}
+		}
+	}
+
+	dirs := make([]string, 0, len(changed))
+	for dir := range changed {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+func sameFileStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		other, ok := b[path]
+		if !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
+
+// Directories は、変更を検出したディレクトリの一覧を返す。
+func (e *RelationsChangeEvent) Directories() []string {
+	return append([]string{}, e.directories...)
+}
+
+// Reloaded は、再度解析したパッケージの一覧を返す。
+func (e *RelationsChangeEvent) Reloaded() []PackagePath {
+	return append([]PackagePath{}, e.reloaded...)
+}
+
+// Removed は、ファイルが無くなったなどの理由で解析結果から取り除いたパッケージの一覧を返す。
+func (e *RelationsChangeEvent) Removed() []PackagePath {
+	return append([]PackagePath{}, e.removed...)
+}
+
+// Err は、変更の検出または再度の解析に失敗した場合のエラーを返す。
+func (e *RelationsChangeEvent) Err() error {
+	return e.err
+}
@@ -0,0 +1,192 @@
+package gocode_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+func TestRelationsWatcher_Poll(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"port/port.go": `package port
+
+type Store interface {
+	Save(key string) error
+}
+`,
+		"memory/memory.go": `package memory
+
+type Cache struct{}
+`,
+		"legacy/legacy.go": `package legacy
+
+type Old struct{}
+`,
+	})
+	w, err := gocode.NewRelationsWatcher(&gocode.LoadOptions{
+		FileSystem:  afero.NewOsFs(),
+		Directories: []string{dir},
+		Recursive:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, ok := w.Poll(); ok {
+		t.Fatal("Poll() detected changes without any modification")
+	}
+
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// 更新時刻の分解能に依らず変更を検出できるように、更新時刻を進める。
+		future := time.Now().Add(time.Minute)
+		if err := os.Chtimes(path, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("memory/memory.go", `package memory
+
+type Cache struct{}
+
+func (c *Cache) Save(key string) error { return nil }
+`)
+	if err := os.RemoveAll(filepath.Join(dir, "legacy")); err != nil {
+		t.Fatal(err)
+	}
+
+	event, ok := w.Poll()
+	if !ok {
+		t.Fatal("Poll() did not detect changes")
+	}
+	if event.Err() != nil {
+		t.Fatal(event.Err())
+	}
+	if got := packagePathsString(event.Reloaded()); got != "example.com/testmodule/memory" {
+		t.Errorf("Reloaded() = %s, want example.com/testmodule/memory", got)
+	}
+	if got := packagePathsString(event.Removed()); got != "example.com/testmodule/legacy" {
+		t.Errorf("Removed() = %s, want example.com/testmodule/legacy", got)
+	}
+	if received := <-w.Events(); received != event {
+		t.Errorf("Events() received %+v, want the event returned by Poll()", received)
+	}
+
+	w.View(func(r *gocode.Relations) {
+		cache, ok := r.Structs().Get("memory", "Cache")
+		if !ok {
+			t.Fatal("memory.Cache not found")
+		}
+		if _, ok := cache.ImplementInterfaces().Get("port", "Store"); !ok {
+			t.Error("memory.Cache does not implement port.Store after reload")
+		}
+		if r.Structs().Contains("legacy", "Old") {
+			t.Error("legacy.Old remains after its package was removed")
+		}
+	})
+
+	if _, ok := w.Poll(); ok {
+		t.Error("Poll() detected changes twice for the same modification")
+	}
+}
+
+// hiddenDirFs は、 hidden のディレクトリが存在しないものとして振る舞うファイルシステム。
+type hiddenDirFs struct {
+	afero.Fs
+	hidden map[string]bool
+}
+
+func (fs *hiddenDirFs) Stat(name string) (os.FileInfo, error) {
+	if fs.hidden[filepath.Clean(name)] {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return fs.Fs.Stat(name)
+}
+
+func TestRelations_Reload_FileSystem(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"legacy/legacy.go": "package legacy\n\ntype Old struct{}\n",
+	})
+	fs := &hiddenDirFs{Fs: afero.NewOsFs(), hidden: make(map[string]bool)}
+	r, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:  fs,
+		Directories: []string{dir},
+		Recursive:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ディスク上に残っていても、 FileSystem から無くなったディレクトリのパッケージは取り除く。
+	legacy := filepath.Join(dir, "legacy")
+	fs.hidden[legacy] = true
+	reloaded, removed, err := r.Reload([]string{legacy}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := packagePathsString(reloaded); got != "" {
+		t.Errorf("Reload() reloaded = %s, want none", got)
+	}
+	if got := packagePathsString(removed); got != "example.com/testmodule/legacy" {
+		t.Errorf("Reload() removed = %s, want example.com/testmodule/legacy", got)
+	}
+}
+
+func packagePathsString(paths []gocode.PackagePath) string {
+	var s []string
+	for _, p := range paths {
+		s = append(s, p.String())
+	}
+	return strings.Join(s, ",")
+}
+
+func TestRelations_Reload_Filter(t *testing.T) {
+	dir := writeTestModule(t, map[string]string{
+		"app/app.go":          "package app\n\ntype App struct{}\n",
+		"legacy/legacy.go":    "package legacy\n\ntype Old struct{}\n",
+		"vendor/v/v.go":       "package v\n\ntype V struct{}\n",
+		".hidden/h.go":        "package hidden\n\ntype H struct{}\n",
+		"internal/gen/gen.go": "package gen\n\ntype Gen struct{}\n",
+	})
+	outside := writeTestModule(t, map[string]string{
+		"other/other.go": "package other\n\ntype Other struct{}\n",
+	})
+	r, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:         afero.NewOsFs(),
+		Directories:        []string{dir},
+		IgnoredDirectories: []string{filepath.Join(dir, "legacy")},
+		DirectoryFilter:    gocode.PathFilter{Exclude: []string{"internal/**"}},
+		Recursive:          true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 走査で除いたディレクトリと解析対象外のディレクトリは、再度の解析を指定されても解析しない。
+	directories := []string{
+		filepath.Join(dir, "legacy"),
+		filepath.Join(dir, "vendor", "v"),
+		filepath.Join(dir, ".hidden"),
+		filepath.Join(dir, "internal", "gen"),
+		filepath.Join(outside, "other"),
+		filepath.Join(dir, "app"),
+	}
+	reloaded, _, err := r.Reload(directories, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := packagePathsString(reloaded); got != "example.com/testmodule/app" {
+		t.Errorf("Reload() reloaded = %s, want example.com/testmodule/app", got)
+	}
+	if got := len(r.Packages().AsSlice()); got != 1 {
+		t.Errorf("number of packages = %d, want 1", got)
+	}
+}
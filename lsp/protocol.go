@@ -0,0 +1,169 @@
+package lsp
+
+import "encoding/json"
+
+// LSPの仕様のうち、このサーバが扱う型のみを定義する。
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/
+
+type (
+	Position struct {
+		// Line は0始まりの行番号。
+		Line uint32 `json:"line"`
+		// Character は0始まりのUTF-16のコード単位での列番号。
+		Character uint32 `json:"character"`
+	}
+
+	Range struct {
+		Start Position `json:"start"`
+		End   Position `json:"end"`
+	}
+
+	Location struct {
+		URI   string `json:"uri"`
+		Range Range  `json:"range"`
+	}
+
+	TextDocumentIdentifier struct {
+		URI string `json:"uri"`
+	}
+
+	TextDocumentItem struct {
+		URI        string `json:"uri"`
+		LanguageID string `json:"languageId"`
+		Version    int    `json:"version"`
+		Text       string `json:"text"`
+	}
+
+	TextDocumentPositionParams struct {
+		TextDocument TextDocumentIdentifier `json:"textDocument"`
+		Position     Position               `json:"position"`
+	}
+
+	WorkspaceFolder struct {
+		URI  string `json:"uri"`
+		Name string `json:"name"`
+	}
+
+	InitializeParams struct {
+		RootURI          string            `json:"rootUri"`
+		WorkspaceFolders []WorkspaceFolder `json:"workspaceFolders"`
+	}
+
+	InitializeResult struct {
+		Capabilities ServerCapabilities `json:"capabilities"`
+		ServerInfo   ServerInfo         `json:"serverInfo"`
+	}
+
+	ServerInfo struct {
+		Name string `json:"name"`
+	}
+
+	ServerCapabilities struct {
+		TextDocumentSync       TextDocumentSyncOptions `json:"textDocumentSync"`
+		ImplementationProvider bool                    `json:"implementationProvider"`
+		TypeHierarchyProvider  bool                    `json:"typeHierarchyProvider"`
+	}
+
+	TextDocumentSyncOptions struct {
+		OpenClose bool                 `json:"openClose"`
+		Change    TextDocumentSyncKind `json:"change"`
+		Save      SaveOptions          `json:"save"`
+	}
+
+	// TextDocumentSyncKind は、編集中の内容の同期方法を表す。
+	TextDocumentSyncKind int
+
+	SaveOptions struct {
+		IncludeText bool `json:"includeText"`
+	}
+
+	DidOpenTextDocumentParams struct {
+		TextDocument TextDocumentItem `json:"textDocument"`
+	}
+
+	DidChangeTextDocumentParams struct {
+		TextDocument   TextDocumentIdentifier           `json:"textDocument"`
+		ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+	}
+
+	// TextDocumentContentChangeEvent は、ドキュメント全体の新しい内容を表す。
+	// サーバは TextDocumentSyncKindFull のみに対応するため、範囲は扱わない。
+	TextDocumentContentChangeEvent struct {
+		Text string `json:"text"`
+	}
+
+	DidSaveTextDocumentParams struct {
+		TextDocument TextDocumentIdentifier `json:"textDocument"`
+		Text         *string                `json:"text,omitempty"`
+	}
+
+	DidCloseTextDocumentParams struct {
+		TextDocument TextDocumentIdentifier `json:"textDocument"`
+	}
+
+	// SymbolKind は、LSPのシンボルの種類を表す。
+	SymbolKind int
+
+	TypeHierarchyItem struct {
+		Name           string     `json:"name"`
+		Kind           SymbolKind `json:"kind"`
+		Detail         string     `json:"detail,omitempty"`
+		URI            string     `json:"uri"`
+		Range          Range      `json:"range"`
+		SelectionRange Range      `json:"selectionRange"`
+		// Data は型の "パッケージパス.型名" を保持し、supertypes と subtypes の要求で使う。
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+
+	TypeHierarchySupertypesParams struct {
+		Item TypeHierarchyItem `json:"item"`
+	}
+
+	TypeHierarchySubtypesParams struct {
+		Item TypeHierarchyItem `json:"item"`
+	}
+
+	LogMessageParams struct {
+		Type    MessageType `json:"type"`
+		Message string      `json:"message"`
+	}
+
+	// MessageType は、 window/logMessage の重要度を表す。
+	MessageType int
+
+	// PackageGraphParams は、独自の要求 goanalyzer/packageGraph のパラメータ。
+	PackageGraphParams struct {
+		// External が真であれば、解析対象外のパッケージへの依存も含める。
+		External bool `json:"external"`
+		// Format が "dot" または "mermaid" であれば、その形式のグラフを Text に含める。
+		Format string `json:"format,omitempty"`
+	}
+
+	// PackageGraphResult は、独自の要求 goanalyzer/packageGraph の結果。
+	PackageGraphResult struct {
+		Packages []string             `json:"packages"`
+		Imports  []PackageGraphImport `json:"imports"`
+		Text     string               `json:"text,omitempty"`
+	}
+
+	PackageGraphImport struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+)
+
+const (
+	TextDocumentSyncKindFull TextDocumentSyncKind = 1
+)
+
+const (
+	SymbolKindClass     SymbolKind = 5
+	SymbolKindInterface SymbolKind = 11
+	SymbolKindStruct    SymbolKind = 23
+)
+
+const (
+	MessageTypeError   MessageType = 1
+	MessageTypeWarning MessageType = 2
+	MessageTypeInfo    MessageType = 3
+)
@@ -0,0 +1,563 @@
+// Package lsp は、 gocode の解析結果を Language Server Protocol で提供するサーバを実装する。
+//
+// textDocument/implementation と typeHierarchy/* に加え、独自の要求 goanalyzer/packageGraph に応答する。
+// 編集中の内容は didChange と didSave の度に、変更されたファイルのディレクトリのパッケージのみを再度解析して反映する。
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/spf13/afero"
+)
+
+type (
+	// Server は、標準入出力などのストリーム上でLSPの要求を処理するサーバ。
+	// 要求は受け取った順に1つずつ処理する。
+	Server struct {
+		options   gocode.LoadOptions
+		relations *gocode.Relations
+		// documents は開いているファイルの内容をファイルの絶対パスごとに保持する。
+		documents map[string]string
+		out       io.Writer
+		shutdown  bool
+	}
+
+	// handlerFunc は、要求のパラメータを受け取り結果を返す。
+	handlerFunc func(s *Server, params json.RawMessage) (interface{}, error)
+)
+
+// ErrExitWithoutShutdown は、 shutdown の要求を受け取る前に exit の通知を受け取ったことを表す。
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown")
+
+var handlers = map[string]handlerFunc{
+	"initialize":                        (*Server).initialize,
+	"shutdown":                          (*Server).handleShutdown,
+	"textDocument/implementation":       (*Server).implementation,
+	"textDocument/prepareTypeHierarchy": (*Server).prepareTypeHierarchy,
+	"typeHierarchy/supertypes":          (*Server).supertypes,
+	"typeHierarchy/subtypes":            (*Server).subtypes,
+	"goanalyzer/packageGraph":           (*Server).packageGraph,
+}
+
+var notificationHandlers = map[string]func(s *Server, params json.RawMessage) error{
+	"textDocument/didOpen":   (*Server).didOpen,
+	"textDocument/didChange": (*Server).didChange,
+	"textDocument/didSave":   (*Server).didSave,
+	"textDocument/didClose":  (*Server).didClose,
+}
+
+// NewServer は、 options で解析するサーバを生成する。
+// options.Directories が空の場合は、 initialize の要求で受け取ったワークスペースのルートを再帰的に解析する。
+func NewServer(options *gocode.LoadOptions) *Server {
+	s := &Server{documents: make(map[string]string)}
+	if options != nil {
+		s.options = *options
+	}
+	if s.options.FileSystem == nil {
+		s.options.FileSystem = afero.NewOsFs()
+	}
+	return s
+}
+
+// Relations は、現在の解析結果を返す。 initialize の要求を受け取るまでは nil となる。
+func (s *Server) Relations() *gocode.Relations {
+	return s.relations
+}
+
+// Serve は、 in から要求を読み込み、 out に応答を書き出す。
+// exit の通知を受け取るか in が終端に達すると終了する。shutdown の前に exit を受け取った場合は ErrExitWithoutShutdown を返す。
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		body, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.reply(json.RawMessage("null"), nil, &ResponseError{Code: CodeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(&req); err != nil {
+			return err
+		}
+	}
+}
+
+// handle は、1つの要求または通知を処理する。応答の書き出しに失敗した場合のみエラーを返す。
+func (s *Server) handle(req *request) error {
+	if req.isNotification() {
+		h, ok := notificationHandlers[req.Method]
+		if !ok || s.relations == nil {
+			return nil
+		}
+		if err := h(s, req.Params); err != nil {
+			return s.logMessage(MessageTypeError, err.Error())
+		}
+		return nil
+	}
+
+	h, ok := handlers[req.Method]
+	switch {
+	case !ok:
+		return s.reply(req.ID, nil, &ResponseError{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)})
+	case s.relations == nil && req.Method != "initialize":
+		return s.reply(req.ID, nil, &ResponseError{Code: CodeServerNotInitialized, Message: "server not initialized"})
+	}
+
+	result, err := h(s, req.Params)
+	if err != nil {
+		var re *ResponseError
+		if !errors.As(err, &re) {
+			re = &ResponseError{Code: CodeInternalError, Message: err.Error()}
+		}
+		return s.reply(req.ID, nil, re)
+	}
+	return s.reply(req.ID, result, nil)
+}
+
+func (s *Server) reply(id json.RawMessage, result interface{}, respErr *ResponseError) error {
+	res := &response{JSONRPC: "2.0", ID: id, Error: respErr}
+	if respErr == nil {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		res.Result = b
+	}
+	return writeMessage(s.out, res)
+}
+
+func (s *Server) logMessage(typ MessageType, message string) error {
+	return writeMessage(s.out, &notification{
+		JSONRPC: "2.0",
+		Method:  "window/logMessage",
+		Params:  &LogMessageParams{Type: typ, Message: message},
+	})
+}
+
+func unmarshalParams(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	var p InitializeParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if len(s.options.Directories) == 0 {
+		root := p.RootURI
+		if root == "" && len(p.WorkspaceFolders) > 0 {
+			root = p.WorkspaceFolders[0].URI
+		}
+		dir, err := uriToPath(root)
+		if err != nil {
+			return nil, &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		s.options.Directories = []string{dir}
+		s.options.Recursive = true
+	}
+
+	r, err := gocode.LoadRelations(&s.options)
+	if err != nil {
+		return nil, err
+	}
+	s.relations = r
+
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync: TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    TextDocumentSyncKindFull,
+				Save:      SaveOptions{IncludeText: true},
+			},
+			ImplementationProvider: true,
+			TypeHierarchyProvider:  true,
+		},
+		ServerInfo: ServerInfo{Name: "goanalyzer"},
+	}, nil
+}
+
+func (s *Server) handleShutdown(json.RawMessage) (interface{}, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *Server) didOpen(params json.RawMessage) error {
+	var p DidOpenTextDocumentParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return err
+	}
+	path, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	s.documents[path] = p.TextDocument.Text
+	return nil
+}
+
+func (s *Server) didChange(params json.RawMessage) error {
+	var p DidChangeTextDocumentParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return err
+	}
+	if len(p.ContentChanges) == 0 {
+		return nil
+	}
+	path, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	s.documents[path] = p.ContentChanges[len(p.ContentChanges)-1].Text
+	return s.reload(path)
+}
+
+func (s *Server) didSave(params json.RawMessage) error {
+	var p DidSaveTextDocumentParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return err
+	}
+	path, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	if p.Text != nil {
+		s.documents[path] = *p.Text
+	}
+	return s.reload(path)
+}
+
+func (s *Server) didClose(params json.RawMessage) error {
+	var p DidCloseTextDocumentParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return err
+	}
+	path, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	delete(s.documents, path)
+	return s.reload(path)
+}
+
+// reload は、 path のディレクトリのパッケージを、開いているファイルの内容で再度解析する。
+func (s *Server) reload(path string) error {
+	if !strings.HasSuffix(path, ".go") {
+		return nil
+	}
+	overlay := make(map[string][]byte, len(s.documents))
+	for p, text := range s.documents {
+		overlay[p] = []byte(text)
+	}
+	_, _, err := s.relations.Reload([]string{filepath.Dir(path)}, overlay)
+	return err
+}
+
+func (s *Server) implementation(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	sym, ok, err := s.symbolAt(p.TextDocument.URI, p.Position)
+	if err != nil || !ok {
+		return []Location{}, err
+	}
+
+	var found []*typeSymbol
+	if sym.iface != nil {
+		found = s.implementers(sym.iface)
+	} else {
+		found = s.implemented(sym)
+	}
+	locations := make([]Location, 0, len(found))
+	for _, t := range found {
+		loc, ok, err := s.location(t)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			locations = append(locations, loc)
+		}
+	}
+	return locations, nil
+}
+
+func (s *Server) prepareTypeHierarchy(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	sym, ok, err := s.symbolAt(p.TextDocument.URI, p.Position)
+	if err != nil || !ok {
+		return nil, err
+	}
+	item, ok, err := s.typeHierarchyItem(sym)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return []*TypeHierarchyItem{item}, nil
+}
+
+// supertypes は、structとdefined typeであれば実装しているinterfaceを、interfaceであれば包含しているinterfaceを返す。
+func (s *Server) supertypes(params json.RawMessage) (interface{}, error) {
+	var p TypeHierarchySupertypesParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	sym, err := s.symbolOfItem(&p.Item)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*typeSymbol
+	if sym.iface != nil {
+		for _, i := range sym.iface.ExtendedInterfaces().InterfaceAll() {
+			found = append(found, newInterfaceSymbol(i))
+		}
+	} else {
+		found = s.implemented(sym)
+	}
+	return s.typeHierarchyItems(found)
+}
+
+// subtypes は、interfaceを実装しているstructとdefined type、interfaceを包含しているinterfaceを返す。
+func (s *Server) subtypes(params json.RawMessage) (interface{}, error) {
+	var p TypeHierarchySubtypesParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	sym, err := s.symbolOfItem(&p.Item)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*typeSymbol
+	if sym.iface != nil {
+		found = s.implementers(sym.iface)
+		for _, i := range sym.iface.ExtendingInterfaces().InterfaceAll() {
+			found = append(found, newInterfaceSymbol(i))
+		}
+	}
+	return s.typeHierarchyItems(found)
+}
+
+func (s *Server) packageGraph(params json.RawMessage) (interface{}, error) {
+	var p PackageGraphParams
+	if len(params) > 0 {
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+	}
+
+	g := s.relations.PackageGraph()
+	if p.External {
+		g = s.relations.PackageGraphWithExternalPackages()
+	}
+	res := &PackageGraphResult{Packages: []string{}, Imports: []PackageGraphImport{}}
+	for _, path := range g.SortedPackagePaths() {
+		res.Packages = append(res.Packages, path.String())
+		for _, ps := range g.SortedImportPackagePaths(path) {
+			res.Imports = append(res.Imports, PackageGraphImport{From: path.String(), To: ps.Path().String()})
+		}
+	}
+
+	var buf bytes.Buffer
+	switch p.Format {
+	case "":
+	case "dot":
+		if err := g.WriteDOT(&buf); err != nil {
+			return nil, err
+		}
+	case "mermaid":
+		if err := g.WriteMermaid(&buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown format: %s", p.Format)}
+	}
+	res.Text = buf.String()
+	return res, nil
+}
+
+func (s *Server) typeHierarchyItems(symbols []*typeSymbol) ([]*TypeHierarchyItem, error) {
+	sortTypeSymbols(symbols)
+	items := make([]*TypeHierarchyItem, 0, len(symbols))
+	for _, sym := range symbols {
+		item, ok, err := s.typeHierarchyItem(sym)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// typeHierarchyItem は、 sym の TypeHierarchyItem を返す。型の位置が分からなければ ok は偽となる。
+func (s *Server) typeHierarchyItem(sym *typeSymbol) (item *TypeHierarchyItem, ok bool, err error) {
+	loc, ok, err := s.location(sym)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	data, err := json.Marshal(sym.id)
+	if err != nil {
+		return nil, false, err
+	}
+	return &TypeHierarchyItem{
+		Name:           sym.name,
+		Kind:           sym.kind,
+		Detail:         sym.pkgSummary.Path().String(),
+		URI:            loc.URI,
+		Range:          loc.Range,
+		SelectionRange: loc.Range,
+		Data:           data,
+	}, true, nil
+}
+
+func (s *Server) symbolOfItem(item *TypeHierarchyItem) (*typeSymbol, error) {
+	var id gocode.TypeNodeID
+	if err := json.Unmarshal(item.Data, &id); err != nil {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: "type hierarchy item has no type id"}
+	}
+	sym, ok := s.lookupSymbol(id)
+	if !ok {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown type: %s", id)}
+	}
+	return sym, nil
+}
+
+// symbolAt は、ドキュメントの position にある識別子が指す型を返す。
+func (s *Server) symbolAt(uri string, position Position) (*typeSymbol, bool, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, false, &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	line, err := s.lineText(path, int(position.Line)+1)
+	if err != nil {
+		return nil, false, err
+	}
+	id, ok := s.relations.TypeAt(path, int(position.Line)+1, byteColumn(line, position.Character)+1)
+	if !ok {
+		return nil, false, nil
+	}
+	sym, ok := s.lookupSymbol(id)
+	return sym, ok, nil
+}
+
+// location は、型が宣言されている位置の型名の範囲を返す。
+// 宣言のファイルが分からない型は応答に含めないため、 ok を偽として返す。
+func (s *Server) location(sym *typeSymbol) (loc Location, ok bool, err error) {
+	pos := s.relations.Position(sym.pos)
+	if pos.Filename == "" {
+		return Location{}, false, nil
+	}
+	line, err := s.lineText(pos.Filename, pos.Line)
+	if err != nil {
+		return Location{}, false, err
+	}
+	start := utf16Column(line, pos.Column-1)
+	end := utf16Column(line, pos.Column-1+len(sym.name))
+	return Location{
+		URI: pathToURI(pos.Filename),
+		Range: Range{
+			Start: Position{Line: uint32(pos.Line - 1), Character: start},
+			End:   Position{Line: uint32(pos.Line - 1), Character: end},
+		},
+	}, true, nil
+}
+
+// lineText は、ファイルの line 行目(1始まり)の内容を返す。開いているファイルは編集中の内容を使う。
+func (s *Server) lineText(path string, line int) (string, error) {
+	text, ok := s.documents[filepath.Clean(path)]
+	if !ok {
+		b, err := afero.ReadFile(s.options.FileSystem, path)
+		if err != nil {
+			return "", err
+		}
+		text = string(b)
+	}
+	lines := strings.Split(text, "\n")
+	if line < 1 || line > len(lines) {
+		return "", nil
+	}
+	return strings.TrimSuffix(lines[line-1], "\r"), nil
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported uri: %q", uri)
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path)), nil
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// byteColumn は、UTF-16のコード単位での列番号を0始まりのバイト単位の列番号に変換する。
+func byteColumn(line string, character uint32) int {
+	var units uint32
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16Len(r)
+	}
+	return len(line)
+}
+
+// utf16Column は、0始まりのバイト単位の列番号をUTF-16のコード単位での列番号に変換する。
+func utf16Column(line string, column int) uint32 {
+	var units uint32
+	for i, r := range line {
+		if i >= column {
+			break
+		}
+		units += utf16Len(r)
+	}
+	return units
+}
+
+func utf16Len(r rune) uint32 {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+func sortTypeSymbols(symbols []*typeSymbol) {
+	sort.Slice(symbols, func(i, j int) bool {
+		return symbols[i].id < symbols[j].id
+	})
+}
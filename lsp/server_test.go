@@ -0,0 +1,242 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/lsp"
+)
+
+type (
+	// testClient は、テスト用にサーバへ要求を送り応答を受け取るクライアント。
+	testClient struct {
+		t      *testing.T
+		in     io.WriteCloser
+		out    *bufio.Reader
+		nextID int
+		done   chan error
+	}
+
+	testResponse struct {
+		ID     int                `json:"id"`
+		Method string             `json:"method"`
+		Result json.RawMessage    `json:"result"`
+		Error  *lsp.ResponseError `json:"error"`
+	}
+)
+
+func startTestServer(t *testing.T) *testClient {
+	t.Helper()
+	clientIn, serverIn := io.Pipe()
+	serverOut, clientOut := io.Pipe()
+	c := &testClient{t: t, in: serverIn, out: bufio.NewReader(serverOut), done: make(chan error, 1)}
+	go func() {
+		err := lsp.NewServer(nil).Serve(clientIn, clientOut)
+		clientOut.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+func (c *testClient) send(v interface{}) {
+	c.t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// call は要求を送り、通知を読み飛ばして応答の結果を result に格納する。
+func (c *testClient) call(method string, params interface{}, result interface{}) *lsp.ResponseError {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	for {
+		header, err := textproto.NewReader(c.out).ReadMIMEHeader()
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(c.out, body); err != nil {
+			c.t.Fatal(err)
+		}
+		var res testResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			c.t.Fatal(err)
+		}
+		if res.Method != "" {
+			c.t.Logf("notification: %s", body)
+			continue
+		}
+		if res.ID != c.nextID {
+			c.t.Fatalf("response id = %d, want %d", res.ID, c.nextID)
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(res.Result, result); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return nil
+	}
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/testmodule\n\ngo 1.17\n",
+		"store/store.go": `package store
+
+type Reader interface {
+	Read() string
+}
+
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+
+type File struct{}
+
+func (f *File) Read() string { return "" }
+func (f *File) Close() error { return nil }
+`,
+		// //line でファイル名を空にした型は位置が分からないため、応答に含めない。
+		"store/gen.go": `package store
+
+//line :1
+type Hidden struct{}
+
+func (h *Hidden) Read() string { return "" }
+`,
+		"app/app.go": `package app
+
+import "example.com/testmodule/store"
+
+var _ store.Reader
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	storeURI := fileURI(filepath.Join(dir, "store", "store.go"))
+
+	c := startTestServer(t)
+	if err := c.call("textDocument/implementation", map[string]interface{}{}, nil); err == nil || err.Code != lsp.CodeServerNotInitialized {
+		t.Fatalf("implementation before initialize: error = %v, want server not initialized", err)
+	}
+	var initResult lsp.InitializeResult
+	if err := c.call("initialize", map[string]interface{}{"rootUri": fileURI(dir)}, &initResult); err != nil {
+		t.Fatal(err)
+	}
+	if !initResult.Capabilities.ImplementationProvider || !initResult.Capabilities.TypeHierarchyProvider {
+		t.Errorf("capabilities = %+v, want implementation and type hierarchy providers", initResult.Capabilities)
+	}
+
+	readerPos := map[string]interface{}{
+		"textDocument": map[string]string{"uri": storeURI},
+		"position":     lsp.Position{Line: 2, Character: 6},
+	}
+	var locations []lsp.Location
+	if err := c.call("textDocument/implementation", readerPos, &locations); err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 1 || locations[0].URI != storeURI || locations[0].Range.Start != (lsp.Position{Line: 11, Character: 5}) {
+		t.Errorf("implementation(Reader) = %+v, want File at 11:5", locations)
+	}
+
+	var items []lsp.TypeHierarchyItem
+	if err := c.call("textDocument/prepareTypeHierarchy", readerPos, &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "Reader" || items[0].Kind != lsp.SymbolKindInterface {
+		t.Fatalf("prepareTypeHierarchy(Reader) = %+v, want Reader", items)
+	}
+	var subtypes []lsp.TypeHierarchyItem
+	if err := c.call("typeHierarchy/subtypes", map[string]interface{}{"item": items[0]}, &subtypes); err != nil {
+		t.Fatal(err)
+	}
+	if got := itemNames(subtypes); got != "File,ReadCloser" {
+		t.Errorf("subtypes(Reader) = %s, want File,ReadCloser", got)
+	}
+	var supertypes []lsp.TypeHierarchyItem
+	if err := c.call("typeHierarchy/supertypes", map[string]interface{}{"item": subtypes[0]}, &supertypes); err != nil {
+		t.Fatal(err)
+	}
+	if got := itemNames(supertypes); got != "ReadCloser,Reader" {
+		t.Errorf("supertypes(File) = %s, want ReadCloser,Reader", got)
+	}
+
+	var graph lsp.PackageGraphResult
+	if err := c.call("goanalyzer/packageGraph", map[string]interface{}{"format": "dot"}, &graph); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Imports) != 1 || graph.Imports[0] != (lsp.PackageGraphImport{From: "example.com/testmodule/app", To: "example.com/testmodule/store"}) {
+		t.Errorf("packageGraph imports = %+v, want app -> store", graph.Imports)
+	}
+	if !strings.HasPrefix(graph.Text, "digraph packages {") {
+		t.Errorf("packageGraph text = %q, want DOT", graph.Text)
+	}
+
+	// 保存されていない変更で Buffer を追加すると、実装の一覧に反映される。
+	changed := files["store/store.go"] + "\ntype Buffer struct{}\n\nfunc (b Buffer) Read() string { return \"\" }\n"
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": storeURI, "languageId": "go", "version": 1, "text": files["store/store.go"]},
+	})
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": storeURI, "version": 2},
+		"contentChanges": []map[string]string{{"text": changed}},
+	})
+	if err := c.call("typeHierarchy/subtypes", map[string]interface{}{"item": items[0]}, &subtypes); err != nil {
+		t.Fatal(err)
+	}
+	if got := itemNames(subtypes); got != "Buffer,File,ReadCloser" {
+		t.Errorf("subtypes(Reader) after didChange = %s, want Buffer,File,ReadCloser", got)
+	}
+
+	if err := c.call("shutdown", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("Serve() = %v, want nil", err)
+	}
+}
+
+func itemNames(items []lsp.TypeHierarchyItem) string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,130 @@
+package lsp
+
+import (
+	"go/token"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+type (
+	// typeSymbol は、LSPの応答に含める解析した型を表す。
+	typeSymbol struct {
+		id         gocode.TypeNodeID
+		name       string
+		kind       SymbolKind
+		pos        token.Pos
+		pkgSummary *gocode.PackageSummary
+		// structure、 iface、 definedType は型の種類に応じていずれか1つのみが設定される。type aliasであれば全て nil となる。
+		structure   *gocode.Struct
+		iface       *gocode.Interface
+		definedType *gocode.DefinedType
+	}
+)
+
+func newStructSymbol(s *gocode.Struct) *typeSymbol {
+	return &typeSymbol{
+		id:         typeID(s.PackageSummary(), s.Name().String()),
+		name:       s.Name().String(),
+		kind:       SymbolKindStruct,
+		pos:        s.DefinedPos(),
+		pkgSummary: s.PackageSummary(),
+		structure:  s,
+	}
+}
+
+func newInterfaceSymbol(i *gocode.Interface) *typeSymbol {
+	return &typeSymbol{
+		id:         typeID(i.PackageSummary(), i.Name().String()),
+		name:       i.Name().String(),
+		kind:       SymbolKindInterface,
+		pos:        i.DefinedPos(),
+		pkgSummary: i.PackageSummary(),
+		iface:      i,
+	}
+}
+
+func newDefinedTypeSymbol(dt *gocode.DefinedType) *typeSymbol {
+	return &typeSymbol{
+		id:          typeID(dt.PackageSummary(), dt.Name().String()),
+		name:        dt.Name().String(),
+		kind:        SymbolKindClass,
+		pos:         dt.DefinedPos(),
+		pkgSummary:  dt.PackageSummary(),
+		definedType: dt,
+	}
+}
+
+func newTypeAliasSymbol(a *gocode.TypeAlias) *typeSymbol {
+	return &typeSymbol{
+		id:         typeID(a.PackageSummary(), a.Name().String()),
+		name:       a.Name().String(),
+		kind:       SymbolKindClass,
+		pos:        a.DefinedPos(),
+		pkgSummary: a.PackageSummary(),
+	}
+}
+
+func typeID(ps *gocode.PackageSummary, name string) gocode.TypeNodeID {
+	return gocode.TypeNodeID(ps.Path().String() + "." + name)
+}
+
+// lookupSymbol は、 "パッケージパス.型名" の型を解析結果から探す。
+func (s *Server) lookupSymbol(id gocode.TypeNodeID) (*typeSymbol, bool) {
+	for _, st := range s.relations.Structs().StructAll() {
+		if sym := newStructSymbol(st); sym.id == id {
+			return sym, true
+		}
+	}
+	for _, i := range s.relations.Interfaces().InterfaceAll() {
+		if sym := newInterfaceSymbol(i); sym.id == id {
+			return sym, true
+		}
+	}
+	for _, dt := range s.relations.DefinedTypes().DefinedTypeAll() {
+		if sym := newDefinedTypeSymbol(dt); sym.id == id {
+			return sym, true
+		}
+	}
+	for _, a := range s.relations.TypeAliases().AliasAll() {
+		if sym := newTypeAliasSymbol(a); sym.id == id {
+			return sym, true
+		}
+	}
+	return nil, false
+}
+
+// implementers は、 i を実装しているstructとdefined typeを返す。
+func (s *Server) implementers(i *gocode.Interface) []*typeSymbol {
+	var res []*typeSymbol
+	for _, st := range s.relations.Structs().StructAll() {
+		if found, ok := st.ImplementInterfaces().Get(i.PackageSummary().Name(), i.Name()); ok && found == i {
+			res = append(res, newStructSymbol(st))
+		}
+	}
+	for _, dt := range s.relations.DefinedTypes().DefinedTypeAll() {
+		if dt.Implements(i) {
+			res = append(res, newDefinedTypeSymbol(dt))
+		}
+	}
+	sortTypeSymbols(res)
+	return res
+}
+
+// implemented は、structまたはdefined typeが実装しているinterfaceを返す。
+func (s *Server) implemented(sym *typeSymbol) []*typeSymbol {
+	var res []*typeSymbol
+	switch {
+	case sym.structure != nil:
+		for _, i := range sym.structure.ImplementInterfaces().InterfaceAll() {
+			res = append(res, newInterfaceSymbol(i))
+		}
+	case sym.definedType != nil:
+		for _, i := range s.relations.Interfaces().InterfaceAll() {
+			if sym.definedType.Implements(i) {
+				res = append(res, newInterfaceSymbol(i))
+			}
+		}
+	}
+	sortTypeSymbols(res)
+	return res
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"go/token"
+	"sort"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+// APIの応答のJSONの形式を定義する。
+
+type (
+	packageJSON struct {
+		Path         string   `json:"path"`
+		Name         string   `json:"name"`
+		Structs      int      `json:"structs"`
+		Interfaces   int      `json:"interfaces"`
+		DefinedTypes int      `json:"definedTypes"`
+		TypeAliases  int      `json:"typeAliases"`
+		Imports      []string `json:"imports"`
+	}
+
+	packageDetailJSON struct {
+		packageJSON
+		Types []*typeSummaryJSON `json:"types"`
+	}
+
+	typeSummaryJSON struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Kind     string `json:"kind"`
+		Package  string `json:"package"`
+		Position string `json:"position"`
+	}
+
+	typeDetailJSON struct {
+		typeSummaryJSON
+		// Underlying はdefined typeの基底型、またはtype aliasが参照する型。
+		Underlying   string             `json:"underlying,omitempty"`
+		Fields       []*fieldJSON       `json:"fields"`
+		Methods      []*methodJSON      `json:"methods"`
+		Implements   []*typeSummaryJSON `json:"implements"`
+		Implementers []*typeSummaryJSON `json:"implementers"`
+		Extends      []*typeSummaryJSON `json:"extends"`
+		ExtendedBy   []*typeSummaryJSON `json:"extendedBy"`
+	}
+
+	fieldJSON struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Embedded bool   `json:"embedded"`
+		Exported bool   `json:"exported"`
+	}
+
+	methodJSON struct {
+		Name      string `json:"name"`
+		Signature string `json:"signature"`
+		Exported  bool   `json:"exported"`
+		Promoted  bool   `json:"promoted"`
+	}
+
+	graphJSON struct {
+		Nodes []*graphNodeJSON `json:"nodes"`
+		Edges []*graphEdgeJSON `json:"edges"`
+	}
+
+	graphNodeJSON struct {
+		ID   string `json:"id"`
+		Kind string `json:"kind,omitempty"`
+	}
+
+	graphEdgeJSON struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Kind string `json:"kind,omitempty"`
+	}
+
+	// typeEntry は、APIで扱う解析した型を表す。 structure 、 iface 、 definedType 、 alias のいずれか1つのみが設定される。
+	typeEntry struct {
+		summary     *typeSummaryJSON
+		structure   *gocode.Struct
+		iface       *gocode.Interface
+		definedType *gocode.DefinedType
+		alias       *gocode.TypeAlias
+	}
+)
+
+func newPackageJSON(pkg *gocode.Package, graph *gocode.PackageGraph) *packageJSON {
+	detail := pkg.Detail()
+	p := &packageJSON{
+		Path:         pkg.Summary().Path().String(),
+		Name:         pkg.Summary().Name().String(),
+		Structs:      len(detail.Structs()),
+		Interfaces:   len(detail.Interfaces()),
+		DefinedTypes: len(detail.DefinedTypes()),
+		TypeAliases:  len(detail.TypeAliases()),
+		Imports:      []string{},
+	}
+	for _, ps := range graph.SortedImportPackagePaths(pkg.Summary().Path()) {
+		p.Imports = append(p.Imports, ps.Path().String())
+	}
+	return p
+}
+
+func newTypeSummaryJSON(r *gocode.Relations, kind string, ps *gocode.PackageSummary, name string, pos token.Pos) *typeSummaryJSON {
+	position := r.Position(pos)
+	return &typeSummaryJSON{
+		ID:       ps.Path().String() + "." + name,
+		Name:     name,
+		Kind:     kind,
+		Package:  ps.Path().String(),
+		Position: position.String(),
+	}
+}
+
+// allTypes は、解析した全ての型をIDの順で返す。
+func allTypes(r *gocode.Relations) []*typeEntry {
+	var entries []*typeEntry
+	for _, s := range r.Structs().StructAll() {
+		entries = append(entries, &typeEntry{
+			summary:   newTypeSummaryJSON(r, gocode.TypeNodeStruct.String(), s.PackageSummary(), s.Name().String(), s.DefinedPos()),
+			structure: s,
+		})
+	}
+	for _, i := range r.Interfaces().InterfaceAll() {
+		entries = append(entries, newInterfaceEntry(r, i))
+	}
+	for _, dt := range r.DefinedTypes().DefinedTypeAll() {
+		entries = append(entries, &typeEntry{
+			summary:     newTypeSummaryJSON(r, gocode.TypeNodeDefinedType.String(), dt.PackageSummary(), dt.Name().String(), dt.DefinedPos()),
+			definedType: dt,
+		})
+	}
+	for _, a := range r.TypeAliases().AliasAll() {
+		entries = append(entries, &typeEntry{
+			summary: newTypeSummaryJSON(r, gocode.TypeNodeTypeAlias.String(), a.PackageSummary(), a.Name().String(), a.DefinedPos()),
+			alias:   a,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].summary.ID < entries[j].summary.ID
+	})
+	return entries
+}
+
+func newInterfaceEntry(r *gocode.Relations, i *gocode.Interface) *typeEntry {
+	return &typeEntry{
+		summary: newTypeSummaryJSON(r, gocode.TypeNodeInterface.String(), i.PackageSummary(), i.Name().String(), i.DefinedPos()),
+		iface:   i,
+	}
+}
+
+func lookupType(r *gocode.Relations, id string) (*typeEntry, bool) {
+	for _, e := range allTypes(r) {
+		if e.summary.ID == id {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// implementers は、 i を実装しているstructとdefined typeの一覧を返す。
+func implementers(r *gocode.Relations, i *gocode.Interface) []*typeSummaryJSON {
+	res := []*typeSummaryJSON{}
+	for _, e := range allTypes(r) {
+		switch {
+		case e.structure != nil:
+			if found, ok := e.structure.ImplementInterfaces().Get(i.PackageSummary().Name(), i.Name()); ok && found == i {
+				res = append(res, e.summary)
+			}
+		case e.definedType != nil:
+			if e.definedType.Implements(i) {
+				res = append(res, e.summary)
+			}
+		}
+	}
+	return res
+}
+
+func interfaceSummaries(r *gocode.Relations, interfaces []*gocode.Interface) []*typeSummaryJSON {
+	res := make([]*typeSummaryJSON, 0, len(interfaces))
+	for _, i := range interfaces {
+		res = append(res, newInterfaceEntry(r, i).summary)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+func newTypeDetailJSON(r *gocode.Relations, e *typeEntry) *typeDetailJSON {
+	d := &typeDetailJSON{
+		typeSummaryJSON: *e.summary,
+		Fields:          []*fieldJSON{},
+		Methods:         []*methodJSON{},
+		Implements:      []*typeSummaryJSON{},
+		Implementers:    []*typeSummaryJSON{},
+		Extends:         []*typeSummaryJSON{},
+		ExtendedBy:      []*typeSummaryJSON{},
+	}
+	switch {
+	case e.structure != nil:
+		for _, f := range e.structure.Fields() {
+			d.Fields = append(d.Fields, &fieldJSON{
+				Name:     f.Name().String(),
+				Type:     gocode.DisplayType(f.Type().GoType()),
+				Embedded: f.Embedded(),
+				Exported: f.Exported(),
+			})
+		}
+		d.Methods = newMethodsJSON(e.structure.MethodsIncludingPromoted())
+		d.Implements = interfaceSummaries(r, e.structure.ImplementInterfaces().InterfaceAll())
+	case e.iface != nil:
+		d.Methods = newMethodsJSON(e.iface.Methods())
+		d.Implementers = implementers(r, e.iface)
+		d.Extends = interfaceSummaries(r, e.iface.ExtendedInterfaces().InterfaceAll())
+		d.ExtendedBy = interfaceSummaries(r, e.iface.ExtendingInterfaces().InterfaceAll())
+	case e.definedType != nil:
+		d.Underlying = gocode.DisplayType(e.definedType.UnderlyingType().GoType())
+		d.Methods = newMethodsJSON(e.definedType.Methods())
+		var interfaces []*gocode.Interface
+		for _, i := range r.Interfaces().InterfaceAll() {
+			if e.definedType.Implements(i) {
+				interfaces = append(interfaces, i)
+			}
+		}
+		d.Implements = interfaceSummaries(r, interfaces)
+	case e.alias != nil:
+		d.Underlying = gocode.DisplayType(e.alias.Target().GoType())
+	}
+	return d
+}
+
+func newMethodsJSON(methods []*gocode.Function) []*methodJSON {
+	res := make([]*methodJSON, 0, len(methods))
+	for _, m := range methods {
+		res = append(res, &methodJSON{
+			Name:      m.Name().String(),
+			Signature: m.Signature(),
+			Exported:  m.Exported(),
+			Promoted:  m.Promoted(),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
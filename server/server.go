@@ -0,0 +1,244 @@
+// Package server は、 gocode の解析結果をJSONのAPIと、パッケージの依存グラフと型の詳細を表示するWeb UIで提供するHTTPサーバを実装する。
+package server
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"sort"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+)
+
+type (
+	// Source は、 Server が解析結果を読み取る元。 *gocode.RelationsWatcher はこれを満たす。
+	Source interface {
+		// View は、解析結果を読み取る fn を呼び出す。
+		View(fn func(r *gocode.Relations))
+	}
+
+	// staticSource は、変更されない解析結果を Source として扱う。
+	staticSource struct {
+		relations *gocode.Relations
+	}
+
+	// Server は、解析結果を提供する http.Handler 。
+	//
+	// 以下のAPIに応答する。型のIDは "パッケージパス.型名" の形式とする。
+	//
+	//	GET /api/packages                     解析したパッケージの一覧
+	//	GET /api/package?path=                パッケージとそのパッケージの型の一覧
+	//	GET /api/structs?package=             structの一覧。package を指定した場合はそのパッケージのみ
+	//	GET /api/interfaces?package=          interfaceの一覧。package を指定した場合はそのパッケージのみ
+	//	GET /api/type?id=                     型の詳細
+	//	GET /api/implementers?id=             interfaceを実装している型の一覧
+	//	GET /api/graph/packages?format=&external=  パッケージの依存グラフ。format は json 、 dot 、 mermaid のいずれか
+	//	GET /api/graph/types?format=          型の依存グラフ
+	Server struct {
+		source Source
+		mux    *http.ServeMux
+	}
+
+	// errorJSON は、エラーの応答のJSONの形式。
+	errorJSON struct {
+		Error string `json:"error"`
+	}
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// StaticSource は、 r を変更されない解析結果の Source として扱う。
+func StaticSource(r *gocode.Relations) Source {
+	return &staticSource{relations: r}
+}
+
+func (s *staticSource) View(fn func(r *gocode.Relations)) {
+	fn(s.relations)
+}
+
+// NewServer は、 source の解析結果を提供する Server を生成する。
+func NewServer(source Source) *Server {
+	s := &Server{source: source, mux: http.NewServeMux()}
+	s.handle("/api/packages", s.packages)
+	s.handle("/api/package", s.packageDetail)
+	s.handle("/api/structs", s.typeList(gocode.TypeNodeStruct))
+	s.handle("/api/interfaces", s.typeList(gocode.TypeNodeInterface))
+	s.handle("/api/type", s.typeDetail)
+	s.handle("/api/implementers", s.implementers)
+	s.handle("/api/graph/packages", s.packageGraph)
+	s.handle("/api/graph/types", s.typeGraph)
+
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+	s.mux.Handle("/", http.FileServer(http.FS(static)))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(w, req)
+}
+
+// handle は、GETの要求に対して解析結果を読み取りながら h を呼び出すAPIを登録する。
+func (s *Server) handle(pattern string, h func(w http.ResponseWriter, req *http.Request, r *gocode.Relations)) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+			return
+		}
+		s.source.View(func(r *gocode.Relations) {
+			h(w, req, r)
+		})
+	})
+}
+
+func (s *Server) packages(w http.ResponseWriter, _ *http.Request, r *gocode.Relations) {
+	graph := r.PackageGraph()
+	res := []*packageJSON{}
+	for _, pkg := range r.Packages().AsSlice() {
+		res = append(res, newPackageJSON(pkg, graph))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	writeJSON(w, res)
+}
+
+func (s *Server) packageDetail(w http.ResponseWriter, req *http.Request, r *gocode.Relations) {
+	path := req.URL.Query().Get("path")
+	pkg, ok := r.Packages().Get(gocode.PackagePath(path))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("package %q not found", path))
+		return
+	}
+	res := &packageDetailJSON{packageJSON: *newPackageJSON(pkg, r.PackageGraph()), Types: []*typeSummaryJSON{}}
+	for _, e := range allTypes(r) {
+		if e.summary.Package == path {
+			res.Types = append(res.Types, e.summary)
+		}
+	}
+	writeJSON(w, res)
+}
+
+func (s *Server) typeList(kind gocode.TypeNodeKind) func(w http.ResponseWriter, req *http.Request, r *gocode.Relations) {
+	return func(w http.ResponseWriter, req *http.Request, r *gocode.Relations) {
+		pkgPath := req.URL.Query().Get("package")
+		res := []*typeSummaryJSON{}
+		for _, e := range allTypes(r) {
+			if e.summary.Kind == kind.String() && (pkgPath == "" || e.summary.Package == pkgPath) {
+				res = append(res, e.summary)
+			}
+		}
+		writeJSON(w, res)
+	}
+}
+
+func (s *Server) typeDetail(w http.ResponseWriter, req *http.Request, r *gocode.Relations) {
+	id := req.URL.Query().Get("id")
+	e, ok := lookupType(r, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("type %q not found", id))
+		return
+	}
+	writeJSON(w, newTypeDetailJSON(r, e))
+}
+
+func (s *Server) implementers(w http.ResponseWriter, req *http.Request, r *gocode.Relations) {
+	id := req.URL.Query().Get("id")
+	e, ok := lookupType(r, id)
+	if !ok || e.iface == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("interface %q not found", id))
+		return
+	}
+	writeJSON(w, implementers(r, e.iface))
+}
+
+func (s *Server) packageGraph(w http.ResponseWriter, req *http.Request, r *gocode.Relations) {
+	graph := r.PackageGraph()
+	if req.URL.Query().Get("external") == "true" {
+		graph = r.PackageGraphWithExternalPackages()
+	}
+
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "json":
+		res := &graphJSON{Nodes: []*graphNodeJSON{}, Edges: []*graphEdgeJSON{}}
+		nodes := make(map[string]struct{})
+		addNode := func(path string) {
+			if _, ok := nodes[path]; !ok {
+				nodes[path] = struct{}{}
+				res.Nodes = append(res.Nodes, &graphNodeJSON{ID: path})
+			}
+		}
+		for _, path := range graph.SortedPackagePaths() {
+			addNode(path.String())
+		}
+		for _, path := range graph.SortedPackagePaths() {
+			for _, ps := range graph.SortedImportPackagePaths(path) {
+				addNode(ps.Path().String())
+				res.Edges = append(res.Edges, &graphEdgeJSON{From: path.String(), To: ps.Path().String()})
+			}
+		}
+		writeJSON(w, res)
+	case "dot":
+		writeGraph(w, graph.WriteDOT)
+	case "mermaid":
+		writeGraph(w, graph.WriteMermaid)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown format: %s", format))
+	}
+}
+
+func (s *Server) typeGraph(w http.ResponseWriter, req *http.Request, r *gocode.Relations) {
+	graph := r.TypeGraph()
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "json":
+		res := &graphJSON{Nodes: []*graphNodeJSON{}, Edges: []*graphEdgeJSON{}}
+		for _, n := range graph.Nodes() {
+			res.Nodes = append(res.Nodes, &graphNodeJSON{ID: n.ID().String(), Kind: n.Kind().String()})
+		}
+		for _, e := range graph.Edges() {
+			res.Edges = append(res.Edges, &graphEdgeJSON{From: e.From().String(), To: e.To().String(), Kind: e.Kind().String()})
+		}
+		writeJSON(w, res)
+	case "dot":
+		writeGraph(w, graph.WriteDOT)
+	case "mermaid":
+		writeGraph(w, graph.WriteMermaid)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown format: %s", format))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(body)
+}
+
+func writeGraph(w http.ResponseWriter, write func(w io.Writer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(&errorJSON{Error: err.Error()})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
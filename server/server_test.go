@@ -0,0 +1,151 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keisuke-m123/goanalyzer/gocode"
+	"github.com/keisuke-m123/goanalyzer/server"
+	"github.com/spf13/afero"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/testmodule\n\ngo 1.17\n",
+		"store/store.go": `package store
+
+type Reader interface {
+	Read(key string) ([]byte, error)
+}
+
+type Memory struct {
+	data map[string][]byte
+}
+
+func (m *Memory) Read(key string) ([]byte, error) { return m.data[key], nil }
+`,
+		"app/app.go": `package app
+
+import "example.com/testmodule/store"
+
+type App struct {
+	Reader store.Reader
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r, err := gocode.LoadRelations(&gocode.LoadOptions{
+		FileSystem:  afero.NewOsFs(),
+		Directories: []string{dir},
+		Recursive:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server.NewServer(server.StaticSource(r)))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, ts *httptest.Server, path string, v interface{}) int {
+	t.Helper()
+	res, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := v.(*string); ok {
+		*s = string(body)
+	} else if v != nil {
+		if err := json.Unmarshal(body, v); err != nil {
+			t.Fatalf("GET %s: %v: %s", path, err, body)
+		}
+	}
+	return res.StatusCode
+}
+
+func TestServer_API(t *testing.T) {
+	ts := newTestServer(t)
+
+	var packages []struct {
+		Path    string   `json:"path"`
+		Imports []string `json:"imports"`
+	}
+	get(t, ts, "/api/packages", &packages)
+	if len(packages) != 2 || packages[0].Path != "example.com/testmodule/app" || strings.Join(packages[0].Imports, ",") != "example.com/testmodule/store" {
+		t.Errorf("/api/packages = %+v, want app importing store and store", packages)
+	}
+
+	type summary struct {
+		ID   string `json:"id"`
+		Kind string `json:"kind"`
+	}
+	var interfaces []summary
+	get(t, ts, "/api/interfaces", &interfaces)
+	if len(interfaces) != 1 || interfaces[0].ID != "example.com/testmodule/store.Reader" {
+		t.Errorf("/api/interfaces = %+v, want store.Reader", interfaces)
+	}
+
+	var implementers []summary
+	get(t, ts, "/api/implementers?id="+url.QueryEscape("example.com/testmodule/store.Reader"), &implementers)
+	if len(implementers) != 1 || implementers[0] != (summary{ID: "example.com/testmodule/store.Memory", Kind: "struct"}) {
+		t.Errorf("/api/implementers = %+v, want store.Memory", implementers)
+	}
+
+	var detail struct {
+		Fields []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"fields"`
+		Methods []struct {
+			Signature string `json:"signature"`
+		} `json:"methods"`
+		Implements []summary `json:"implements"`
+	}
+	get(t, ts, "/api/type?id="+url.QueryEscape("example.com/testmodule/store.Memory"), &detail)
+	if len(detail.Fields) != 1 || detail.Fields[0].Type != "map[string][]byte" {
+		t.Errorf("fields = %+v, want data map[string][]byte", detail.Fields)
+	}
+	if len(detail.Methods) != 1 || detail.Methods[0].Signature != "Read(key string) ([]byte, error)" {
+		t.Errorf("methods = %+v, want Read(key string) ([]byte, error)", detail.Methods)
+	}
+	if len(detail.Implements) != 1 || detail.Implements[0].ID != "example.com/testmodule/store.Reader" {
+		t.Errorf("implements = %+v, want store.Reader", detail.Implements)
+	}
+
+	var mermaid string
+	get(t, ts, "/api/graph/packages?format=mermaid", &mermaid)
+	if !strings.Contains(mermaid, "p0 --> p1") {
+		t.Errorf("/api/graph/packages?format=mermaid = %q, want app --> store", mermaid)
+	}
+
+	if status := get(t, ts, "/api/type?id=unknown.Type", nil); status != http.StatusNotFound {
+		t.Errorf("/api/type for unknown type: status = %d, want 404", status)
+	}
+
+	var index string
+	if status := get(t, ts, "/", &index); status != http.StatusOK || !strings.Contains(index, "app.js") {
+		t.Errorf("GET / = %d %q, want the web UI", status, index)
+	}
+}
@@ -0,0 +1,215 @@
+// goanalyzer のWeb UI。パッケージの依存グラフを描画し、パッケージと型の詳細を表示する。
+// 表示している対象は location.hash に "#package=<path>" または "#type=<id>" の形式で保持する。
+"use strict";
+
+const SVG_NS = "http://www.w3.org/2000/svg";
+const NODE_HEIGHT = 24;
+const ROW_GAP = 12;
+const COLUMN_GAP = 80;
+const CHAR_WIDTH = 7;
+
+let graph = { nodes: [], edges: [] };
+let selectedPackage = "";
+
+async function fetchJSON(url) {
+  const res = await fetch(url);
+  const body = await res.json();
+  if (!res.ok) {
+    throw new Error(body.error || res.statusText);
+  }
+  return body;
+}
+
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    if (key === "onclick") {
+      e.addEventListener("click", value);
+    } else {
+      e.setAttribute(key, value);
+    }
+  }
+  for (const child of children) {
+    e.append(child);
+  }
+  return e;
+}
+
+function svg(tag, attrs) {
+  const e = document.createElementNS(SVG_NS, tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    e.setAttribute(key, value);
+  }
+  return e;
+}
+
+function packageLink(path) {
+  return el("a", { href: "#package=" + encodeURIComponent(path) }, path);
+}
+
+function typeLink(summary) {
+  return el("span", {},
+    el("a", { href: "#type=" + encodeURIComponent(summary.id) }, summary.name),
+    el("span", { class: "kind" }, summary.kind + " in " + summary.package));
+}
+
+function list(items, render) {
+  if (items.length === 0) {
+    return el("p", { class: "hint" }, "none");
+  }
+  return el("ul", {}, ...items.map((item) => el("li", {}, render(item))));
+}
+
+// layout は、importしている側が左になるように各パッケージの列と行を決める。
+function layout(nodes, edges) {
+  const column = new Map(nodes.map((n) => [n.id, 0]));
+  for (let i = 0; i < nodes.length; i++) {
+    let changed = false;
+    for (const e of edges) {
+      if (column.get(e.to) < column.get(e.from) + 1) {
+        column.set(e.to, column.get(e.from) + 1);
+        changed = true;
+      }
+    }
+    if (!changed) {
+      break;
+    }
+  }
+
+  const columns = [];
+  for (const n of nodes) {
+    const c = column.get(n.id);
+    (columns[c] = columns[c] || []).push(n);
+  }
+  const positions = new Map();
+  let x = 16;
+  for (const members of columns.filter(Boolean)) {
+    const width = Math.max(...members.map((n) => n.id.length * CHAR_WIDTH + 16));
+    members.forEach((n, row) => {
+      positions.set(n.id, { x, y: 16 + row * (NODE_HEIGHT + ROW_GAP), width });
+    });
+    x += width + COLUMN_GAP;
+  }
+  return positions;
+}
+
+function renderGraph() {
+  const root = document.getElementById("graph");
+  root.replaceChildren();
+  const positions = layout(graph.nodes, graph.edges);
+
+  const defs = svg("defs");
+  const marker = svg("marker", { id: "arrow", viewBox: "0 0 10 10", refX: "10", refY: "5", markerWidth: "6", markerHeight: "6", orient: "auto" });
+  marker.append(svg("path", { d: "M 0 0 L 10 5 L 0 10 z", fill: "#8c959f" }));
+  defs.append(marker);
+  root.append(defs);
+
+  let width = 0;
+  let height = 0;
+  for (const e of graph.edges) {
+    const from = positions.get(e.from);
+    const to = positions.get(e.to);
+    const x1 = from.x + from.width;
+    const y1 = from.y + NODE_HEIGHT / 2;
+    const x2 = to.x;
+    const y2 = to.y + NODE_HEIGHT / 2;
+    const mid = (x1 + x2) / 2;
+    const selected = e.from === selectedPackage || e.to === selectedPackage;
+    root.append(svg("path", {
+      class: selected ? "edge selected" : "edge",
+      d: `M ${x1} ${y1} C ${mid} ${y1}, ${mid} ${y2}, ${x2} ${y2}`,
+      "marker-end": "url(#arrow)",
+    }));
+  }
+  for (const n of graph.nodes) {
+    const p = positions.get(n.id);
+    const g = svg("g", { class: n.id === selectedPackage ? "node selected" : "node" });
+    g.append(svg("rect", { x: p.x, y: p.y, width: p.width, height: NODE_HEIGHT }));
+    const text = svg("text", { x: p.x + 8, y: p.y + NODE_HEIGHT / 2 });
+    text.textContent = n.id;
+    g.append(text);
+    g.addEventListener("click", () => {
+      location.hash = "package=" + encodeURIComponent(n.id);
+    });
+    root.append(g);
+    width = Math.max(width, p.x + p.width + 16);
+    height = Math.max(height, p.y + NODE_HEIGHT + 16);
+  }
+  root.setAttribute("width", width);
+  root.setAttribute("height", height);
+}
+
+async function loadPackages() {
+  const external = document.getElementById("external").checked;
+  const [packages, g] = await Promise.all([
+    fetchJSON("/api/packages"),
+    fetchJSON("/api/graph/packages?external=" + external),
+  ]);
+  graph = g;
+  const nav = document.getElementById("packages");
+  nav.replaceChildren(list(packages, (p) => packageLink(p.path)));
+  renderGraph();
+}
+
+async function showPackage(path) {
+  selectedPackage = path;
+  renderGraph();
+  const p = await fetchJSON("/api/package?path=" + encodeURIComponent(path));
+  document.getElementById("details").replaceChildren(
+    el("h2", {}, p.path),
+    el("p", {}, `${p.structs} structs, ${p.interfaces} interfaces, ${p.definedTypes} defined types, ${p.typeAliases} type aliases`),
+    el("h3", {}, "Imports"),
+    list(p.imports, packageLink),
+    el("h3", {}, "Types"),
+    list(p.types, typeLink),
+  );
+}
+
+async function showType(id) {
+  const t = await fetchJSON("/api/type?id=" + encodeURIComponent(id));
+  selectedPackage = t.package;
+  renderGraph();
+  const children = [
+    el("h2", {}, t.name, el("span", { class: "kind" }, t.kind)),
+    el("p", {}, packageLink(t.package)),
+    el("p", {}, el("code", {}, t.position)),
+  ];
+  if (t.underlying) {
+    children.push(el("h3", {}, "Underlying"), el("code", {}, t.underlying));
+  }
+  if (t.kind === "struct") {
+    children.push(el("h3", {}, "Fields"), list(t.fields, (f) => el("code", {}, f.embedded ? f.type : `${f.name} ${f.type}`)));
+  }
+  children.push(el("h3", {}, "Methods"), list(t.methods, (m) => el("code", {}, m.signature + (m.promoted ? " (promoted)" : ""))));
+  if (t.kind === "interface") {
+    children.push(
+      el("h3", {}, "Implementers"), list(t.implementers, typeLink),
+      el("h3", {}, "Extends"), list(t.extends, typeLink),
+      el("h3", {}, "Extended by"), list(t.extendedBy, typeLink),
+    );
+  } else if (t.kind !== "type alias") {
+    children.push(el("h3", {}, "Implements"), list(t.implements, typeLink));
+  }
+  document.getElementById("details").replaceChildren(...children);
+}
+
+function showError(err) {
+  document.getElementById("details").replaceChildren(el("p", { class: "hint" }, String(err.message || err)));
+}
+
+function route() {
+  const hash = location.hash.slice(1);
+  const sep = hash.indexOf("=");
+  const key = hash.slice(0, sep);
+  const value = decodeURIComponent(hash.slice(sep + 1));
+  if (key === "package") {
+    showPackage(value).catch(showError);
+  } else if (key === "type") {
+    showType(value).catch(showError);
+  }
+}
+
+window.addEventListener("hashchange", route);
+document.getElementById("external").addEventListener("change", () => loadPackages().catch(showError));
+document.getElementById("reload").addEventListener("click", () => loadPackages().then(route).catch(showError));
+loadPackages().then(route).catch(showError);